@@ -0,0 +1,6 @@
+// Package tinyurlv1 содержит сгенерированный код gRPC API. После изменения
+// tinyurl.proto код нужно перегенерировать (нужны protoc, protoc-gen-go и
+// protoc-gen-go-grpc в PATH).
+package tinyurlv1
+
+//go:generate protoc -I ../../.. --go_out=../../.. --go_opt=paths=source_relative --go-grpc_out=../../.. --go-grpc_opt=paths=source_relative api/tinyurl/v1/tinyurl.proto
@@ -0,0 +1,1269 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.11
+// 	protoc        v5.29.0
+// source: api/tinyurl/v1/tinyurl.proto
+
+package tinyurlv1
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type TargetRule struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Os            string                 `protobuf:"bytes,2,opt,name=os,proto3" json:"os,omitempty"`
+	Device        string                 `protobuf:"bytes,3,opt,name=device,proto3" json:"device,omitempty"`
+	Bot           *bool                  `protobuf:"varint,4,opt,name=bot,proto3,oneof" json:"bot,omitempty"`
+	Url           string                 `protobuf:"bytes,5,opt,name=url,proto3" json:"url,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TargetRule) Reset() {
+	*x = TargetRule{}
+	mi := &file_api_tinyurl_v1_tinyurl_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TargetRule) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TargetRule) ProtoMessage() {}
+
+func (x *TargetRule) ProtoReflect() protoreflect.Message {
+	mi := &file_api_tinyurl_v1_tinyurl_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TargetRule.ProtoReflect.Descriptor instead.
+func (*TargetRule) Descriptor() ([]byte, []int) {
+	return file_api_tinyurl_v1_tinyurl_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *TargetRule) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *TargetRule) GetOs() string {
+	if x != nil {
+		return x.Os
+	}
+	return ""
+}
+
+func (x *TargetRule) GetDevice() string {
+	if x != nil {
+		return x.Device
+	}
+	return ""
+}
+
+func (x *TargetRule) GetBot() bool {
+	if x != nil && x.Bot != nil {
+		return *x.Bot
+	}
+	return false
+}
+
+func (x *TargetRule) GetUrl() string {
+	if x != nil {
+		return x.Url
+	}
+	return ""
+}
+
+type Variant struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Url           string                 `protobuf:"bytes,2,opt,name=url,proto3" json:"url,omitempty"`
+	Weight        int32                  `protobuf:"varint,3,opt,name=weight,proto3" json:"weight,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Variant) Reset() {
+	*x = Variant{}
+	mi := &file_api_tinyurl_v1_tinyurl_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Variant) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Variant) ProtoMessage() {}
+
+func (x *Variant) ProtoReflect() protoreflect.Message {
+	mi := &file_api_tinyurl_v1_tinyurl_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Variant.ProtoReflect.Descriptor instead.
+func (*Variant) Descriptor() ([]byte, []int) {
+	return file_api_tinyurl_v1_tinyurl_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *Variant) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *Variant) GetUrl() string {
+	if x != nil {
+		return x.Url
+	}
+	return ""
+}
+
+func (x *Variant) GetWeight() int32 {
+	if x != nil {
+		return x.Weight
+	}
+	return 0
+}
+
+type ShortenRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Url           string                 `protobuf:"bytes,1,opt,name=url,proto3" json:"url,omitempty"`
+	Alias         string                 `protobuf:"bytes,2,opt,name=alias,proto3" json:"alias,omitempty"`
+	TtlDays       int32                  `protobuf:"varint,3,opt,name=ttl_days,json=ttlDays,proto3" json:"ttl_days,omitempty"`
+	RedirectType  int32                  `protobuf:"varint,4,opt,name=redirect_type,json=redirectType,proto3" json:"redirect_type,omitempty"`
+	Passthrough   bool                   `protobuf:"varint,5,opt,name=passthrough,proto3" json:"passthrough,omitempty"`
+	UtmTemplate   string                 `protobuf:"bytes,6,opt,name=utm_template,json=utmTemplate,proto3" json:"utm_template,omitempty"`
+	UtmApply      string                 `protobuf:"bytes,7,opt,name=utm_apply,json=utmApply,proto3" json:"utm_apply,omitempty"`
+	Rules         []*TargetRule          `protobuf:"bytes,8,rep,name=rules,proto3" json:"rules,omitempty"`
+	Variants      []*Variant             `protobuf:"bytes,9,rep,name=variants,proto3" json:"variants,omitempty"`
+	Sticky        bool                   `protobuf:"varint,10,opt,name=sticky,proto3" json:"sticky,omitempty"`
+	Geo           map[string]string      `protobuf:"bytes,11,rep,name=geo,proto3" json:"geo,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	Interstitial  bool                   `protobuf:"varint,12,opt,name=interstitial,proto3" json:"interstitial,omitempty"`
+	Domain        string                 `protobuf:"bytes,13,opt,name=domain,proto3" json:"domain,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ShortenRequest) Reset() {
+	*x = ShortenRequest{}
+	mi := &file_api_tinyurl_v1_tinyurl_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ShortenRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ShortenRequest) ProtoMessage() {}
+
+func (x *ShortenRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_tinyurl_v1_tinyurl_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ShortenRequest.ProtoReflect.Descriptor instead.
+func (*ShortenRequest) Descriptor() ([]byte, []int) {
+	return file_api_tinyurl_v1_tinyurl_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *ShortenRequest) GetUrl() string {
+	if x != nil {
+		return x.Url
+	}
+	return ""
+}
+
+func (x *ShortenRequest) GetAlias() string {
+	if x != nil {
+		return x.Alias
+	}
+	return ""
+}
+
+func (x *ShortenRequest) GetTtlDays() int32 {
+	if x != nil {
+		return x.TtlDays
+	}
+	return 0
+}
+
+func (x *ShortenRequest) GetRedirectType() int32 {
+	if x != nil {
+		return x.RedirectType
+	}
+	return 0
+}
+
+func (x *ShortenRequest) GetPassthrough() bool {
+	if x != nil {
+		return x.Passthrough
+	}
+	return false
+}
+
+func (x *ShortenRequest) GetUtmTemplate() string {
+	if x != nil {
+		return x.UtmTemplate
+	}
+	return ""
+}
+
+func (x *ShortenRequest) GetUtmApply() string {
+	if x != nil {
+		return x.UtmApply
+	}
+	return ""
+}
+
+func (x *ShortenRequest) GetRules() []*TargetRule {
+	if x != nil {
+		return x.Rules
+	}
+	return nil
+}
+
+func (x *ShortenRequest) GetVariants() []*Variant {
+	if x != nil {
+		return x.Variants
+	}
+	return nil
+}
+
+func (x *ShortenRequest) GetSticky() bool {
+	if x != nil {
+		return x.Sticky
+	}
+	return false
+}
+
+func (x *ShortenRequest) GetGeo() map[string]string {
+	if x != nil {
+		return x.Geo
+	}
+	return nil
+}
+
+func (x *ShortenRequest) GetInterstitial() bool {
+	if x != nil {
+		return x.Interstitial
+	}
+	return false
+}
+
+func (x *ShortenRequest) GetDomain() string {
+	if x != nil {
+		return x.Domain
+	}
+	return ""
+}
+
+type ShortenResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Code  string                 `protobuf:"bytes,1,opt,name=code,proto3" json:"code,omitempty"`
+	// short_url абсолютный, если сервер знает свой адрес (TINYURL_BASE_URL) или
+	// ссылка создана в собственном домене. Иначе это путь /r/<code>.
+	ShortUrl      string `protobuf:"bytes,2,opt,name=short_url,json=shortUrl,proto3" json:"short_url,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ShortenResponse) Reset() {
+	*x = ShortenResponse{}
+	mi := &file_api_tinyurl_v1_tinyurl_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ShortenResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ShortenResponse) ProtoMessage() {}
+
+func (x *ShortenResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_api_tinyurl_v1_tinyurl_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ShortenResponse.ProtoReflect.Descriptor instead.
+func (*ShortenResponse) Descriptor() ([]byte, []int) {
+	return file_api_tinyurl_v1_tinyurl_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *ShortenResponse) GetCode() string {
+	if x != nil {
+		return x.Code
+	}
+	return ""
+}
+
+func (x *ShortenResponse) GetShortUrl() string {
+	if x != nil {
+		return x.ShortUrl
+	}
+	return ""
+}
+
+// Link - сохраненная ссылка. Пустой domain означает домен по умолчанию.
+type Link struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Code          string                 `protobuf:"bytes,1,opt,name=code,proto3" json:"code,omitempty"`
+	Domain        string                 `protobuf:"bytes,2,opt,name=domain,proto3" json:"domain,omitempty"`
+	Url           string                 `protobuf:"bytes,3,opt,name=url,proto3" json:"url,omitempty"`
+	CreatedAt     *timestamppb.Timestamp `protobuf:"bytes,4,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	ExpiresAt     *timestamppb.Timestamp `protobuf:"bytes,5,opt,name=expires_at,json=expiresAt,proto3" json:"expires_at,omitempty"`
+	HitCount      int64                  `protobuf:"varint,6,opt,name=hit_count,json=hitCount,proto3" json:"hit_count,omitempty"`
+	RedirectType  int32                  `protobuf:"varint,7,opt,name=redirect_type,json=redirectType,proto3" json:"redirect_type,omitempty"`
+	Passthrough   bool                   `protobuf:"varint,8,opt,name=passthrough,proto3" json:"passthrough,omitempty"`
+	UtmTemplate   string                 `protobuf:"bytes,9,opt,name=utm_template,json=utmTemplate,proto3" json:"utm_template,omitempty"`
+	Rules         []*TargetRule          `protobuf:"bytes,10,rep,name=rules,proto3" json:"rules,omitempty"`
+	Variants      []*Variant             `protobuf:"bytes,11,rep,name=variants,proto3" json:"variants,omitempty"`
+	Sticky        bool                   `protobuf:"varint,12,opt,name=sticky,proto3" json:"sticky,omitempty"`
+	Geo           map[string]string      `protobuf:"bytes,13,rep,name=geo,proto3" json:"geo,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	Interstitial  bool                   `protobuf:"varint,14,opt,name=interstitial,proto3" json:"interstitial,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Link) Reset() {
+	*x = Link{}
+	mi := &file_api_tinyurl_v1_tinyurl_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Link) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Link) ProtoMessage() {}
+
+func (x *Link) ProtoReflect() protoreflect.Message {
+	mi := &file_api_tinyurl_v1_tinyurl_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Link.ProtoReflect.Descriptor instead.
+func (*Link) Descriptor() ([]byte, []int) {
+	return file_api_tinyurl_v1_tinyurl_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *Link) GetCode() string {
+	if x != nil {
+		return x.Code
+	}
+	return ""
+}
+
+func (x *Link) GetDomain() string {
+	if x != nil {
+		return x.Domain
+	}
+	return ""
+}
+
+func (x *Link) GetUrl() string {
+	if x != nil {
+		return x.Url
+	}
+	return ""
+}
+
+func (x *Link) GetCreatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return nil
+}
+
+func (x *Link) GetExpiresAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.ExpiresAt
+	}
+	return nil
+}
+
+func (x *Link) GetHitCount() int64 {
+	if x != nil {
+		return x.HitCount
+	}
+	return 0
+}
+
+func (x *Link) GetRedirectType() int32 {
+	if x != nil {
+		return x.RedirectType
+	}
+	return 0
+}
+
+func (x *Link) GetPassthrough() bool {
+	if x != nil {
+		return x.Passthrough
+	}
+	return false
+}
+
+func (x *Link) GetUtmTemplate() string {
+	if x != nil {
+		return x.UtmTemplate
+	}
+	return ""
+}
+
+func (x *Link) GetRules() []*TargetRule {
+	if x != nil {
+		return x.Rules
+	}
+	return nil
+}
+
+func (x *Link) GetVariants() []*Variant {
+	if x != nil {
+		return x.Variants
+	}
+	return nil
+}
+
+func (x *Link) GetSticky() bool {
+	if x != nil {
+		return x.Sticky
+	}
+	return false
+}
+
+func (x *Link) GetGeo() map[string]string {
+	if x != nil {
+		return x.Geo
+	}
+	return nil
+}
+
+func (x *Link) GetInterstitial() bool {
+	if x != nil {
+		return x.Interstitial
+	}
+	return false
+}
+
+type ResolveRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Code          string                 `protobuf:"bytes,1,opt,name=code,proto3" json:"code,omitempty"`
+	Domain        string                 `protobuf:"bytes,2,opt,name=domain,proto3" json:"domain,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ResolveRequest) Reset() {
+	*x = ResolveRequest{}
+	mi := &file_api_tinyurl_v1_tinyurl_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ResolveRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ResolveRequest) ProtoMessage() {}
+
+func (x *ResolveRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_tinyurl_v1_tinyurl_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ResolveRequest.ProtoReflect.Descriptor instead.
+func (*ResolveRequest) Descriptor() ([]byte, []int) {
+	return file_api_tinyurl_v1_tinyurl_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *ResolveRequest) GetCode() string {
+	if x != nil {
+		return x.Code
+	}
+	return ""
+}
+
+func (x *ResolveRequest) GetDomain() string {
+	if x != nil {
+		return x.Domain
+	}
+	return ""
+}
+
+type ResolveResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Url           string                 `protobuf:"bytes,1,opt,name=url,proto3" json:"url,omitempty"`
+	RedirectType  int32                  `protobuf:"varint,2,opt,name=redirect_type,json=redirectType,proto3" json:"redirect_type,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ResolveResponse) Reset() {
+	*x = ResolveResponse{}
+	mi := &file_api_tinyurl_v1_tinyurl_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ResolveResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ResolveResponse) ProtoMessage() {}
+
+func (x *ResolveResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_api_tinyurl_v1_tinyurl_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ResolveResponse.ProtoReflect.Descriptor instead.
+func (*ResolveResponse) Descriptor() ([]byte, []int) {
+	return file_api_tinyurl_v1_tinyurl_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *ResolveResponse) GetUrl() string {
+	if x != nil {
+		return x.Url
+	}
+	return ""
+}
+
+func (x *ResolveResponse) GetRedirectType() int32 {
+	if x != nil {
+		return x.RedirectType
+	}
+	return 0
+}
+
+type GetStatsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Code          string                 `protobuf:"bytes,1,opt,name=code,proto3" json:"code,omitempty"`
+	Domain        string                 `protobuf:"bytes,2,opt,name=domain,proto3" json:"domain,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetStatsRequest) Reset() {
+	*x = GetStatsRequest{}
+	mi := &file_api_tinyurl_v1_tinyurl_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetStatsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetStatsRequest) ProtoMessage() {}
+
+func (x *GetStatsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_tinyurl_v1_tinyurl_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetStatsRequest.ProtoReflect.Descriptor instead.
+func (*GetStatsRequest) Descriptor() ([]byte, []int) {
+	return file_api_tinyurl_v1_tinyurl_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *GetStatsRequest) GetCode() string {
+	if x != nil {
+		return x.Code
+	}
+	return ""
+}
+
+func (x *GetStatsRequest) GetDomain() string {
+	if x != nil {
+		return x.Domain
+	}
+	return ""
+}
+
+// Counts - число переходов по значениям одного измерения статистики.
+type Counts struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Hits          map[string]int64       `protobuf:"bytes,1,rep,name=hits,proto3" json:"hits,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"varint,2,opt,name=value"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Counts) Reset() {
+	*x = Counts{}
+	mi := &file_api_tinyurl_v1_tinyurl_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Counts) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Counts) ProtoMessage() {}
+
+func (x *Counts) ProtoReflect() protoreflect.Message {
+	mi := &file_api_tinyurl_v1_tinyurl_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Counts.ProtoReflect.Descriptor instead.
+func (*Counts) Descriptor() ([]byte, []int) {
+	return file_api_tinyurl_v1_tinyurl_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *Counts) GetHits() map[string]int64 {
+	if x != nil {
+		return x.Hits
+	}
+	return nil
+}
+
+type GetStatsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Link          *Link                  `protobuf:"bytes,1,opt,name=link,proto3" json:"link,omitempty"`
+	Breakdown     map[string]*Counts     `protobuf:"bytes,2,rep,name=breakdown,proto3" json:"breakdown,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetStatsResponse) Reset() {
+	*x = GetStatsResponse{}
+	mi := &file_api_tinyurl_v1_tinyurl_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetStatsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetStatsResponse) ProtoMessage() {}
+
+func (x *GetStatsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_api_tinyurl_v1_tinyurl_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetStatsResponse.ProtoReflect.Descriptor instead.
+func (*GetStatsResponse) Descriptor() ([]byte, []int) {
+	return file_api_tinyurl_v1_tinyurl_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *GetStatsResponse) GetLink() *Link {
+	if x != nil {
+		return x.Link
+	}
+	return nil
+}
+
+func (x *GetStatsResponse) GetBreakdown() map[string]*Counts {
+	if x != nil {
+		return x.Breakdown
+	}
+	return nil
+}
+
+// UpdateRequest меняет только заданные поля.
+type UpdateRequest struct {
+	state  protoimpl.MessageState `protogen:"open.v1"`
+	Code   string                 `protobuf:"bytes,1,opt,name=code,proto3" json:"code,omitempty"`
+	Domain string                 `protobuf:"bytes,2,opt,name=domain,proto3" json:"domain,omitempty"`
+	Url    *string                `protobuf:"bytes,3,opt,name=url,proto3,oneof" json:"url,omitempty"`
+	// ttl_days отсчитывается от момента изменения; 0 снимает срок действия.
+	TtlDays *int32 `protobuf:"varint,4,opt,name=ttl_days,json=ttlDays,proto3,oneof" json:"ttl_days,omitempty"`
+	// redirect_type 0 возвращает тип перенаправления по умолчанию для домена.
+	RedirectType  *int32 `protobuf:"varint,5,opt,name=redirect_type,json=redirectType,proto3,oneof" json:"redirect_type,omitempty"`
+	Passthrough   *bool  `protobuf:"varint,6,opt,name=passthrough,proto3,oneof" json:"passthrough,omitempty"`
+	Interstitial  *bool  `protobuf:"varint,7,opt,name=interstitial,proto3,oneof" json:"interstitial,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UpdateRequest) Reset() {
+	*x = UpdateRequest{}
+	mi := &file_api_tinyurl_v1_tinyurl_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UpdateRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdateRequest) ProtoMessage() {}
+
+func (x *UpdateRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_tinyurl_v1_tinyurl_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpdateRequest.ProtoReflect.Descriptor instead.
+func (*UpdateRequest) Descriptor() ([]byte, []int) {
+	return file_api_tinyurl_v1_tinyurl_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *UpdateRequest) GetCode() string {
+	if x != nil {
+		return x.Code
+	}
+	return ""
+}
+
+func (x *UpdateRequest) GetDomain() string {
+	if x != nil {
+		return x.Domain
+	}
+	return ""
+}
+
+func (x *UpdateRequest) GetUrl() string {
+	if x != nil && x.Url != nil {
+		return *x.Url
+	}
+	return ""
+}
+
+func (x *UpdateRequest) GetTtlDays() int32 {
+	if x != nil && x.TtlDays != nil {
+		return *x.TtlDays
+	}
+	return 0
+}
+
+func (x *UpdateRequest) GetRedirectType() int32 {
+	if x != nil && x.RedirectType != nil {
+		return *x.RedirectType
+	}
+	return 0
+}
+
+func (x *UpdateRequest) GetPassthrough() bool {
+	if x != nil && x.Passthrough != nil {
+		return *x.Passthrough
+	}
+	return false
+}
+
+func (x *UpdateRequest) GetInterstitial() bool {
+	if x != nil && x.Interstitial != nil {
+		return *x.Interstitial
+	}
+	return false
+}
+
+type DeleteRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Code          string                 `protobuf:"bytes,1,opt,name=code,proto3" json:"code,omitempty"`
+	Domain        string                 `protobuf:"bytes,2,opt,name=domain,proto3" json:"domain,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteRequest) Reset() {
+	*x = DeleteRequest{}
+	mi := &file_api_tinyurl_v1_tinyurl_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteRequest) ProtoMessage() {}
+
+func (x *DeleteRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_tinyurl_v1_tinyurl_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteRequest.ProtoReflect.Descriptor instead.
+func (*DeleteRequest) Descriptor() ([]byte, []int) {
+	return file_api_tinyurl_v1_tinyurl_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *DeleteRequest) GetCode() string {
+	if x != nil {
+		return x.Code
+	}
+	return ""
+}
+
+func (x *DeleteRequest) GetDomain() string {
+	if x != nil {
+		return x.Domain
+	}
+	return ""
+}
+
+type DeleteResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteResponse) Reset() {
+	*x = DeleteResponse{}
+	mi := &file_api_tinyurl_v1_tinyurl_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteResponse) ProtoMessage() {}
+
+func (x *DeleteResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_api_tinyurl_v1_tinyurl_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteResponse.ProtoReflect.Descriptor instead.
+func (*DeleteResponse) Descriptor() ([]byte, []int) {
+	return file_api_tinyurl_v1_tinyurl_proto_rawDescGZIP(), []int{12}
+}
+
+type ListRequest struct {
+	state  protoimpl.MessageState `protogen:"open.v1"`
+	Domain string                 `protobuf:"bytes,1,opt,name=domain,proto3" json:"domain,omitempty"`
+	// page_size по умолчанию 50, максимум 1000.
+	PageSize  int32  `protobuf:"varint,2,opt,name=page_size,json=pageSize,proto3" json:"page_size,omitempty"`
+	PageToken string `protobuf:"bytes,3,opt,name=page_token,json=pageToken,proto3" json:"page_token,omitempty"`
+	// search отбирает ссылки, у которых код или URL содержит подстроку.
+	Search        string `protobuf:"bytes,4,opt,name=search,proto3" json:"search,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListRequest) Reset() {
+	*x = ListRequest{}
+	mi := &file_api_tinyurl_v1_tinyurl_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListRequest) ProtoMessage() {}
+
+func (x *ListRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_tinyurl_v1_tinyurl_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListRequest.ProtoReflect.Descriptor instead.
+func (*ListRequest) Descriptor() ([]byte, []int) {
+	return file_api_tinyurl_v1_tinyurl_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *ListRequest) GetDomain() string {
+	if x != nil {
+		return x.Domain
+	}
+	return ""
+}
+
+func (x *ListRequest) GetPageSize() int32 {
+	if x != nil {
+		return x.PageSize
+	}
+	return 0
+}
+
+func (x *ListRequest) GetPageToken() string {
+	if x != nil {
+		return x.PageToken
+	}
+	return ""
+}
+
+func (x *ListRequest) GetSearch() string {
+	if x != nil {
+		return x.Search
+	}
+	return ""
+}
+
+type ListResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Ссылки в списке содержат только основные поля, без правил, вариантов и гео.
+	Links         []*Link `protobuf:"bytes,1,rep,name=links,proto3" json:"links,omitempty"`
+	NextPageToken string  `protobuf:"bytes,2,opt,name=next_page_token,json=nextPageToken,proto3" json:"next_page_token,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListResponse) Reset() {
+	*x = ListResponse{}
+	mi := &file_api_tinyurl_v1_tinyurl_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListResponse) ProtoMessage() {}
+
+func (x *ListResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_api_tinyurl_v1_tinyurl_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListResponse.ProtoReflect.Descriptor instead.
+func (*ListResponse) Descriptor() ([]byte, []int) {
+	return file_api_tinyurl_v1_tinyurl_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *ListResponse) GetLinks() []*Link {
+	if x != nil {
+		return x.Links
+	}
+	return nil
+}
+
+func (x *ListResponse) GetNextPageToken() string {
+	if x != nil {
+		return x.NextPageToken
+	}
+	return ""
+}
+
+var File_api_tinyurl_v1_tinyurl_proto protoreflect.FileDescriptor
+
+const file_api_tinyurl_v1_tinyurl_proto_rawDesc = "" +
+	"\n" +
+	"\x1capi/tinyurl/v1/tinyurl.proto\x12\n" +
+	"tinyurl.v1\x1a\x1fgoogle/protobuf/timestamp.proto\"y\n" +
+	"\n" +
+	"TargetRule\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\x0e\n" +
+	"\x02os\x18\x02 \x01(\tR\x02os\x12\x16\n" +
+	"\x06device\x18\x03 \x01(\tR\x06device\x12\x15\n" +
+	"\x03bot\x18\x04 \x01(\bH\x00R\x03bot\x88\x01\x01\x12\x10\n" +
+	"\x03url\x18\x05 \x01(\tR\x03urlB\x06\n" +
+	"\x04_bot\"G\n" +
+	"\aVariant\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\x10\n" +
+	"\x03url\x18\x02 \x01(\tR\x03url\x12\x16\n" +
+	"\x06weight\x18\x03 \x01(\x05R\x06weight\"\xfc\x03\n" +
+	"\x0eShortenRequest\x12\x10\n" +
+	"\x03url\x18\x01 \x01(\tR\x03url\x12\x14\n" +
+	"\x05alias\x18\x02 \x01(\tR\x05alias\x12\x19\n" +
+	"\bttl_days\x18\x03 \x01(\x05R\attlDays\x12#\n" +
+	"\rredirect_type\x18\x04 \x01(\x05R\fredirectType\x12 \n" +
+	"\vpassthrough\x18\x05 \x01(\bR\vpassthrough\x12!\n" +
+	"\futm_template\x18\x06 \x01(\tR\vutmTemplate\x12\x1b\n" +
+	"\tutm_apply\x18\a \x01(\tR\butmApply\x12,\n" +
+	"\x05rules\x18\b \x03(\v2\x16.tinyurl.v1.TargetRuleR\x05rules\x12/\n" +
+	"\bvariants\x18\t \x03(\v2\x13.tinyurl.v1.VariantR\bvariants\x12\x16\n" +
+	"\x06sticky\x18\n" +
+	" \x01(\bR\x06sticky\x125\n" +
+	"\x03geo\x18\v \x03(\v2#.tinyurl.v1.ShortenRequest.GeoEntryR\x03geo\x12\"\n" +
+	"\finterstitial\x18\f \x01(\bR\finterstitial\x12\x16\n" +
+	"\x06domain\x18\r \x01(\tR\x06domain\x1a6\n" +
+	"\bGeoEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"B\n" +
+	"\x0fShortenResponse\x12\x12\n" +
+	"\x04code\x18\x01 \x01(\tR\x04code\x12\x1b\n" +
+	"\tshort_url\x18\x02 \x01(\tR\bshortUrl\"\xc1\x04\n" +
+	"\x04Link\x12\x12\n" +
+	"\x04code\x18\x01 \x01(\tR\x04code\x12\x16\n" +
+	"\x06domain\x18\x02 \x01(\tR\x06domain\x12\x10\n" +
+	"\x03url\x18\x03 \x01(\tR\x03url\x129\n" +
+	"\n" +
+	"created_at\x18\x04 \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt\x129\n" +
+	"\n" +
+	"expires_at\x18\x05 \x01(\v2\x1a.google.protobuf.TimestampR\texpiresAt\x12\x1b\n" +
+	"\thit_count\x18\x06 \x01(\x03R\bhitCount\x12#\n" +
+	"\rredirect_type\x18\a \x01(\x05R\fredirectType\x12 \n" +
+	"\vpassthrough\x18\b \x01(\bR\vpassthrough\x12!\n" +
+	"\futm_template\x18\t \x01(\tR\vutmTemplate\x12,\n" +
+	"\x05rules\x18\n" +
+	" \x03(\v2\x16.tinyurl.v1.TargetRuleR\x05rules\x12/\n" +
+	"\bvariants\x18\v \x03(\v2\x13.tinyurl.v1.VariantR\bvariants\x12\x16\n" +
+	"\x06sticky\x18\f \x01(\bR\x06sticky\x12+\n" +
+	"\x03geo\x18\r \x03(\v2\x19.tinyurl.v1.Link.GeoEntryR\x03geo\x12\"\n" +
+	"\finterstitial\x18\x0e \x01(\bR\finterstitial\x1a6\n" +
+	"\bGeoEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"<\n" +
+	"\x0eResolveRequest\x12\x12\n" +
+	"\x04code\x18\x01 \x01(\tR\x04code\x12\x16\n" +
+	"\x06domain\x18\x02 \x01(\tR\x06domain\"H\n" +
+	"\x0fResolveResponse\x12\x10\n" +
+	"\x03url\x18\x01 \x01(\tR\x03url\x12#\n" +
+	"\rredirect_type\x18\x02 \x01(\x05R\fredirectType\"=\n" +
+	"\x0fGetStatsRequest\x12\x12\n" +
+	"\x04code\x18\x01 \x01(\tR\x04code\x12\x16\n" +
+	"\x06domain\x18\x02 \x01(\tR\x06domain\"s\n" +
+	"\x06Counts\x120\n" +
+	"\x04hits\x18\x01 \x03(\v2\x1c.tinyurl.v1.Counts.HitsEntryR\x04hits\x1a7\n" +
+	"\tHitsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\x03R\x05value:\x028\x01\"\xd5\x01\n" +
+	"\x10GetStatsResponse\x12$\n" +
+	"\x04link\x18\x01 \x01(\v2\x10.tinyurl.v1.LinkR\x04link\x12I\n" +
+	"\tbreakdown\x18\x02 \x03(\v2+.tinyurl.v1.GetStatsResponse.BreakdownEntryR\tbreakdown\x1aP\n" +
+	"\x0eBreakdownEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12(\n" +
+	"\x05value\x18\x02 \x01(\v2\x12.tinyurl.v1.CountsR\x05value:\x028\x01\"\xb4\x02\n" +
+	"\rUpdateRequest\x12\x12\n" +
+	"\x04code\x18\x01 \x01(\tR\x04code\x12\x16\n" +
+	"\x06domain\x18\x02 \x01(\tR\x06domain\x12\x15\n" +
+	"\x03url\x18\x03 \x01(\tH\x00R\x03url\x88\x01\x01\x12\x1e\n" +
+	"\bttl_days\x18\x04 \x01(\x05H\x01R\attlDays\x88\x01\x01\x12(\n" +
+	"\rredirect_type\x18\x05 \x01(\x05H\x02R\fredirectType\x88\x01\x01\x12%\n" +
+	"\vpassthrough\x18\x06 \x01(\bH\x03R\vpassthrough\x88\x01\x01\x12'\n" +
+	"\finterstitial\x18\a \x01(\bH\x04R\finterstitial\x88\x01\x01B\x06\n" +
+	"\x04_urlB\v\n" +
+	"\t_ttl_daysB\x10\n" +
+	"\x0e_redirect_typeB\x0e\n" +
+	"\f_passthroughB\x0f\n" +
+	"\r_interstitial\";\n" +
+	"\rDeleteRequest\x12\x12\n" +
+	"\x04code\x18\x01 \x01(\tR\x04code\x12\x16\n" +
+	"\x06domain\x18\x02 \x01(\tR\x06domain\"\x10\n" +
+	"\x0eDeleteResponse\"y\n" +
+	"\vListRequest\x12\x16\n" +
+	"\x06domain\x18\x01 \x01(\tR\x06domain\x12\x1b\n" +
+	"\tpage_size\x18\x02 \x01(\x05R\bpageSize\x12\x1d\n" +
+	"\n" +
+	"page_token\x18\x03 \x01(\tR\tpageToken\x12\x16\n" +
+	"\x06search\x18\x04 \x01(\tR\x06search\"^\n" +
+	"\fListResponse\x12&\n" +
+	"\x05links\x18\x01 \x03(\v2\x10.tinyurl.v1.LinkR\x05links\x12&\n" +
+	"\x0fnext_page_token\x18\x02 \x01(\tR\rnextPageToken2\x8b\x03\n" +
+	"\aTinyURL\x12B\n" +
+	"\aShorten\x12\x1a.tinyurl.v1.ShortenRequest\x1a\x1b.tinyurl.v1.ShortenResponse\x12B\n" +
+	"\aResolve\x12\x1a.tinyurl.v1.ResolveRequest\x1a\x1b.tinyurl.v1.ResolveResponse\x12E\n" +
+	"\bGetStats\x12\x1b.tinyurl.v1.GetStatsRequest\x1a\x1c.tinyurl.v1.GetStatsResponse\x125\n" +
+	"\x06Update\x12\x19.tinyurl.v1.UpdateRequest\x1a\x10.tinyurl.v1.Link\x12?\n" +
+	"\x06Delete\x12\x19.tinyurl.v1.DeleteRequest\x1a\x1a.tinyurl.v1.DeleteResponse\x129\n" +
+	"\x04List\x12\x17.tinyurl.v1.ListRequest\x1a\x18.tinyurl.v1.ListResponseB\"Z tinyurl/api/tinyurl/v1;tinyurlv1b\x06proto3"
+
+var (
+	file_api_tinyurl_v1_tinyurl_proto_rawDescOnce sync.Once
+	file_api_tinyurl_v1_tinyurl_proto_rawDescData []byte
+)
+
+func file_api_tinyurl_v1_tinyurl_proto_rawDescGZIP() []byte {
+	file_api_tinyurl_v1_tinyurl_proto_rawDescOnce.Do(func() {
+		file_api_tinyurl_v1_tinyurl_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_api_tinyurl_v1_tinyurl_proto_rawDesc), len(file_api_tinyurl_v1_tinyurl_proto_rawDesc)))
+	})
+	return file_api_tinyurl_v1_tinyurl_proto_rawDescData
+}
+
+var file_api_tinyurl_v1_tinyurl_proto_msgTypes = make([]protoimpl.MessageInfo, 19)
+var file_api_tinyurl_v1_tinyurl_proto_goTypes = []any{
+	(*TargetRule)(nil),            // 0: tinyurl.v1.TargetRule
+	(*Variant)(nil),               // 1: tinyurl.v1.Variant
+	(*ShortenRequest)(nil),        // 2: tinyurl.v1.ShortenRequest
+	(*ShortenResponse)(nil),       // 3: tinyurl.v1.ShortenResponse
+	(*Link)(nil),                  // 4: tinyurl.v1.Link
+	(*ResolveRequest)(nil),        // 5: tinyurl.v1.ResolveRequest
+	(*ResolveResponse)(nil),       // 6: tinyurl.v1.ResolveResponse
+	(*GetStatsRequest)(nil),       // 7: tinyurl.v1.GetStatsRequest
+	(*Counts)(nil),                // 8: tinyurl.v1.Counts
+	(*GetStatsResponse)(nil),      // 9: tinyurl.v1.GetStatsResponse
+	(*UpdateRequest)(nil),         // 10: tinyurl.v1.UpdateRequest
+	(*DeleteRequest)(nil),         // 11: tinyurl.v1.DeleteRequest
+	(*DeleteResponse)(nil),        // 12: tinyurl.v1.DeleteResponse
+	(*ListRequest)(nil),           // 13: tinyurl.v1.ListRequest
+	(*ListResponse)(nil),          // 14: tinyurl.v1.ListResponse
+	nil,                           // 15: tinyurl.v1.ShortenRequest.GeoEntry
+	nil,                           // 16: tinyurl.v1.Link.GeoEntry
+	nil,                           // 17: tinyurl.v1.Counts.HitsEntry
+	nil,                           // 18: tinyurl.v1.GetStatsResponse.BreakdownEntry
+	(*timestamppb.Timestamp)(nil), // 19: google.protobuf.Timestamp
+}
+var file_api_tinyurl_v1_tinyurl_proto_depIdxs = []int32{
+	0,  // 0: tinyurl.v1.ShortenRequest.rules:type_name -> tinyurl.v1.TargetRule
+	1,  // 1: tinyurl.v1.ShortenRequest.variants:type_name -> tinyurl.v1.Variant
+	15, // 2: tinyurl.v1.ShortenRequest.geo:type_name -> tinyurl.v1.ShortenRequest.GeoEntry
+	19, // 3: tinyurl.v1.Link.created_at:type_name -> google.protobuf.Timestamp
+	19, // 4: tinyurl.v1.Link.expires_at:type_name -> google.protobuf.Timestamp
+	0,  // 5: tinyurl.v1.Link.rules:type_name -> tinyurl.v1.TargetRule
+	1,  // 6: tinyurl.v1.Link.variants:type_name -> tinyurl.v1.Variant
+	16, // 7: tinyurl.v1.Link.geo:type_name -> tinyurl.v1.Link.GeoEntry
+	17, // 8: tinyurl.v1.Counts.hits:type_name -> tinyurl.v1.Counts.HitsEntry
+	4,  // 9: tinyurl.v1.GetStatsResponse.link:type_name -> tinyurl.v1.Link
+	18, // 10: tinyurl.v1.GetStatsResponse.breakdown:type_name -> tinyurl.v1.GetStatsResponse.BreakdownEntry
+	4,  // 11: tinyurl.v1.ListResponse.links:type_name -> tinyurl.v1.Link
+	8,  // 12: tinyurl.v1.GetStatsResponse.BreakdownEntry.value:type_name -> tinyurl.v1.Counts
+	2,  // 13: tinyurl.v1.TinyURL.Shorten:input_type -> tinyurl.v1.ShortenRequest
+	5,  // 14: tinyurl.v1.TinyURL.Resolve:input_type -> tinyurl.v1.ResolveRequest
+	7,  // 15: tinyurl.v1.TinyURL.GetStats:input_type -> tinyurl.v1.GetStatsRequest
+	10, // 16: tinyurl.v1.TinyURL.Update:input_type -> tinyurl.v1.UpdateRequest
+	11, // 17: tinyurl.v1.TinyURL.Delete:input_type -> tinyurl.v1.DeleteRequest
+	13, // 18: tinyurl.v1.TinyURL.List:input_type -> tinyurl.v1.ListRequest
+	3,  // 19: tinyurl.v1.TinyURL.Shorten:output_type -> tinyurl.v1.ShortenResponse
+	6,  // 20: tinyurl.v1.TinyURL.Resolve:output_type -> tinyurl.v1.ResolveResponse
+	9,  // 21: tinyurl.v1.TinyURL.GetStats:output_type -> tinyurl.v1.GetStatsResponse
+	4,  // 22: tinyurl.v1.TinyURL.Update:output_type -> tinyurl.v1.Link
+	12, // 23: tinyurl.v1.TinyURL.Delete:output_type -> tinyurl.v1.DeleteResponse
+	14, // 24: tinyurl.v1.TinyURL.List:output_type -> tinyurl.v1.ListResponse
+	19, // [19:25] is the sub-list for method output_type
+	13, // [13:19] is the sub-list for method input_type
+	13, // [13:13] is the sub-list for extension type_name
+	13, // [13:13] is the sub-list for extension extendee
+	0,  // [0:13] is the sub-list for field type_name
+}
+
+func init() { file_api_tinyurl_v1_tinyurl_proto_init() }
+func file_api_tinyurl_v1_tinyurl_proto_init() {
+	if File_api_tinyurl_v1_tinyurl_proto != nil {
+		return
+	}
+	file_api_tinyurl_v1_tinyurl_proto_msgTypes[0].OneofWrappers = []any{}
+	file_api_tinyurl_v1_tinyurl_proto_msgTypes[10].OneofWrappers = []any{}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_api_tinyurl_v1_tinyurl_proto_rawDesc), len(file_api_tinyurl_v1_tinyurl_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   19,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_api_tinyurl_v1_tinyurl_proto_goTypes,
+		DependencyIndexes: file_api_tinyurl_v1_tinyurl_proto_depIdxs,
+		MessageInfos:      file_api_tinyurl_v1_tinyurl_proto_msgTypes,
+	}.Build()
+	File_api_tinyurl_v1_tinyurl_proto = out.File
+	file_api_tinyurl_v1_tinyurl_proto_goTypes = nil
+	file_api_tinyurl_v1_tinyurl_proto_depIdxs = nil
+}
@@ -0,0 +1,337 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             v5.29.0
+// source: api/tinyurl/v1/tinyurl.proto
+
+package tinyurlv1
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	TinyURL_Shorten_FullMethodName  = "/tinyurl.v1.TinyURL/Shorten"
+	TinyURL_Resolve_FullMethodName  = "/tinyurl.v1.TinyURL/Resolve"
+	TinyURL_GetStats_FullMethodName = "/tinyurl.v1.TinyURL/GetStats"
+	TinyURL_Update_FullMethodName   = "/tinyurl.v1.TinyURL/Update"
+	TinyURL_Delete_FullMethodName   = "/tinyurl.v1.TinyURL/Delete"
+	TinyURL_List_FullMethodName     = "/tinyurl.v1.TinyURL/List"
+)
+
+// TinyURLClient is the client API for TinyURL service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// TinyURL - gRPC-версия API сокращения ссылок. Сервис использует то же хранилище
+// и те же проверки, что и HTTP API /api/v1.
+//
+// Ошибки возвращаются со статусом gRPC и деталью google.rpc.ErrorInfo: reason
+// содержит стабильный код ошибки HTTP API (например, alias_taken), metadata.field -
+// поле запроса. Язык сообщения выбирается по метаданным accept-language.
+type TinyURLClient interface {
+	// Shorten создает короткую ссылку.
+	Shorten(ctx context.Context, in *ShortenRequest, opts ...grpc.CallOption) (*ShortenResponse, error)
+	// Resolve возвращает адрес назначения ссылки без учета перехода в статистике.
+	Resolve(ctx context.Context, in *ResolveRequest, opts ...grpc.CallOption) (*ResolveResponse, error)
+	// GetStats возвращает ссылку и разбивку переходов.
+	GetStats(ctx context.Context, in *GetStatsRequest, opts ...grpc.CallOption) (*GetStatsResponse, error)
+	// Update меняет адрес назначения и настройки ссылки.
+	Update(ctx context.Context, in *UpdateRequest, opts ...grpc.CallOption) (*Link, error)
+	// Delete удаляет ссылку вместе со статистикой.
+	Delete(ctx context.Context, in *DeleteRequest, opts ...grpc.CallOption) (*DeleteResponse, error)
+	// List возвращает ссылки домена постранично, от старых к новым.
+	List(ctx context.Context, in *ListRequest, opts ...grpc.CallOption) (*ListResponse, error)
+}
+
+type tinyURLClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewTinyURLClient(cc grpc.ClientConnInterface) TinyURLClient {
+	return &tinyURLClient{cc}
+}
+
+func (c *tinyURLClient) Shorten(ctx context.Context, in *ShortenRequest, opts ...grpc.CallOption) (*ShortenResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ShortenResponse)
+	err := c.cc.Invoke(ctx, TinyURL_Shorten_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *tinyURLClient) Resolve(ctx context.Context, in *ResolveRequest, opts ...grpc.CallOption) (*ResolveResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ResolveResponse)
+	err := c.cc.Invoke(ctx, TinyURL_Resolve_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *tinyURLClient) GetStats(ctx context.Context, in *GetStatsRequest, opts ...grpc.CallOption) (*GetStatsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetStatsResponse)
+	err := c.cc.Invoke(ctx, TinyURL_GetStats_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *tinyURLClient) Update(ctx context.Context, in *UpdateRequest, opts ...grpc.CallOption) (*Link, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Link)
+	err := c.cc.Invoke(ctx, TinyURL_Update_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *tinyURLClient) Delete(ctx context.Context, in *DeleteRequest, opts ...grpc.CallOption) (*DeleteResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(DeleteResponse)
+	err := c.cc.Invoke(ctx, TinyURL_Delete_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *tinyURLClient) List(ctx context.Context, in *ListRequest, opts ...grpc.CallOption) (*ListResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListResponse)
+	err := c.cc.Invoke(ctx, TinyURL_List_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// TinyURLServer is the server API for TinyURL service.
+// All implementations must embed UnimplementedTinyURLServer
+// for forward compatibility.
+//
+// TinyURL - gRPC-версия API сокращения ссылок. Сервис использует то же хранилище
+// и те же проверки, что и HTTP API /api/v1.
+//
+// Ошибки возвращаются со статусом gRPC и деталью google.rpc.ErrorInfo: reason
+// содержит стабильный код ошибки HTTP API (например, alias_taken), metadata.field -
+// поле запроса. Язык сообщения выбирается по метаданным accept-language.
+type TinyURLServer interface {
+	// Shorten создает короткую ссылку.
+	Shorten(context.Context, *ShortenRequest) (*ShortenResponse, error)
+	// Resolve возвращает адрес назначения ссылки без учета перехода в статистике.
+	Resolve(context.Context, *ResolveRequest) (*ResolveResponse, error)
+	// GetStats возвращает ссылку и разбивку переходов.
+	GetStats(context.Context, *GetStatsRequest) (*GetStatsResponse, error)
+	// Update меняет адрес назначения и настройки ссылки.
+	Update(context.Context, *UpdateRequest) (*Link, error)
+	// Delete удаляет ссылку вместе со статистикой.
+	Delete(context.Context, *DeleteRequest) (*DeleteResponse, error)
+	// List возвращает ссылки домена постранично, от старых к новым.
+	List(context.Context, *ListRequest) (*ListResponse, error)
+	mustEmbedUnimplementedTinyURLServer()
+}
+
+// UnimplementedTinyURLServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedTinyURLServer struct{}
+
+func (UnimplementedTinyURLServer) Shorten(context.Context, *ShortenRequest) (*ShortenResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Shorten not implemented")
+}
+func (UnimplementedTinyURLServer) Resolve(context.Context, *ResolveRequest) (*ResolveResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Resolve not implemented")
+}
+func (UnimplementedTinyURLServer) GetStats(context.Context, *GetStatsRequest) (*GetStatsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetStats not implemented")
+}
+func (UnimplementedTinyURLServer) Update(context.Context, *UpdateRequest) (*Link, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Update not implemented")
+}
+func (UnimplementedTinyURLServer) Delete(context.Context, *DeleteRequest) (*DeleteResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Delete not implemented")
+}
+func (UnimplementedTinyURLServer) List(context.Context, *ListRequest) (*ListResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method List not implemented")
+}
+func (UnimplementedTinyURLServer) mustEmbedUnimplementedTinyURLServer() {}
+func (UnimplementedTinyURLServer) testEmbeddedByValue()                 {}
+
+// UnsafeTinyURLServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to TinyURLServer will
+// result in compilation errors.
+type UnsafeTinyURLServer interface {
+	mustEmbedUnimplementedTinyURLServer()
+}
+
+func RegisterTinyURLServer(s grpc.ServiceRegistrar, srv TinyURLServer) {
+	// If the following call pancis, it indicates UnimplementedTinyURLServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&TinyURL_ServiceDesc, srv)
+}
+
+func _TinyURL_Shorten_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ShortenRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TinyURLServer).Shorten(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TinyURL_Shorten_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TinyURLServer).Shorten(ctx, req.(*ShortenRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TinyURL_Resolve_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ResolveRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TinyURLServer).Resolve(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TinyURL_Resolve_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TinyURLServer).Resolve(ctx, req.(*ResolveRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TinyURL_GetStats_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetStatsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TinyURLServer).GetStats(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TinyURL_GetStats_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TinyURLServer).GetStats(ctx, req.(*GetStatsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TinyURL_Update_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UpdateRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TinyURLServer).Update(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TinyURL_Update_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TinyURLServer).Update(ctx, req.(*UpdateRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TinyURL_Delete_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TinyURLServer).Delete(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TinyURL_Delete_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TinyURLServer).Delete(ctx, req.(*DeleteRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TinyURL_List_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TinyURLServer).List(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TinyURL_List_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TinyURLServer).List(ctx, req.(*ListRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// TinyURL_ServiceDesc is the grpc.ServiceDesc for TinyURL service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var TinyURL_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "tinyurl.v1.TinyURL",
+	HandlerType: (*TinyURLServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Shorten",
+			Handler:    _TinyURL_Shorten_Handler,
+		},
+		{
+			MethodName: "Resolve",
+			Handler:    _TinyURL_Resolve_Handler,
+		},
+		{
+			MethodName: "GetStats",
+			Handler:    _TinyURL_GetStats_Handler,
+		},
+		{
+			MethodName: "Update",
+			Handler:    _TinyURL_Update_Handler,
+		},
+		{
+			MethodName: "Delete",
+			Handler:    _TinyURL_Delete_Handler,
+		},
+		{
+			MethodName: "List",
+			Handler:    _TinyURL_List_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "api/tinyurl/v1/tinyurl.proto",
+}
@@ -0,0 +1,177 @@
+package main
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"tinyurl/internal/i18n"
+	"tinyurl/pkg/client"
+)
+
+const (
+	// codesCacheTTL - сколько живет кэш кодов для автодополнения. Его хватает на
+	// несколько нажатий Tab подряд, а новые ссылки появляются в подсказках быстро.
+	codesCacheTTL = 30 * time.Second
+	// completionTimeout ограничивает запрос к серверу, чтобы оболочка не зависала.
+	completionTimeout = 2 * time.Second
+	// completionLimit - сколько ссылок запрашивается для подсказок за раз.
+	completionLimit = 1000
+)
+
+// codesCache - кэш кодов одного сервера и домена.
+type codesCache struct {
+	FetchedAt time.Time        `json:"fetched_at"`
+	Complete  bool             `json:"complete"`
+	Links     []completionLink `json:"links"`
+}
+
+type completionLink struct {
+	Code string `json:"code"`
+	URL  string `json:"url"`
+}
+
+func newCompletionCmd(root *cobra.Command) *cobra.Command {
+	return &cobra.Command{
+		Use:       "completion [bash|zsh|fish|powershell]",
+		Short:     i18n.T(lang, i18n.CLICompletionShort),
+		Long:      i18n.T(lang, i18n.CLICompletionLong),
+		Args:      cobra.MatchAll(cobra.ExactArgs(1), cobra.OnlyValidArgs),
+		ValidArgs: []string{"bash", "zsh", "fish", "powershell"},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			switch args[0] {
+			case "bash":
+				return root.GenBashCompletionV2(os.Stdout, true)
+			case "zsh":
+				return root.GenZshCompletion(os.Stdout)
+			case "fish":
+				return root.GenFishCompletion(os.Stdout, true)
+			default:
+				return root.GenPowerShellCompletionWithDesc(os.Stdout)
+			}
+		},
+	}
+}
+
+// completeCodes подсказывает коды ссылок по первым буквам. Подсказка - это
+// URL ссылки, его показывают zsh, fish и PowerShell.
+func completeCodes(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) > 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	// При автодополнении cobra не вызывает PersistentPreRunE, поэтому профиль
+	// применяется здесь.
+	if err := applyConfig(cmd); err != nil {
+		return nil, cobra.ShellCompDirectiveError
+	}
+	api = connect()
+	api.HTTPClient.Timeout = completionTimeout
+	api.MaxRetries = 0
+
+	links, err := completionLinks(toComplete)
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveError
+	}
+
+	var suggestions []string
+	for _, link := range links {
+		if strings.HasPrefix(link.Code, toComplete) {
+			suggestions = append(suggestions, link.Code+"\t"+link.URL)
+		}
+	}
+	return suggestions, cobra.ShellCompDirectiveNoFileComp
+}
+
+// completionLinks берет коды из кэша или запрашивает их у сервера. Если ссылок
+// больше, чем помещается в кэш, ссылки с введенным префиксом ищутся отдельно.
+func completionLinks(prefix string) ([]completionLink, error) {
+	path := codesCachePath()
+	cache, ok := readCodesCache(path)
+	if !ok {
+		var err error
+		if cache, err = fetchCodes(""); err != nil {
+			return nil, err
+		}
+		writeCodesCache(path, cache)
+	}
+	if cache.Complete || prefix == "" {
+		return cache.Links, nil
+	}
+
+	found, err := fetchCodes(prefix)
+	if err != nil {
+		return nil, err
+	}
+	return found.Links, nil
+}
+
+func fetchCodes(search string) (*codesCache, error) {
+	opts := client.ListOptions{Domain: domainName, Search: search, PageSize: completionLimit}
+	page, err := api.ListLinks(context.Background(), opts)
+	if err != nil {
+		return nil, err
+	}
+
+	cache := &codesCache{FetchedAt: time.Now(), Complete: page.NextPageToken == ""}
+	for _, link := range page.Links {
+		cache.Links = append(cache.Links, completionLink{Code: link.Code, URL: link.URL})
+	}
+	return cache, nil
+}
+
+// codesCachePath возвращает файл кэша для текущего сервера и домена. Пустая
+// строка означает, что кэш недоступен.
+func codesCachePath() string {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return ""
+	}
+	sum := sha256.Sum256([]byte(serverURL + "\n" + domainName))
+	return filepath.Join(dir, "tinyurl", "codes-"+hex.EncodeToString(sum[:8])+".json")
+}
+
+func readCodesCache(path string) (*codesCache, bool) {
+	if path == "" {
+		return nil, false
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, false
+	}
+	var cache codesCache
+	if err := json.Unmarshal(data, &cache); err != nil || time.Since(cache.FetchedAt) > codesCacheTTL {
+		return nil, false
+	}
+	return &cache, true
+}
+
+// writeCodesCache сохраняет кэш. Ошибки игнорируются: без кэша автодополнение
+// просто обращается к серверу каждый раз.
+func writeCodesCache(path string, cache *codesCache) {
+	if path == "" {
+		return
+	}
+	data, err := json.Marshal(cache)
+	if err != nil {
+		return
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return
+	}
+	os.WriteFile(path, data, 0o600)
+}
+
+// forgetCodes сбрасывает кэш после создания или удаления ссылки, чтобы
+// подсказки не расходились с сервером.
+func forgetCodes() {
+	if path := codesCachePath(); path != "" {
+		os.Remove(path)
+	}
+}
@@ -0,0 +1,357 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+
+	"tinyurl/internal/i18n"
+)
+
+// Переменные окружения перекрывают значения из профиля, флаги - и то и другое.
+const (
+	envConfig  = "TINYURL_CONFIG"
+	envProfile = "TINYURL_PROFILE"
+	envServer  = "TINYURL_SERVER"
+	envToken   = "TINYURL_TOKEN"
+	envTTL     = "TINYURL_TTL"
+	envOutput  = "TINYURL_OUTPUT"
+
+	defaultProfile = "default"
+)
+
+// configKeys - ключи профиля для config get и config set.
+var configKeys = []string{"server", "token", "ttl", "output"}
+
+// cliConfig - файл настроек CLI с именованными профилями.
+type cliConfig struct {
+	CurrentProfile string              `yaml:"current_profile,omitempty"`
+	Profiles       map[string]*profile `yaml:"profiles,omitempty"`
+}
+
+type profile struct {
+	Server string `yaml:"server,omitempty" json:"server,omitempty"`
+	Token  string `yaml:"token,omitempty" json:"token,omitempty"`
+	TTL    int    `yaml:"ttl,omitempty" json:"ttl,omitempty"`
+	Output string `yaml:"output,omitempty" json:"output,omitempty"`
+}
+
+var (
+	profileName string
+	config      *cliConfig
+	authToken   string
+)
+
+// configPath возвращает путь к файлу настроек: $TINYURL_CONFIG или
+// tinyurl/config.yaml в каталоге настроек пользователя ($XDG_CONFIG_HOME на Linux).
+func configPath() (string, error) {
+	if path := os.Getenv(envConfig); path != "" {
+		return path, nil
+	}
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "tinyurl", "config.yaml"), nil
+}
+
+// loadConfig читает файл настроек. Отсутствующий файл - это пустые настройки.
+func loadConfig() (*cliConfig, error) {
+	cfg := &cliConfig{}
+	path, err := configPath()
+	if err != nil {
+		return cfg, nil
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return cfg, nil
+	}
+	if err == nil {
+		err = yaml.Unmarshal(data, cfg)
+	}
+	if err != nil {
+		return nil, errors.New(i18n.T(lang, i18n.CLIConfigRead, path, err))
+	}
+	return cfg, nil
+}
+
+// saveConfig записывает настройки. Файл доступен только владельцу: в нем хранится токен.
+func saveConfig(cfg *cliConfig) error {
+	path, err := configPath()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return err
+	}
+	var data bytes.Buffer
+	enc := yaml.NewEncoder(&data)
+	enc.SetIndent(2)
+	if err := enc.Encode(cfg); err != nil {
+		return err
+	}
+	enc.Close()
+	return os.WriteFile(path, data.Bytes(), 0o600)
+}
+
+// applyConfig выбирает профиль и подставляет его значения и переменные окружения
+// вместо флагов, которые не заданы явно.
+func applyConfig(cmd *cobra.Command) error {
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+	config = cfg
+
+	explicit := true
+	if !cmd.Flags().Changed("profile") {
+		profileName = os.Getenv(envProfile)
+	}
+	if profileName == "" {
+		profileName, explicit = cfg.CurrentProfile, false
+	}
+	if profileName == "" {
+		profileName = defaultProfile
+	}
+	p := cfg.Profiles[profileName]
+	if p == nil {
+		// Опечатка в имени профиля не должна молча отправлять запросы на localhost.
+		if explicit && !isConfigCommand(cmd) {
+			return usageError(i18n.T(lang, i18n.CLIProfileNotFound, profileName))
+		}
+		p = &profile{}
+	}
+
+	flags := cmd.Flags()
+	if !flags.Changed("server") {
+		if value := firstSet(os.Getenv(envServer), p.Server); value != "" {
+			serverURL = value
+		}
+	}
+	if !flags.Changed("token") {
+		authToken = firstSet(os.Getenv(envToken), p.Token)
+	}
+	if !flags.Changed("output") {
+		if value := firstSet(os.Getenv(envOutput), p.Output); value != "" {
+			outputFormat = value
+		}
+	}
+	if cmd.Name() == "short" && !flags.Changed("ttl") {
+		ttlDays = p.TTL
+		if value := os.Getenv(envTTL); value != "" {
+			days, err := parseTTL(value)
+			if err != nil {
+				return err
+			}
+			ttlDays = days
+		}
+	}
+	return nil
+}
+
+func isConfigCommand(cmd *cobra.Command) bool {
+	return cmd.HasParent() && cmd.Parent().Name() == "config"
+}
+
+func firstSet(values ...string) string {
+	for _, value := range values {
+		if value != "" {
+			return value
+		}
+	}
+	return ""
+}
+
+func parseTTL(value string) (int, error) {
+	days, err := strconv.Atoi(value)
+	if err != nil || days < 0 {
+		return 0, usageError(i18n.T(lang, i18n.CLIConfigTTL, value))
+	}
+	return days, nil
+}
+
+func (p *profile) get(key string) (string, error) {
+	switch key {
+	case "server":
+		return p.Server, nil
+	case "token":
+		return p.Token, nil
+	case "ttl":
+		if p.TTL == 0 {
+			return "", nil
+		}
+		return strconv.Itoa(p.TTL), nil
+	case "output":
+		return p.Output, nil
+	}
+	return "", usageError(i18n.T(lang, i18n.CLIConfigKey, key))
+}
+
+// set меняет значение ключа. Пустое значение удаляет ключ из профиля.
+func (p *profile) set(key, value string) error {
+	switch key {
+	case "server":
+		if value != "" {
+			u, err := url.Parse(value)
+			if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+				return usageError(i18n.T(lang, i18n.CLIConfigServer, value))
+			}
+		}
+		p.Server = value
+	case "token":
+		p.Token = value
+	case "ttl":
+		days := 0
+		if value != "" {
+			var err error
+			if days, err = parseTTL(value); err != nil {
+				return err
+			}
+		}
+		p.TTL = days
+	case "output":
+		if value != "" {
+			if _, err := compileOutput(value); err != nil {
+				return err
+			}
+		}
+		p.Output = value
+	default:
+		return usageError(i18n.T(lang, i18n.CLIConfigKey, key))
+	}
+	return nil
+}
+
+func configSet(cmd *cobra.Command, args []string) error {
+	p := config.Profiles[profileName]
+	if p == nil {
+		p = &profile{}
+	}
+	if err := p.set(args[0], args[1]); err != nil {
+		return err
+	}
+
+	if config.Profiles == nil {
+		config.Profiles = map[string]*profile{}
+	}
+	config.Profiles[profileName] = p
+	if err := saveConfig(config); err != nil {
+		return err
+	}
+	fmt.Fprintln(os.Stderr, i18n.T(lang, i18n.CLIConfigSaved, profileName, args[0]))
+	return nil
+}
+
+// configGet выводит значение ключа профиля, а без аргумента - весь профиль со
+// скрытым токеном.
+func configGet(cmd *cobra.Command, args []string) error {
+	p := config.Profiles[profileName]
+	if p == nil {
+		p = &profile{}
+	}
+	if len(args) == 1 {
+		value, err := p.get(args[0])
+		if err != nil {
+			return err
+		}
+		if value != "" {
+			fmt.Println(value)
+		}
+		return nil
+	}
+
+	shown := *p
+	if shown.Token != "" {
+		shown.Token = "********"
+	}
+	return render(shown, profileName, func(w io.Writer) {
+		fmt.Fprintln(w, i18n.T(lang, i18n.CLIProfile), profileName)
+		for _, key := range configKeys {
+			value, _ := shown.get(key)
+			fmt.Fprintf(w, "%s: %s\n", key, value)
+		}
+	})
+}
+
+func useProfile(cmd *cobra.Command, args []string) error {
+	name := args[0]
+	if config.Profiles[name] == nil && name != defaultProfile {
+		return withExitCode(exitNotFound, errors.New(i18n.T(lang, i18n.CLIProfileNotFound, name)))
+	}
+
+	config.CurrentProfile = name
+	if err := saveConfig(config); err != nil {
+		return err
+	}
+	fmt.Println(i18n.T(lang, i18n.CLIProfileSwitched), name)
+	return nil
+}
+
+func completeKeys(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) > 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	return configKeys, cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeProfiles подсказывает имена профилей из файла настроек.
+func completeProfiles(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	cfg, err := loadConfig()
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveError
+	}
+	names := make([]string, 0, len(cfg.Profiles))
+	for name := range cfg.Profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names, cobra.ShellCompDirectiveNoFileComp
+}
+
+func newConfigCmd() *cobra.Command {
+	configCmd := &cobra.Command{
+		Use:   "config",
+		Short: i18n.T(lang, i18n.CLIConfigShort),
+	}
+	setCmd := &cobra.Command{
+		Use:   "set [key] [value]",
+		Short: i18n.T(lang, i18n.CLIConfigSetShort),
+		Args:  cobra.ExactArgs(2),
+		RunE:  configSet,
+
+		ValidArgsFunction: completeKeys,
+	}
+	getCmd := &cobra.Command{
+		Use:   "get [key]",
+		Short: i18n.T(lang, i18n.CLIConfigGetShort),
+		Args:  cobra.MaximumNArgs(1),
+		RunE:  configGet,
+
+		ValidArgsFunction: completeKeys,
+	}
+	useCmd := &cobra.Command{
+		Use:   "use-profile [name]",
+		Short: i18n.T(lang, i18n.CLIUseProfileShort),
+		Args:  cobra.ExactArgs(1),
+		RunE:  useProfile,
+
+		ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+			if len(args) > 0 {
+				return nil, cobra.ShellCompDirectiveNoFileComp
+			}
+			return completeProfiles(cmd, args, toComplete)
+		},
+	}
+	configCmd.AddCommand(setCmd, getCmd, useCmd)
+	return configCmd
+}
@@ -0,0 +1,106 @@
+package main
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"tinyurl/internal/i18n"
+	"tinyurl/pkg/client"
+)
+
+// Коды выхода CLI. Скрипты могут отличать ошибки запроса от недоступности сервера
+// и отсутствующих ссылок.
+const (
+	exitOK          = 0
+	exitError       = 1 // прочие ошибки, а также неудачная проверка probe
+	exitUsage       = 2 // неверные аргументы, флаги или формат вывода
+	exitNotFound    = 3 // ссылка, домен или шаблон не найдены (404)
+	exitConflict    = 4 // алиас или имя уже заняты (409)
+	exitInvalid     = 5 // сервер отклонил запрос (400, 422 и прочие 4xx)
+	exitServer      = 6 // внутренняя ошибка сервера (5xx)
+	exitUnavailable = 7 // сервер недоступен
+)
+
+// cliError - ошибка с кодом выхода.
+type cliError struct {
+	code int
+	err  error
+}
+
+func (e *cliError) Error() string { return e.err.Error() }
+func (e *cliError) Unwrap() error { return e.err }
+
+func withExitCode(code int, err error) error {
+	return &cliError{code: code, err: err}
+}
+
+func usageError(msg string) error {
+	return withExitCode(exitUsage, errors.New(msg))
+}
+
+func requestError(err error) error {
+	return withExitCode(exitUnavailable, errors.New(i18n.T(lang, i18n.CLIRequestFailed, err)))
+}
+
+// exitCode возвращает код выхода для ошибки. Ошибки без кода, возникшие до запуска
+// команды (разбор флагов и аргументов cobra), считаются ошибками использования.
+func exitCode(err error, started bool) int {
+	var e *cliError
+	switch {
+	case err == nil:
+		return exitOK
+	case errors.As(err, &e):
+		return e.code
+	case !started:
+		return exitUsage
+	default:
+		return exitError
+	}
+}
+
+func statusExitCode(status int) int {
+	switch {
+	case status == http.StatusNotFound:
+		return exitNotFound
+	case status == http.StatusConflict:
+		return exitConflict
+	case status >= 500:
+		return exitServer
+	case status >= 400:
+		return exitInvalid
+	default:
+		return exitError
+	}
+}
+
+// apiError превращает ошибку клиента API в понятное сообщение с кодом выхода.
+// Ответы application/problem+json выводятся с кодом ошибки, полем и
+// идентификатором запроса, ошибки без ответа сервера - как недоступность сервера.
+func apiError(err error) error {
+	var e *client.Error
+	if !errors.As(err, &e) {
+		return requestError(err)
+	}
+	code := statusExitCode(e.StatusCode)
+	if e.Problem.Code != "" {
+		return withExitCode(code, formatProblem(&e.Problem))
+	}
+	return withExitCode(code, errors.New(i18n.T(lang, i18n.CLIServerError, e.StatusCode, e.Body)))
+}
+
+func formatProblem(p *client.Problem) error {
+	var details []string
+	if p.Field != "" {
+		details = append(details, i18n.T(lang, i18n.CLIProblemField, p.Field))
+	}
+	if p.RequestID != "" {
+		details = append(details, i18n.T(lang, i18n.CLIProblemRequest, p.RequestID))
+	}
+
+	msg := i18n.T(lang, i18n.CLIProblem, p.Code, p.Message)
+	if len(details) > 0 {
+		msg += " (" + strings.Join(details, ", ") + ")"
+	}
+	return errors.New(msg)
+}
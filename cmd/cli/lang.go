@@ -0,0 +1,28 @@
+package main
+
+import (
+	"strings"
+
+	"tinyurl/internal/i18n"
+)
+
+// detectLang берет язык из флага --lang, а без него - из окружения (LC_ALL,
+// LC_MESSAGES, LANG). Некорректное значение флага проверяется позже, при разборе флагов.
+func detectLang(args []string) i18n.Lang {
+	for i, arg := range args {
+		if arg == "--" {
+			break
+		}
+		value, ok := strings.CutPrefix(arg, "--lang=")
+		if !ok && arg == "--lang" && i+1 < len(args) {
+			value, ok = args[i+1], true
+		}
+		if ok {
+			if lang, valid := i18n.Parse(value); valid {
+				return lang
+			}
+			break
+		}
+	}
+	return i18n.FromEnv(i18n.Default)
+}
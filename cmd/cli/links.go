@@ -0,0 +1,159 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"tinyurl/internal/i18n"
+	"tinyurl/internal/version"
+	"tinyurl/pkg/client"
+)
+
+// connect создает клиент API с адресом, токеном и языком из флагов и профиля.
+func connect() *client.Client {
+	c := client.New(serverURL)
+	c.Token = authToken
+	c.Language = string(lang)
+	c.UserAgent = "tinyurl-cli/" + version.Version
+	return c
+}
+
+// listLinks выводит одну страницу ссылок, а с --all - все страницы подряд.
+func listLinks(cmd *cobra.Command, args []string) error {
+	opts := client.ListOptions{Domain: domainName, Search: listSearch, PageSize: listPageSize, PageToken: listPageToken}
+	list := &client.LinkList{}
+	if listAll {
+		links, err := api.AllLinks(cmd.Context(), opts)
+		if err != nil {
+			return apiError(err)
+		}
+		list.Links = links
+	} else {
+		page, err := api.ListLinks(cmd.Context(), opts)
+		if err != nil {
+			return apiError(err)
+		}
+		list = page
+	}
+	if list.Links == nil {
+		list.Links = []client.LinkSummary{}
+	}
+
+	codes := make([]string, len(list.Links))
+	for i, link := range list.Links {
+		codes[i] = link.Code
+	}
+	return render(list, strings.Join(codes, "\n"), func(w io.Writer) { printLinks(w, list) })
+}
+
+func printLinks(w io.Writer, list *client.LinkList) {
+	if len(list.Links) == 0 {
+		fmt.Fprintln(w, i18n.T(lang, i18n.CLINoLinks))
+	} else {
+		out := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(out, i18n.T(lang, i18n.CLIListHeader))
+		for _, link := range list.Links {
+			expires := i18n.T(lang, i18n.CLINever)
+			if link.ExpiresAt != nil {
+				expires = link.ExpiresAt.Local().Format(time.DateTime)
+			}
+			fmt.Fprintf(out, "%s\t%d\t%s\t%s\n", link.Code, link.HitCount, expires, link.URL)
+		}
+		out.Flush()
+	}
+	if list.NextPageToken != "" {
+		fmt.Fprintln(w, i18n.T(lang, i18n.CLINextPage, list.NextPageToken))
+	}
+}
+
+func deleteLink(cmd *cobra.Command, args []string) error {
+	if err := api.DeleteLink(cmd.Context(), domainName, args[0]); err != nil {
+		return apiError(err)
+	}
+	forgetCodes()
+
+	result := struct {
+		Code    string `json:"code"`
+		Deleted bool   `json:"deleted"`
+	}{args[0], true}
+	return render(result, "", func(w io.Writer) {
+		fmt.Fprintln(w, i18n.T(lang, i18n.CLIDeleted), args[0])
+	})
+}
+
+func updateLink(cmd *cobra.Command, args []string) error {
+	var update client.LinkUpdate
+	if cmd.Flags().Changed("url") {
+		update.URL = &updateURL
+	}
+	if cmd.Flags().Changed("ttl") {
+		update.TTLDays = &updateTTL
+	}
+	if update.URL == nil && update.TTLDays == nil {
+		return usageError(i18n.T(lang, i18n.CLINothingToUpdate))
+	}
+
+	stats, err := api.UpdateLink(cmd.Context(), domainName, args[0], update)
+	if err != nil {
+		return apiError(err)
+	}
+	return render(stats, "", func(w io.Writer) { printStats(w, stats) })
+}
+
+func resolveLink(cmd *cobra.Command, args []string) error {
+	result, err := api.ResolveLink(cmd.Context(), domainName, args[0])
+	if err != nil {
+		return apiError(err)
+	}
+
+	return render(result, result.URL, func(w io.Writer) {
+		fmt.Fprintln(w, i18n.T(lang, i18n.CLIShortURL), result.ShortURL)
+		fmt.Fprintln(w, i18n.T(lang, i18n.CLIDestination), result.URL)
+		fmt.Fprintln(w, i18n.T(lang, i18n.CLIRedirectType), result.RedirectType)
+	})
+}
+
+// openLink открывает короткую ссылку, чтобы переход прошел через правила и попал
+// в статистику. С --target открывается основной URL ссылки.
+func openLink(cmd *cobra.Command, args []string) error {
+	result, err := api.ResolveLink(cmd.Context(), domainName, args[0])
+	if err != nil {
+		return apiError(err)
+	}
+
+	target := result.ShortURL
+	if openTarget {
+		target = result.URL
+	}
+	if err := openBrowser(target); err != nil {
+		return errors.New(i18n.T(lang, i18n.CLIBrowserFailed, err))
+	}
+	return render(result, target, func(w io.Writer) {
+		fmt.Fprintln(w, i18n.T(lang, i18n.CLIOpening), target)
+	})
+}
+
+// openBrowser запускает браузер из $BROWSER или системный обработчик ссылок.
+func openBrowser(target string) error {
+	var cmd *exec.Cmd
+	switch browser := os.Getenv("BROWSER"); {
+	case browser != "":
+		cmd = exec.Command(browser, target)
+	case runtime.GOOS == "darwin":
+		cmd = exec.Command("open", target)
+	case runtime.GOOS == "windows":
+		cmd = exec.Command("rundll32", "url.dll,FileProtocolHandler", target)
+	default:
+		cmd = exec.Command("xdg-open", target)
+	}
+	return cmd.Start()
+}
@@ -1,115 +1,280 @@
 package main
 
 import (
-	"bytes"
-	"encoding/json"
+	"context"
+	"errors"
 	"fmt"
 	"io"
-	"net/http"
 	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"time"
 
 	"github.com/spf13/cobra"
+
+	"tinyurl/internal/i18n"
+	"tinyurl/pkg/client"
 )
 
 var (
 	serverURL string
 	alias     string
 	ttlDays   int
+
+	qrFile   string
+	qrSize   int
+	qrLevel  string
+	qrFg     string
+	qrBg     string
+	qrMargin int
+
+	probeEndpoint string
+	probeTimeout  time.Duration
+
+	domainName    string
+	listSearch    string
+	listPageSize  int
+	listPageToken string
+	listAll       bool
+	updateURL     string
+	updateTTL     int
+	openTarget    bool
+
+	langName string
+	lang     i18n.Lang
+	api      *client.Client
 )
 
 func main() {
+	// Язык нужен еще до разбора флагов: на нем написаны описания команд в справке.
+	lang = detectLang(os.Args[1:])
+
+	// started отделяет ошибки разбора флагов и аргументов от ошибок самой команды.
+	started := false
 	rootCmd := &cobra.Command{
 		Use:   "tinyurl",
-		Short: "TinyURL CLI - сокращайте ссылки из командной строки",
+		Short: i18n.T(lang, i18n.CLIRootShort),
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+			started = true
+			// Справка нужна при ошибке в аргументах, но не при ошибке запроса.
+			cmd.SilenceUsage = true
+			if _, ok := i18n.Parse(langName); langName != "" && !ok {
+				return usageError(i18n.T(lang, i18n.CLIInvalidLang, langName))
+			}
+			if err := applyConfig(cmd); err != nil {
+				return err
+			}
+			api = connect()
+			// Раньше qr принимал файл в -o; такое значение по-прежнему работает.
+			if cmd.Name() == "qr" && qrFile == "" && isImageFile(outputFormat) {
+				fmt.Fprintln(os.Stderr, i18n.T(lang, i18n.CLIQRLegacyFile))
+				qrFile, outputFormat = outputFormat, outputTable
+			}
+			return parseOutput(outputFormat)
+		},
+		SilenceErrors: true,
 	}
 
-	rootCmd.PersistentFlags().StringVarP(&serverURL, "server", "s", "http://localhost:8080", "Адрес сервера TinyURL")
+	rootCmd.PersistentFlags().StringVarP(&serverURL, "server", "s", "http://localhost:8080", i18n.T(lang, i18n.CLIServerFlag))
+	rootCmd.PersistentFlags().StringVar(&langName, "lang", "", i18n.T(lang, i18n.CLILangFlag))
+	rootCmd.PersistentFlags().StringVarP(&outputFormat, "output", "o", outputTable, i18n.T(lang, i18n.CLIOutputFlag))
+	rootCmd.PersistentFlags().StringVarP(&profileName, "profile", "p", "", i18n.T(lang, i18n.CLIProfileFlag))
+	rootCmd.PersistentFlags().StringVar(&authToken, "token", "", i18n.T(lang, i18n.CLITokenFlag))
+	rootCmd.PersistentFlags().BoolVarP(&quiet, "quiet", "q", false, i18n.T(lang, i18n.CLIQuietFlag))
+	rootCmd.RegisterFlagCompletionFunc("output", cobra.FixedCompletions(
+		[]string{outputTable, outputJSON, outputYAML, templatePrefix}, cobra.ShellCompDirectiveNoFileComp|cobra.ShellCompDirectiveNoSpace))
+	rootCmd.RegisterFlagCompletionFunc("profile", completeProfiles)
+	// Встроенная команда completion заменена своей: с переведенной справкой.
+	rootCmd.CompletionOptions.DisableDefaultCmd = true
 
 	shortCmd := &cobra.Command{
 		Use:   "short [url]",
-		Short: "Сократить URL",
+		Short: i18n.T(lang, i18n.CLIShortShort),
 		Args:  cobra.ExactArgs(1),
 		RunE:  shortURL,
 	}
-	shortCmd.Flags().StringVarP(&alias, "alias", "a", "", "Пользовательский алиас для ссылки")
-	shortCmd.Flags().IntVarP(&ttlDays, "ttl", "t", 0, "Срок жизни ссылки в днях (0 = бессрочно)")
+	shortCmd.Flags().StringVarP(&alias, "alias", "a", "", i18n.T(lang, i18n.CLIAliasFlag))
+	shortCmd.Flags().IntVarP(&ttlDays, "ttl", "t", 0, i18n.T(lang, i18n.CLITTLFlag))
 
 	statsCmd := &cobra.Command{
 		Use:   "stats [code]",
-		Short: "Получить статистику по коду",
+		Short: i18n.T(lang, i18n.CLIStatsShort),
 		Args:  cobra.ExactArgs(1),
 		RunE:  getStats,
 	}
 
-	rootCmd.AddCommand(shortCmd, statsCmd)
+	qrCmd := &cobra.Command{
+		Use:   "qr [code]",
+		Short: i18n.T(lang, i18n.CLIQRShort),
+		Args:  cobra.ExactArgs(1),
+		RunE:  getQR,
+	}
+	qrCmd.Flags().StringVarP(&qrFile, "file", "f", "", i18n.T(lang, i18n.CLIQROutputFlag))
+	qrCmd.Flags().IntVar(&qrSize, "size", 256, i18n.T(lang, i18n.CLIQRSizeFlag))
+	qrCmd.Flags().StringVar(&qrLevel, "level", "M", i18n.T(lang, i18n.CLIQRLevelFlag))
+	qrCmd.Flags().StringVar(&qrFg, "fg", "000000", i18n.T(lang, i18n.CLIQRFgFlag))
+	qrCmd.Flags().StringVar(&qrBg, "bg", "ffffff", i18n.T(lang, i18n.CLIQRBgFlag))
+	qrCmd.Flags().IntVar(&qrMargin, "margin", 4, i18n.T(lang, i18n.CLIQRMarginFlag))
+
+	probeCmd := &cobra.Command{
+		Use:   "probe",
+		Short: i18n.T(lang, i18n.CLIProbeShort),
+		Args:  cobra.NoArgs,
+		RunE:  probe,
+		// Вывод справки при каждой неудачной проверке только засоряет логи HEALTHCHECK.
+		SilenceUsage: true,
+	}
+	probeCmd.Flags().StringVar(&probeEndpoint, "endpoint", "readyz", i18n.T(lang, i18n.CLIEndpointFlag))
+	probeCmd.Flags().DurationVar(&probeTimeout, "timeout", 3*time.Second, i18n.T(lang, i18n.CLITimeoutFlag))
+
+	listCmd := &cobra.Command{
+		Use:   "list",
+		Short: i18n.T(lang, i18n.CLIListShort),
+		Args:  cobra.NoArgs,
+		RunE:  listLinks,
+	}
+	listCmd.Flags().StringVar(&listSearch, "search", "", i18n.T(lang, i18n.CLISearchFlag))
+	listCmd.Flags().IntVarP(&listPageSize, "limit", "n", 0, i18n.T(lang, i18n.CLIPageSizeFlag))
+	listCmd.Flags().StringVar(&listPageToken, "page", "", i18n.T(lang, i18n.CLIPageTokenFlag))
+	listCmd.Flags().BoolVar(&listAll, "all", false, i18n.T(lang, i18n.CLIAllFlag))
+
+	deleteCmd := &cobra.Command{
+		Use:   "delete [code]",
+		Short: i18n.T(lang, i18n.CLIDeleteShort),
+		Args:  cobra.ExactArgs(1),
+		RunE:  deleteLink,
+	}
+
+	updateCmd := &cobra.Command{
+		Use:   "update [code]",
+		Short: i18n.T(lang, i18n.CLIUpdateShort),
+		Args:  cobra.ExactArgs(1),
+		RunE:  updateLink,
+	}
+	updateCmd.Flags().StringVarP(&updateURL, "url", "u", "", i18n.T(lang, i18n.CLIUpdateURLFlag))
+	updateCmd.Flags().IntVarP(&updateTTL, "ttl", "t", 0, i18n.T(lang, i18n.CLIUpdateTTLFlag))
+
+	resolveCmd := &cobra.Command{
+		Use:   "resolve [code]",
+		Short: i18n.T(lang, i18n.CLIResolveShort),
+		Args:  cobra.ExactArgs(1),
+		RunE:  resolveLink,
+	}
+
+	openCmd := &cobra.Command{
+		Use:   "open [code]",
+		Short: i18n.T(lang, i18n.CLIOpenShort),
+		Args:  cobra.ExactArgs(1),
+		RunE:  openLink,
+	}
+	openCmd.Flags().BoolVar(&openTarget, "target", false, i18n.T(lang, i18n.CLIOpenTargetFlag))
+
+	for _, cmd := range []*cobra.Command{shortCmd, statsCmd, qrCmd, listCmd, deleteCmd, updateCmd, resolveCmd, openCmd} {
+		cmd.Flags().StringVarP(&domainName, "domain", "d", "", i18n.T(lang, i18n.CLIDomainFlag))
+	}
+	for _, cmd := range []*cobra.Command{statsCmd, qrCmd, deleteCmd, updateCmd, resolveCmd, openCmd} {
+		cmd.ValidArgsFunction = completeCodes
+	}
+
+	rootCmd.AddCommand(shortCmd, statsCmd, qrCmd, probeCmd, listCmd, deleteCmd, updateCmd, resolveCmd, openCmd, newConfigCmd())
+	rootCmd.AddCommand(newCompletionCmd(rootCmd))
 
-	if err := rootCmd.Execute(); err != nil {
-		fmt.Println(err)
-		os.Exit(1)
+	// Ctrl+C отменяет запрос и паузу между повторами.
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	if err := rootCmd.ExecuteContext(ctx); err != nil {
+		stop()
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(exitCode(err, started))
 	}
 }
 
 func shortURL(cmd *cobra.Command, args []string) error {
-	url := args[0]
-	reqBody, err := json.Marshal(map[string]interface{}{
-		"url":      url,
-		"alias":    alias,
-		"ttl_days": ttlDays,
-	})
+	req := client.ShortenRequest{URL: args[0], Alias: alias, TTLDays: ttlDays, Domain: domainName}
+	result, err := api.CreateLink(cmd.Context(), req)
 	if err != nil {
-		return err
+		return apiError(err)
 	}
+	forgetCodes()
 
-	resp, err := http.Post(serverURL+"/shorten", "application/json", bytes.NewBuffer(reqBody))
+	return render(result, result.ShortURL, func(w io.Writer) {
+		fmt.Fprintln(w, i18n.T(lang, i18n.CLIShortURL), result.ShortURL)
+	})
+}
+
+func getStats(cmd *cobra.Command, args []string) error {
+	stats, err := api.GetLink(cmd.Context(), domainName, args[0])
 	if err != nil {
-		return fmt.Errorf("ошибка при отправке запроса: %v", err)
+		return apiError(err)
 	}
-	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(resp.Body)
-		return fmt.Errorf("сервер вернул ошибку %d: %s", resp.StatusCode, body)
-	}
+	return render(stats, "", func(w io.Writer) { printStats(w, stats) })
+}
 
-	var result map[string]string
-	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
-		return err
+func printStats(w io.Writer, stats *client.StatsResponse) {
+	fmt.Fprintln(w, "URL:", stats.URL)
+	fmt.Fprintln(w, i18n.T(lang, i18n.CLICreated), stats.CreatedAt.Format(time.RFC3339))
+	if stats.ExpiresAt != nil {
+		fmt.Fprintln(w, i18n.T(lang, i18n.CLIExpires), stats.ExpiresAt.Format(time.RFC3339))
+	} else {
+		fmt.Fprintln(w, i18n.T(lang, i18n.CLIExpires), i18n.T(lang, i18n.CLINever))
 	}
+	fmt.Fprintln(w, i18n.T(lang, i18n.CLIHits), stats.HitCount)
+}
 
-	fmt.Println("Короткая ссылка:", result["short_url"])
-	return nil
+func isImageFile(name string) bool {
+	ext := strings.ToLower(filepath.Ext(name))
+	return ext == ".png" || ext == ".svg"
 }
 
-func getStats(cmd *cobra.Command, args []string) error {
+func getQR(cmd *cobra.Command, args []string) error {
 	code := args[0]
-	resp, err := http.Get(serverURL + "/stats/" + code)
-	if err != nil {
-		return fmt.Errorf("ошибка при отправке запроса: %v", err)
+	file := qrFile
+	if file == "" {
+		file = code + ".png"
 	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(resp.Body)
-		return fmt.Errorf("сервер вернул ошибку %d: %s", resp.StatusCode, body)
+	if !isImageFile(file) {
+		return usageError(i18n.T(lang, i18n.CLIQRExtension))
 	}
+	format := strings.TrimPrefix(strings.ToLower(filepath.Ext(file)), ".")
 
-	var stats struct {
-		URL       string  `json:"url"`
-		CreatedAt string  `json:"created_at"`
-		ExpiresAt *string `json:"expires_at,omitempty"`
-		HitCount  int     `json:"hit_count"`
+	image, err := api.QRCode(cmd.Context(), domainName, code, client.QROptions{
+		Format: format, Size: qrSize, Level: qrLevel, Foreground: qrFg, Background: qrBg, Margin: &qrMargin,
+	})
+	if err != nil {
+		return apiError(err)
 	}
-	if err := json.NewDecoder(resp.Body).Decode(&stats); err != nil {
+	if err := os.WriteFile(file, image, 0o644); err != nil {
 		return err
 	}
 
-	fmt.Println("URL:", stats.URL)
-	fmt.Println("Создано:", stats.CreatedAt)
-	if stats.ExpiresAt != nil {
-		fmt.Println("Истекает:", *stats.ExpiresAt)
-	} else {
-		fmt.Println("Истекает: никогда")
+	result := struct {
+		File   string `json:"file"`
+		Format string `json:"format"`
+	}{file, format}
+	return render(result, file, func(w io.Writer) {
+		fmt.Fprintln(w, i18n.T(lang, i18n.CLIQRSaved), file)
+	})
+}
+
+func probe(cmd *cobra.Command, args []string) error {
+	if probeEndpoint != "readyz" && probeEndpoint != "healthz" {
+		return errors.New(i18n.T(lang, i18n.CLIInvalidProbe))
+	}
+
+	// HEALTHCHECK сам повторяет проверки, поэтому одна попытка с коротким таймаутом.
+	api.HTTPClient.Timeout = probeTimeout
+	api.MaxRetries = 0
+	body, err := api.Probe(cmd.Context(), probeEndpoint)
+	var e *client.Error
+	if errors.As(err, &e) {
+		return errors.New(i18n.T(lang, i18n.CLINotReady, e.StatusCode, e.Body))
 	}
-	fmt.Println("Количество переходов:", stats.HitCount)
+	if err != nil {
+		return errors.New(i18n.T(lang, i18n.CLIUnavailable, err))
+	}
+
+	fmt.Println(strings.TrimSpace(string(body)))
 	return nil
 }
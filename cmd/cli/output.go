@@ -0,0 +1,140 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/template"
+
+	"gopkg.in/yaml.v3"
+
+	"tinyurl/internal/i18n"
+)
+
+// Форматы вывода флага --output. table - вывод для человека, остальные - для
+// скриптов: поля называются так же, как в JSON API.
+const (
+	outputTable    = "table"
+	outputJSON     = "json"
+	outputYAML     = "yaml"
+	templatePrefix = "template="
+)
+
+var (
+	outputFormat   string
+	quiet          bool
+	outputTemplate *template.Template
+)
+
+// parseOutput проверяет значение --output и компилирует шаблон для template=.
+func parseOutput(value string) error {
+	tmpl, err := compileOutput(value)
+	if err != nil {
+		return err
+	}
+	outputTemplate = tmpl
+	return nil
+}
+
+// compileOutput проверяет формат вывода и возвращает шаблон, если формат - template=.
+func compileOutput(value string) (*template.Template, error) {
+	switch value {
+	case outputTable, outputJSON, outputYAML:
+		return nil, nil
+	}
+	text, ok := strings.CutPrefix(value, templatePrefix)
+	if !ok {
+		return nil, usageError(i18n.T(lang, i18n.CLIInvalidOutput, value))
+	}
+	tmpl, err := template.New("output").Option("missingkey=error").Parse(text)
+	if err != nil {
+		return nil, usageError(i18n.T(lang, i18n.CLIInvalidTemplate, err))
+	}
+	return tmpl, nil
+}
+
+// render выводит результат команды. С --quiet печатается только quietValue (или
+// ничего, если он пуст), в формате table - вызывается table, иначе v кодируется
+// в выбранный формат.
+func render(v interface{}, quietValue string, table func(w io.Writer)) error {
+	w := os.Stdout
+	if quiet {
+		if quietValue != "" {
+			fmt.Fprintln(w, quietValue)
+		}
+		return nil
+	}
+
+	switch {
+	case outputFormat == outputJSON:
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(v)
+	case outputFormat == outputYAML:
+		data, err := plain(v)
+		if err != nil {
+			return err
+		}
+		enc := yaml.NewEncoder(w)
+		enc.SetIndent(2)
+		defer enc.Close()
+		return enc.Encode(data)
+	case outputTemplate != nil:
+		data, err := plain(v)
+		if err != nil {
+			return err
+		}
+		var out strings.Builder
+		if err := outputTemplate.Execute(&out, data); err != nil {
+			return usageError(i18n.T(lang, i18n.CLIInvalidTemplate, err))
+		}
+		if !strings.HasSuffix(out.String(), "\n") {
+			out.WriteString("\n")
+		}
+		_, err = io.WriteString(w, out.String())
+		return err
+	default:
+		table(w)
+		return nil
+	}
+}
+
+// plain переводит значение в карты и срезы через JSON, чтобы YAML и шаблоны
+// использовали имена полей и omitempty из тегов json.
+func plain(v interface{}) (interface{}, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	dec := json.NewDecoder(strings.NewReader(string(data)))
+	dec.UseNumber()
+	var out interface{}
+	if err := dec.Decode(&out); err != nil {
+		return nil, err
+	}
+	return numbers(out), nil
+}
+
+// numbers заменяет json.Number на int64 или float64, чтобы целые числа выводились
+// как есть, а не как 1e+06.
+func numbers(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		for key, item := range v {
+			v[key] = numbers(item)
+		}
+	case []interface{}:
+		for i, item := range v {
+			v[i] = numbers(item)
+		}
+	case json.Number:
+		if n, err := v.Int64(); err == nil {
+			return n
+		}
+		f, _ := v.Float64()
+		return f
+	}
+	return v
+}
@@ -1,30 +1,159 @@
 package main
 
 import (
-	"fmt"
+	"context"
+	"errors"
 	"log"
+	"log/slog"
 	_ "modernc.org/sqlite"
+	"net"
 	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
 
+	"tinyurl/internal/config"
 	"tinyurl/internal/db"
+	"tinyurl/internal/geoip"
+	"tinyurl/internal/grpcserver"
 	"tinyurl/internal/handlers"
+	"tinyurl/internal/i18n"
+	"tinyurl/internal/logging"
+	"tinyurl/internal/metrics"
+	"tinyurl/internal/tracing"
+	"tinyurl/internal/version"
+	"tinyurl/internal/webhooks"
 )
 
 func main() {
-	fmt.Println("Запуск TinyURL...")
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatal("Ошибка конфигурации:", err)
+	}
+
+	logger, err := logging.New(os.Stdout, cfg.LogFormat, cfg.LogLevel)
+	if err != nil {
+		log.Fatal("Ошибка конфигурации:", err)
+	}
+	slog.SetDefault(logger)
+
+	build := version.Get()
+	logger.Info("Запуск TinyURL...", "version", build.Version, "commit", build.Commit)
 
-	database, err := db.InitDB("file:tinyurl.db?cache=shared&mode=rwc&_fk=1")
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	shutdownTracing, err := tracing.Setup(ctx, cfg.TracingExporter, cfg.OTLPEndpoint, os.Stdout)
+	if err != nil {
+		fatal("Ошибка при настройке трассировки", err)
+	}
+	defer func() {
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := shutdownTracing(shutdownCtx); err != nil {
+			logger.Error("Ошибка при остановке трассировки", "error", err)
+		}
+	}()
+
+	database, err := db.InitDB(cfg.DBPath)
 	if err != nil {
-		log.Fatal("Ошибка при инициализации базы данных:", err)
+		fatal("Ошибка при инициализации базы данных", err)
 	}
 	defer database.Close()
 
 	server := handlers.NewServer(database)
+	server.DefaultRedirectType = cfg.DefaultRedirectType
+	server.BaseURL = cfg.BaseURL
+	server.TrustedProxies = cfg.TrustedProxies
+	server.IdempotencyTTL = cfg.IdempotencyTTL
+
+	if cfg.GeoIPPath != "" {
+		resolver, err := geoip.Open(cfg.GeoIPPath)
+		if err != nil {
+			fatal("Ошибка при загрузке базы GeoIP", err)
+		}
+		defer resolver.Close()
+
+		go resolver.Watch(ctx, cfg.GeoIPReloadInterval)
 
-	http.HandleFunc("/shorten", server.ShortenHandler)
-	http.HandleFunc("/r/", server.RedirectHandler)
-	http.HandleFunc("/stats/", server.StatsHandler)
+		server.GeoIP = resolver
+		logger.Info("База GeoIP загружена", "path", cfg.GeoIPPath)
+	}
+
+	dispatcher := webhooks.NewDispatcher(database)
+	dispatcher.Retention = cfg.WebhookRetention
+	dispatcher.AllowPrivate = cfg.WebhookAllowPrivate
+	server.Events = dispatcher
+	dispatcherDone := make(chan struct{})
+	go func() {
+		defer close(dispatcherDone)
+		dispatcher.Run(ctx)
+	}()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/shorten", server.Idempotent(server.ShortenHandler))
+	mux.HandleFunc("/r/", server.RedirectHandler)
+	mux.HandleFunc("/stats/", server.StatsHandler)
+	mux.HandleFunc("/qr/", server.QRHandler)
+	mux.HandleFunc("/domains", server.Idempotent(server.DomainsHandler))
+	mux.HandleFunc("/domains/", server.DomainHandler)
+	mux.HandleFunc("/utm-templates", server.Idempotent(server.UTMTemplatesHandler))
+	mux.HandleFunc("/utm-templates/", server.UTMTemplateHandler)
+	mux.Handle("/metrics", metrics.Handler())
+	mux.HandleFunc("/healthz", server.HealthHandler)
+	mux.HandleFunc("/readyz", server.ReadyHandler)
+	mux.HandleFunc("/version", server.VersionHandler)
+	server.RegisterAPI(mux)
+	mux.HandleFunc("/", server.NotFoundHandler)
+
+	handler := tracing.Middleware(mux, logging.Middleware(logger, mux, i18n.Middleware(cfg.Lang, metrics.Middleware(mux))))
+	httpServer := &http.Server{Addr: ":" + cfg.Port, Handler: handler}
+
+	grpcServer := grpcserver.New(server, logger, cfg.Lang)
+	grpcListener, err := net.Listen("tcp", ":"+cfg.GRPCPort)
+	if err != nil {
+		fatal("Ошибка при запуске gRPC-сервера", err)
+	}
+	go func() {
+		logger.Info("gRPC-сервер запущен", "addr", grpcListener.Addr().String())
+		if err := grpcServer.Serve(grpcListener); err != nil {
+			fatal("Ошибка gRPC-сервера", err)
+		}
+	}()
+
+	// shutdownDone закрывается, когда HTTP- и gRPC-серверы завершили запросы.
+	shutdownDone := make(chan struct{})
+	go func() {
+		defer close(shutdownDone)
+		<-ctx.Done()
+		logger.Info("Остановка сервера", "drain", cfg.ShutdownDelay.String())
+		server.Drain(cfg.ShutdownDelay)
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		go func() {
+			<-shutdownCtx.Done()
+			grpcServer.Stop()
+		}()
+		httpServer.Shutdown(shutdownCtx)
+		grpcServer.GracefulStop()
+	}()
+
+	logger.Info("Сервер запущен", "addr", "http://localhost:"+cfg.Port)
+	if err := httpServer.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		fatal("Ошибка HTTP-сервера", err)
+	}
+
+	// ListenAndServe возвращается сразу после начала Shutdown. База и трассировка
+	// закрываются отложенными вызовами, поэтому сначала дожидаемся запросов,
+	// фоновой записи переходов и доставки вебхуков.
+	<-shutdownDone
+	server.WaitHits()
+	<-dispatcherDone
+	logger.Info("Сервер остановлен")
+}
 
-	fmt.Println("Сервер запущен на http://localhost:8080")
-	log.Fatal(http.ListenAndServe(":8080", nil))
+func fatal(msg string, err error) {
+	slog.Error(msg, "error", err)
+	os.Exit(1)
 }
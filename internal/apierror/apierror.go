@@ -0,0 +1,138 @@
+package apierror
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"tinyurl/internal/i18n"
+	"tinyurl/internal/logging"
+)
+
+// ContentType - тип ответа с ошибкой по RFC 7807.
+const ContentType = "application/problem+json"
+
+// Code - стабильный машиночитаемый код ошибки. Коды не меняются между версиями,
+// в отличие от текста сообщений.
+type Code string
+
+const (
+	Internal             Code = "internal_error"
+	NotFound             Code = "not_found"
+	MethodNotAllowed     Code = "method_not_allowed"
+	InvalidJSON          Code = "invalid_json"
+	URLRequired          Code = "url_required"
+	InvalidURL           Code = "invalid_url"
+	InvalidAlias         Code = "invalid_alias"
+	InvalidRedirectType  Code = "invalid_redirect_type"
+	InvalidRules         Code = "invalid_rules"
+	InvalidVariants      Code = "invalid_variants"
+	InvalidGeo           Code = "invalid_geo"
+	InvalidUTMApply      Code = "invalid_utm_apply"
+	InvalidUTMTemplate   Code = "invalid_utm_template"
+	InvalidPassthrough   Code = "invalid_passthrough"
+	InvalidTTL           Code = "invalid_ttl"
+	InvalidPageToken     Code = "invalid_page_token"
+	InvalidPageSize      Code = "invalid_page_size"
+	InvalidWebhook       Code = "invalid_webhook"
+	InvalidQROptions     Code = "invalid_qr_options"
+	InvalidHost          Code = "invalid_host"
+	InvalidCodeLength    Code = "invalid_code_length"
+	InvalidNotFoundURL   Code = "invalid_not_found_url"
+	StickyWithoutVariant Code = "sticky_without_variants"
+	LinkNotFound         Code = "link_not_found"
+	DomainNotFound       Code = "domain_not_found"
+	UTMTemplateNotFound  Code = "utm_template_not_found"
+	WebhookNotFound      Code = "webhook_not_found"
+	DeliveryNotFound     Code = "delivery_not_found"
+	AliasTaken           Code = "alias_taken"
+	DomainExists         Code = "domain_exists"
+	DomainInUse          Code = "domain_in_use"
+	UTMTemplateExists    Code = "utm_template_exists"
+	UTMTemplateInUse     Code = "utm_template_in_use"
+	CodeGenerationFailed Code = "code_generation_failed"
+	BaseURLRequired      Code = "base_url_required"
+
+	// Ошибки заголовка Idempotency-Key.
+	InvalidIdempotencyKey Code = "invalid_idempotency_key"
+	IdempotencyKeyReused  Code = "idempotency_key_reused"
+	IdempotencyInProgress Code = "idempotency_key_in_progress"
+)
+
+// Problem - тело ответа с ошибкой: поля RFC 7807 и расширения code, message,
+// field и request_id.
+type Problem struct {
+	Type      string `json:"type"`
+	Title     string `json:"title"`
+	Status    int    `json:"status"`
+	Code      Code   `json:"code"`
+	Message   string `json:"message"`
+	Field     string `json:"field,omitempty"`
+	RequestID string `json:"request_id,omitempty"`
+	Instance  string `json:"instance,omitempty"`
+}
+
+func (p *Problem) Error() string {
+	if p.Field != "" {
+		return fmt.Sprintf("%s: %s (%s)", p.Code, p.Message, p.Field)
+	}
+	return fmt.Sprintf("%s: %s", p.Code, p.Message)
+}
+
+// Error - ошибка обработчика, которая отдается клиенту как Problem. Сообщение
+// хранится ключом каталога и переводится на язык запроса при записи ответа.
+type Error struct {
+	Status int
+	Code   Code
+	Field  string
+	Key    i18n.Key
+	Args   []any
+}
+
+func (e *Error) Error() string {
+	return i18n.T(i18n.Default, e.Key, e.Args...)
+}
+
+func New(status int, code Code, key i18n.Key, args ...any) *Error {
+	return &Error{Status: status, Code: code, Key: key, Args: args}
+}
+
+// Invalid создает ошибку 400 для конкретного поля запроса.
+func Invalid(code Code, field string, key i18n.Key, args ...any) *Error {
+	return &Error{Status: http.StatusBadRequest, Code: code, Field: field, Key: key, Args: args}
+}
+
+// TypeURI возвращает идентификатор типа проблемы для кода.
+func TypeURI(code Code) string {
+	return "urn:tinyurl:error:" + string(code)
+}
+
+// Write отдает err как application/problem+json на языке запроса. Ошибки, не
+// являющиеся *Error, отдаются как internal_error без раскрытия подробностей.
+func Write(w http.ResponseWriter, r *http.Request, err error) {
+	var e *Error
+	if !errors.As(err, &e) {
+		e = New(http.StatusInternalServerError, Internal, i18n.ErrInternal)
+	}
+	lang := i18n.FromContext(r.Context())
+
+	problem := Problem{
+		Type:      TypeURI(e.Code),
+		Title:     http.StatusText(e.Status),
+		Status:    e.Status,
+		Code:      e.Code,
+		Message:   i18n.T(lang, e.Key, e.Args...),
+		Field:     e.Field,
+		RequestID: logging.RequestID(r.Context()),
+		Instance:  r.URL.Path,
+	}
+
+	h := w.Header()
+	h.Del("Content-Length")
+	h.Set("Content-Type", ContentType)
+	h.Set("X-Content-Type-Options", "nosniff")
+	i18n.SetHeaders(w, lang)
+	w.WriteHeader(e.Status)
+	json.NewEncoder(w).Encode(problem)
+}
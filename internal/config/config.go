@@ -0,0 +1,150 @@
+package config
+
+import (
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"tinyurl/internal/i18n"
+	"tinyurl/internal/tracing"
+	"tinyurl/internal/utils"
+)
+
+type Config struct {
+	DBPath              string
+	Port                string
+	GRPCPort            string
+	DefaultRedirectType int
+	GeoIPPath           string
+	GeoIPReloadInterval time.Duration
+	BaseURL             string
+	TrustedProxies      utils.TrustedProxies
+	LogFormat           string
+	LogLevel            string
+	TracingExporter     string
+	OTLPEndpoint        string
+	Lang                i18n.Lang
+	IdempotencyTTL      time.Duration
+	ShutdownDelay       time.Duration
+	WebhookRetention    time.Duration
+	// WebhookAllowPrivate разрешает вебхуки на внутренние адреса.
+	WebhookAllowPrivate bool
+}
+
+func Load() (*Config, error) {
+	cfg := &Config{
+		DBPath:              getEnv("TINYURL_DB_PATH", "file:tinyurl.db?cache=shared&mode=rwc&_fk=1"),
+		Port:                getEnv("PORT", "8080"),
+		GRPCPort:            getEnv("TINYURL_GRPC_PORT", "9090"),
+		DefaultRedirectType: http.StatusFound,
+		GeoIPPath:           os.Getenv("TINYURL_GEOIP_DB"),
+		GeoIPReloadInterval: time.Minute,
+		LogFormat:           strings.ToLower(getEnv("TINYURL_LOG_FORMAT", "text")),
+		LogLevel:            strings.ToLower(getEnv("TINYURL_LOG_LEVEL", "info")),
+		TracingExporter:     strings.ToLower(getEnv("TINYURL_TRACING_EXPORTER", tracing.ExporterNone)),
+		OTLPEndpoint:        os.Getenv("TINYURL_OTLP_ENDPOINT"),
+		Lang:                i18n.Default,
+		IdempotencyTTL:      24 * time.Hour,
+		ShutdownDelay:       5 * time.Second,
+		WebhookRetention:    7 * 24 * time.Hour,
+	}
+
+	if v := os.Getenv("TINYURL_LANG"); v != "" {
+		lang, ok := i18n.Parse(v)
+		if !ok {
+			return nil, fmt.Errorf("некорректный TINYURL_LANG: %s", v)
+		}
+		cfg.Lang = lang
+	}
+
+	if !tracing.IsValidExporter(cfg.TracingExporter) {
+		return nil, fmt.Errorf("некорректный TINYURL_TRACING_EXPORTER: %s", cfg.TracingExporter)
+	}
+
+	if cfg.LogFormat != "text" && cfg.LogFormat != "json" {
+		return nil, fmt.Errorf("некорректный TINYURL_LOG_FORMAT: %s", cfg.LogFormat)
+	}
+
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(cfg.LogLevel)); err != nil {
+		return nil, fmt.Errorf("некорректный TINYURL_LOG_LEVEL: %s", cfg.LogLevel)
+	}
+
+	if v := os.Getenv("TINYURL_REDIRECT_TYPE"); v != "" {
+		code, err := strconv.Atoi(v)
+		if err != nil || !utils.IsValidRedirectType(code) {
+			return nil, fmt.Errorf("некорректный TINYURL_REDIRECT_TYPE: %s", v)
+		}
+		cfg.DefaultRedirectType = code
+	}
+
+	if v := os.Getenv("TINYURL_GEOIP_RELOAD_INTERVAL"); v != "" {
+		interval, err := time.ParseDuration(v)
+		if err != nil || interval <= 0 {
+			return nil, fmt.Errorf("некорректный TINYURL_GEOIP_RELOAD_INTERVAL: %s", v)
+		}
+		cfg.GeoIPReloadInterval = interval
+	}
+
+	if v := os.Getenv("TINYURL_BASE_URL"); v != "" {
+		u, err := url.Parse(v)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" || u.RawQuery != "" || u.Fragment != "" {
+			return nil, fmt.Errorf("некорректный TINYURL_BASE_URL: %s", v)
+		}
+		cfg.BaseURL = strings.TrimSuffix(v, "/")
+	}
+
+	if v := os.Getenv("TINYURL_TRUSTED_PROXIES"); v != "" {
+		proxies, err := utils.ParseTrustedProxies(v)
+		if err != nil {
+			return nil, fmt.Errorf("некорректный TINYURL_TRUSTED_PROXIES: %w", err)
+		}
+		cfg.TrustedProxies = proxies
+	}
+
+	if v := os.Getenv("TINYURL_IDEMPOTENCY_TTL"); v != "" {
+		ttl, err := time.ParseDuration(v)
+		if err != nil || ttl <= 0 {
+			return nil, fmt.Errorf("некорректный TINYURL_IDEMPOTENCY_TTL: %s", v)
+		}
+		cfg.IdempotencyTTL = ttl
+	}
+
+	if v := os.Getenv("TINYURL_SHUTDOWN_DELAY"); v != "" {
+		delay, err := time.ParseDuration(v)
+		if err != nil || delay < 0 {
+			return nil, fmt.Errorf("некорректный TINYURL_SHUTDOWN_DELAY: %s", v)
+		}
+		cfg.ShutdownDelay = delay
+	}
+
+	if v := os.Getenv("TINYURL_WEBHOOK_RETENTION"); v != "" {
+		retention, err := time.ParseDuration(v)
+		if err != nil || retention <= 0 {
+			return nil, fmt.Errorf("некорректный TINYURL_WEBHOOK_RETENTION: %s", v)
+		}
+		cfg.WebhookRetention = retention
+	}
+
+	if v := os.Getenv("TINYURL_WEBHOOK_ALLOW_PRIVATE"); v != "" {
+		allow, err := strconv.ParseBool(v)
+		if err != nil {
+			return nil, fmt.Errorf("некорректный TINYURL_WEBHOOK_ALLOW_PRIVATE: %s", v)
+		}
+		cfg.WebhookAllowPrivate = allow
+	}
+
+	return cfg, nil
+}
+
+func getEnv(key, fallback string) string {
+	if v := os.Getenv(key); v != "" {
+		return v
+	}
+	return fallback
+}
@@ -1,10 +1,11 @@
 package db
 
 import (
+	"context"
 	"database/sql"
 	"errors"
 	"fmt"
-	"os"
+	"strings"
 	"time"
 	"tinyurl/internal/models"
 )
@@ -24,30 +25,73 @@ func InitDB(dbPath string) (*sql.DB, error) {
 		return nil, fmt.Errorf("ошибка при настройке SQLite: %w", err)
 	}
 
-	schemaSQL, err := os.ReadFile("db/schema.sql")
-	if err != nil {
-		return nil, fmt.Errorf("ошибка при чтении файла схемы: %w", err)
-	}
-
-	_, err = db.Exec(string(schemaSQL))
-	if err != nil {
-		return nil, fmt.Errorf("ошибка при выполнении схемы: %w", err)
+	if err = Migrate(db); err != nil {
+		return nil, err
 	}
 
 	return db, nil
 }
 
 func InsertLink(db *sql.DB, code, url string, ttlDays int) error {
+	return InsertLinkWithOptions(context.Background(), db, code, url, ttlDays, models.LinkOptions{})
+}
+
+func InsertLinkWithOptions(ctx context.Context, db *sql.DB, code, url string, ttlDays int, opts models.LinkOptions) (err error) {
+	ctx, done := observe(ctx, "insert_link")
+	defer done(&err)
+
 	var expires interface{}
 	if ttlDays > 0 {
-		expires = time.Now().AddDate(0, 0, ttlDays)
+		expires = time.Now().AddDate(0, 0, ttlDays).UTC()
+	}
+
+	var redirectType interface{}
+	if opts.RedirectType != 0 {
+		redirectType = opts.RedirectType
+	}
+
+	var utmTemplate interface{}
+	if opts.UTMTemplate != "" {
+		utmTemplate = opts.UTMTemplate
+	}
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("ошибка при вставке ссылки: %w", err)
 	}
+	defer tx.Rollback()
 
-	_, err := db.Exec("INSERT INTO links (code, url, expires_at) VALUES (?, ?, ?)", code, url, expires)
+	result, err := tx.ExecContext(ctx, `
+		INSERT INTO links (domain_id, code, url, expires_at, redirect_type, passthrough, utm_template, 
+		                   utm_at_redirect, sticky_variant, interstitial) 
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		opts.DomainID, code, url, expires, redirectType, opts.Passthrough, utmTemplate, opts.UTMAtRedirect,
+		opts.StickyVariant, opts.Interstitial)
 	if err != nil {
 		return fmt.Errorf("ошибка при вставке ссылки: %w", err)
 	}
 
+	linkID, err := result.LastInsertId()
+	if err != nil {
+		return fmt.Errorf("ошибка при вставке ссылки: %w", err)
+	}
+
+	if err = insertRules(tx, linkID, opts.Rules); err != nil {
+		return err
+	}
+
+	if err = insertVariants(tx, linkID, opts.Variants); err != nil {
+		return err
+	}
+
+	if err = insertGeoTargets(tx, linkID, opts.GeoTargets); err != nil {
+		return err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("ошибка при вставке ссылки: %w", err)
+	}
+
 	return nil
 }
 
@@ -64,16 +108,47 @@ func contains(s, substr string) bool {
 	return false
 }
 
-func GetLink(db *sql.DB, code string) (*models.Link, error) {
+const linkColumns = `id, domain_id, code, url, created_at, expires_at, hit_count, redirect_type, passthrough,
+	utm_template, utm_at_redirect, sticky_variant, interstitial`
+
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanLink(row rowScanner) (*models.Link, error) {
 	var link models.Link
 	var expires sql.NullTime
+	var redirectType sql.NullInt64
+	var utmTemplate sql.NullString
+
+	err := row.Scan(&link.ID, &link.DomainID, &link.Code, &link.URL, &link.CreatedAt, &expires, &link.HitCount, &redirectType,
+		&link.Passthrough, &utmTemplate, &link.UTMAtRedirect, &link.StickyVariant,
+		&link.Interstitial)
+	if err != nil {
+		return nil, err
+	}
+
+	if expires.Valid {
+		link.ExpiresAt = &expires.Time
+	}
+	if redirectType.Valid {
+		link.RedirectType = int(redirectType.Int64)
+	}
+	link.UTMTemplate = utmTemplate.String
+
+	return &link, nil
+}
+
+func GetLink(db *sql.DB, code string) (*models.Link, error) {
+	return GetLinkInDomain(context.Background(), db, DefaultDomainID, code)
+}
 
-	err := db.QueryRow(`
-		SELECT id, code, url, created_at, expires_at, hit_count 
-		FROM links 
-		WHERE code = ?`, code).Scan(
-		&link.ID, &link.Code, &link.URL, &link.CreatedAt, &expires, &link.HitCount)
+func GetLinkInDomain(ctx context.Context, db *sql.DB, domainID int64, code string) (link *models.Link, err error) {
+	ctx, done := observe(ctx, "get_link")
+	defer done(&err)
 
+	link, err = scanLink(db.QueryRowContext(ctx, "SELECT "+linkColumns+" FROM links WHERE domain_id = ? AND code = ?",
+		domainID, code))
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return nil, nil
@@ -81,15 +156,31 @@ func GetLink(db *sql.DB, code string) (*models.Link, error) {
 		return nil, fmt.Errorf("ошибка при получении ссылки: %w", err)
 	}
 
-	if expires.Valid {
-		link.ExpiresAt = &expires.Time
+	if err = loadTargeting(ctx, db, link); err != nil {
+		return nil, err
 	}
 
-	return &link, nil
+	return link, nil
 }
 
 func IncrementHitCount(db *sql.DB, code string) error {
-	result, err := db.Exec("UPDATE links SET hit_count = hit_count + 1 WHERE code = ?", code)
+	var linkID int64
+	err := db.QueryRow("SELECT id FROM links WHERE domain_id = ? AND code = ?", DefaultDomainID, code).Scan(&linkID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return fmt.Errorf("ссылка не найдена")
+		}
+		return fmt.Errorf("ошибка при обновлении счетчика: %w", err)
+	}
+
+	return IncrementLinkHitCount(context.Background(), db, linkID)
+}
+
+func IncrementLinkHitCount(ctx context.Context, db *sql.DB, linkID int64) (err error) {
+	ctx, done := observe(ctx, "increment_hit_count")
+	defer done(&err)
+
+	result, err := db.ExecContext(ctx, "UPDATE links SET hit_count = hit_count + 1 WHERE id = ?", linkID)
 	if err != nil {
 		return fmt.Errorf("ошибка при обновлении счетчика: %w", err)
 	}
@@ -104,3 +195,108 @@ func IncrementHitCount(db *sql.DB, code string) error {
 
 	return nil
 }
+
+// UpdateLink сохраняет адрес назначения и настройки ссылки link.ID. Правила,
+// варианты и гео-таргетинг не меняются.
+func UpdateLink(ctx context.Context, db *sql.DB, link *models.Link) (err error) {
+	ctx, done := observe(ctx, "update_link")
+	defer done(&err)
+
+	var expires, redirectType interface{}
+	if link.ExpiresAt != nil {
+		expires = link.ExpiresAt.UTC()
+	}
+	if link.RedirectType != 0 {
+		redirectType = link.RedirectType
+	}
+
+	result, err := db.ExecContext(ctx, `
+		UPDATE links SET url = ?, expires_at = ?, redirect_type = ?, passthrough = ?, interstitial = ?
+		WHERE id = ?`,
+		link.URL, expires, redirectType, link.Passthrough, link.Interstitial, link.ID)
+	if err != nil {
+		return fmt.Errorf("ошибка при обновлении ссылки: %w", err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return fmt.Errorf("ссылка не найдена")
+	}
+
+	return nil
+}
+
+// DeleteLink удаляет ссылку вместе с правилами, вариантами и статистикой. Зависимые
+// строки удаляются явно: PRAGMA foreign_keys действует только на соединение, в
+// котором был выполнен.
+func DeleteLink(ctx context.Context, db *sql.DB, linkID int64) (deleted bool, err error) {
+	ctx, done := observe(ctx, "delete_link")
+	defer done(&err)
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return false, fmt.Errorf("ошибка при удалении ссылки: %w", err)
+	}
+	defer tx.Rollback()
+
+	for _, table := range []string{"link_stats", "link_rules", "link_variants", "link_geo"} {
+		if _, err = tx.ExecContext(ctx, "DELETE FROM "+table+" WHERE link_id = ?", linkID); err != nil {
+			return false, fmt.Errorf("ошибка при удалении ссылки: %w", err)
+		}
+	}
+
+	result, err := tx.ExecContext(ctx, "DELETE FROM links WHERE id = ?", linkID)
+	if err != nil {
+		return false, fmt.Errorf("ошибка при удалении ссылки: %w", err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return false, fmt.Errorf("ошибка при удалении ссылки: %w", err)
+	}
+
+	return affected > 0, nil
+}
+
+// likeEscaper экранирует спецсимволы шаблона LIKE, чтобы поиск шел по подстроке как есть.
+var likeEscaper = strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`)
+
+// ListLinks возвращает страницу ссылок домена без правил, вариантов и гео-таргетинга.
+func ListLinks(ctx context.Context, db *sql.DB, filter models.LinkFilter) (links []models.Link, err error) {
+	ctx, done := observe(ctx, "list_links")
+	defer done(&err)
+
+	query := "SELECT " + linkColumns + " FROM links WHERE domain_id = ? AND id > ?"
+	args := []any{filter.DomainID, filter.AfterID}
+	if filter.Search != "" {
+		pattern := "%" + likeEscaper.Replace(filter.Search) + "%"
+		query += ` AND (code LIKE ? ESCAPE '\' OR url LIKE ? ESCAPE '\')`
+		args = append(args, pattern, pattern)
+	}
+	query += " ORDER BY id LIMIT ?"
+	args = append(args, filter.Limit)
+
+	rows, err := db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при получении ссылок: %w", err)
+	}
+	defer rows.Close()
+
+	links = []models.Link{}
+	for rows.Next() {
+		link, err := scanLink(rows)
+		if err != nil {
+			return nil, fmt.Errorf("ошибка при чтении ссылки: %w", err)
+		}
+		links = append(links, *link)
+	}
+
+	return links, rows.Err()
+}
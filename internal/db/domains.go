@@ -0,0 +1,141 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"tinyurl/internal/models"
+)
+
+const DefaultDomainID = 0
+
+const domainColumns = "id, host, redirect_type, not_found_url, code_length, created_at"
+
+func scanDomain(row rowScanner) (*models.Domain, error) {
+	var d models.Domain
+	var redirectType, codeLength sql.NullInt64
+	var notFoundURL sql.NullString
+
+	if err := row.Scan(&d.ID, &d.Host, &redirectType, &notFoundURL, &codeLength, &d.CreatedAt); err != nil {
+		return nil, err
+	}
+
+	d.RedirectType = int(redirectType.Int64)
+	d.NotFoundURL = notFoundURL.String
+	d.CodeLength = int(codeLength.Int64)
+
+	return &d, nil
+}
+
+func domainArgs(d *models.Domain) (redirectType, notFoundURL, codeLength interface{}) {
+	if d.RedirectType != 0 {
+		redirectType = d.RedirectType
+	}
+	if d.NotFoundURL != "" {
+		notFoundURL = d.NotFoundURL
+	}
+	if d.CodeLength != 0 {
+		codeLength = d.CodeLength
+	}
+	return
+}
+
+func InsertDomain(ctx context.Context, db *sql.DB, d *models.Domain) (err error) {
+	ctx, done := observe(ctx, "insert_domain")
+	defer done(&err)
+
+	redirectType, notFoundURL, codeLength := domainArgs(d)
+	_, err = db.ExecContext(ctx, "INSERT INTO domains (host, redirect_type, not_found_url, code_length) VALUES (?, ?, ?, ?)",
+		d.Host, redirectType, notFoundURL, codeLength)
+	if err != nil {
+		return fmt.Errorf("ошибка при создании домена: %w", err)
+	}
+
+	return nil
+}
+
+func GetDomain(ctx context.Context, db *sql.DB, host string) (d *models.Domain, err error) {
+	ctx, done := observe(ctx, "get_domain")
+	defer done(&err)
+
+	d, err = scanDomain(db.QueryRowContext(ctx, "SELECT "+domainColumns+" FROM domains WHERE host = ?", host))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("ошибка при получении домена: %w", err)
+	}
+
+	return d, nil
+}
+
+func ListDomains(ctx context.Context, db *sql.DB) (domains []models.Domain, err error) {
+	ctx, done := observe(ctx, "list_domains")
+	defer done(&err)
+
+	rows, err := db.QueryContext(ctx, "SELECT "+domainColumns+" FROM domains ORDER BY host")
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при получении доменов: %w", err)
+	}
+	defer rows.Close()
+
+	domains = []models.Domain{}
+	for rows.Next() {
+		d, err := scanDomain(rows)
+		if err != nil {
+			return nil, fmt.Errorf("ошибка при чтении домена: %w", err)
+		}
+		domains = append(domains, *d)
+	}
+
+	return domains, rows.Err()
+}
+
+func UpdateDomain(ctx context.Context, db *sql.DB, d *models.Domain) (updated bool, err error) {
+	ctx, done := observe(ctx, "update_domain")
+	defer done(&err)
+
+	redirectType, notFoundURL, codeLength := domainArgs(d)
+	result, err := db.ExecContext(ctx, "UPDATE domains SET redirect_type = ?, not_found_url = ?, code_length = ? WHERE host = ?",
+		redirectType, notFoundURL, codeLength, d.Host)
+	if err != nil {
+		return false, fmt.Errorf("ошибка при обновлении домена: %w", err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return affected > 0, nil
+}
+
+func DeleteDomain(ctx context.Context, db *sql.DB, host string) (deleted bool, err error) {
+	ctx, done := observe(ctx, "delete_domain")
+	defer done(&err)
+
+	result, err := db.ExecContext(ctx, "DELETE FROM domains WHERE host = ?", host)
+	if err != nil {
+		return false, fmt.Errorf("ошибка при удалении домена: %w", err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return affected > 0, nil
+}
+
+func CountDomainLinks(ctx context.Context, db *sql.DB, domainID int64) (count int64, err error) {
+	ctx, done := observe(ctx, "count_domain_links")
+	defer done(&err)
+
+	if err = db.QueryRowContext(ctx, "SELECT COUNT(*) FROM links WHERE domain_id = ?", domainID).Scan(&count); err != nil {
+		return 0, fmt.Errorf("ошибка при подсчете ссылок: %w", err)
+	}
+
+	return count, nil
+}
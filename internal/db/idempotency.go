@@ -0,0 +1,81 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"tinyurl/internal/models"
+)
+
+// ReserveIdempotencyKey занимает ключ для нового запроса до req.ExpiresAt. Если
+// ключ уже занят неистекшей записью, она возвращается и ничего не меняется.
+// Заодно удаляются записи, срок которых истек к моменту now, в том числе брошенные
+// резервы запросов, не дождавшихся ответа: такой ключ занимает повтор.
+func ReserveIdempotencyKey(ctx context.Context, db *sql.DB, req models.IdempotentRequest, now time.Time) (existing *models.IdempotentRequest, err error) {
+	ctx, done := observe(ctx, "reserve_idempotency_key")
+	defer done(&err)
+
+	if _, err = db.ExecContext(ctx, "DELETE FROM idempotency_keys WHERE expires_at <= ?", now.UnixMilli()); err != nil {
+		return nil, fmt.Errorf("ошибка при удалении истекших ключей идемпотентности: %w", err)
+	}
+
+	result, err := db.ExecContext(ctx, `
+		INSERT INTO idempotency_keys (scope, key, request_hash, status, expires_at)
+		VALUES (?, ?, ?, 0, ?)
+		ON CONFLICT (scope, key) DO NOTHING`,
+		req.Scope, req.Key, req.RequestHash, req.ExpiresAt.UnixMilli())
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при сохранении ключа идемпотентности: %w", err)
+	}
+	if n, err := result.RowsAffected(); err != nil || n == 1 {
+		return nil, err
+	}
+
+	var found models.IdempotentRequest
+	var contentType sql.NullString
+	var expiresAt int64
+	err = db.QueryRowContext(ctx, `
+		SELECT scope, key, request_hash, status, content_type, body, expires_at
+		FROM idempotency_keys WHERE scope = ? AND key = ?`, req.Scope, req.Key).
+		Scan(&found.Scope, &found.Key, &found.RequestHash, &found.Status, &contentType, &found.Body, &expiresAt)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при получении ключа идемпотентности: %w", err)
+	}
+	found.ContentType = contentType.String
+	found.ExpiresAt = time.UnixMilli(expiresAt)
+
+	return &found, nil
+}
+
+// SaveIdempotentResponse сохраняет ответ на запрос, занявший ключ до reservedUntil,
+// и продлевает хранение до req.ExpiresAt. Если резерв истек и ключ занял повтор,
+// ответ не сохраняется, чтобы не подменить результат повтора.
+func SaveIdempotentResponse(ctx context.Context, db *sql.DB, req models.IdempotentRequest, reservedUntil time.Time) (err error) {
+	ctx, done := observe(ctx, "save_idempotent_response")
+	defer done(&err)
+
+	_, err = db.ExecContext(ctx, `
+		UPDATE idempotency_keys SET status = ?, content_type = ?, body = ?, expires_at = ?
+		WHERE scope = ? AND key = ? AND status = 0 AND expires_at = ?`,
+		req.Status, req.ContentType, req.Body, req.ExpiresAt.UnixMilli(), req.Scope, req.Key, reservedUntil.UnixMilli())
+	if err != nil {
+		return fmt.Errorf("ошибка при сохранении ответа по ключу идемпотентности: %w", err)
+	}
+	return nil
+}
+
+// ReleaseIdempotencyKey освобождает ключ, занятый до reservedUntil, чтобы запрос
+// можно было повторить. Резерв, который уже занял повтор, не трогается.
+func ReleaseIdempotencyKey(ctx context.Context, db *sql.DB, scope, key string, reservedUntil time.Time) (err error) {
+	ctx, done := observe(ctx, "release_idempotency_key")
+	defer done(&err)
+
+	_, err = db.ExecContext(ctx, "DELETE FROM idempotency_keys WHERE scope = ? AND key = ? AND status = 0 AND expires_at = ?",
+		scope, key, reservedUntil.UnixMilli())
+	if err != nil {
+		return fmt.Errorf("ошибка при освобождении ключа идемпотентности: %w", err)
+	}
+	return nil
+}
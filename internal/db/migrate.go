@@ -0,0 +1,357 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+)
+
+type columnMigration struct {
+	table  string
+	column string
+	ddl    string
+}
+
+var columnMigrations = []columnMigration{
+	{"links", "redirect_type", "ALTER TABLE links ADD COLUMN redirect_type INTEGER NULL"},
+	{"links", "passthrough", "ALTER TABLE links ADD COLUMN passthrough INTEGER NOT NULL DEFAULT 0"},
+	{"links", "utm_template", "ALTER TABLE links ADD COLUMN utm_template TEXT NULL"},
+	{"links", "utm_at_redirect", "ALTER TABLE links ADD COLUMN utm_at_redirect INTEGER NOT NULL DEFAULT 0"},
+	{"links", "sticky_variant", "ALTER TABLE links ADD COLUMN sticky_variant INTEGER NOT NULL DEFAULT 0"},
+	{"links", "interstitial", "ALTER TABLE links ADD COLUMN interstitial INTEGER NOT NULL DEFAULT 0"},
+	{"links", "domain_id", "ALTER TABLE links ADD COLUMN domain_id INTEGER NOT NULL DEFAULT 0"},
+	{"links", "expired_notified", "ALTER TABLE links ADD COLUMN expired_notified INTEGER NOT NULL DEFAULT 0"},
+}
+
+// linksTableDDL - определение links: по нему создается таблица в новой базе и
+// пересобирается таблица из старых версий схемы.
+const linksTableDDL = `CREATE TABLE %s (
+	id              INTEGER PRIMARY KEY AUTOINCREMENT,
+	domain_id       INTEGER NOT NULL DEFAULT 0,
+	code            TEXT    NOT NULL,
+	url             TEXT    NOT NULL,
+	created_at      TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	expires_at      TIMESTAMP NULL,
+	hit_count       INTEGER NOT NULL DEFAULT 0,
+	redirect_type   INTEGER NULL,
+	passthrough     INTEGER NOT NULL DEFAULT 0,
+	utm_template    TEXT NULL,
+	utm_at_redirect INTEGER NOT NULL DEFAULT 0,
+	sticky_variant  INTEGER NOT NULL DEFAULT 0,
+	interstitial    INTEGER NOT NULL DEFAULT 0,
+	expired_notified INTEGER NOT NULL DEFAULT 0
+)`
+
+var globalCodeUnique = regexp.MustCompile(`(?i)\bcode\s+TEXT\s+NOT\s+NULL\s+UNIQUE\b`)
+
+var createdObject = regexp.MustCompile(`CREATE (?:UNIQUE )?(?:TABLE|INDEX) IF NOT EXISTS (\w+)`)
+
+const linksDomainCodeIndex = "idx_links_domain_code"
+
+// tableMigrations создают схему в пустой базе и недостающие таблицы и индексы в
+// базах старых версий. Других источников схемы нет.
+var tableMigrations = []string{
+	fmt.Sprintf(linksTableDDL, "IF NOT EXISTS links"),
+	`CREATE INDEX IF NOT EXISTS idx_links_expires_at ON links (expires_at)`,
+	`CREATE TABLE IF NOT EXISTS utm_templates (
+		id           INTEGER PRIMARY KEY AUTOINCREMENT,
+		name         TEXT    NOT NULL UNIQUE,
+		utm_source   TEXT    NOT NULL,
+		utm_medium   TEXT    NOT NULL,
+		utm_campaign TEXT    NOT NULL,
+		utm_term     TEXT    NOT NULL DEFAULT '',
+		utm_content  TEXT    NOT NULL DEFAULT '',
+		created_at   TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+	)`,
+	`CREATE TABLE IF NOT EXISTS link_stats (
+		link_id   INTEGER NOT NULL REFERENCES links (id) ON DELETE CASCADE,
+		dimension TEXT    NOT NULL,
+		value     TEXT    NOT NULL,
+		hits      INTEGER NOT NULL DEFAULT 0,
+		PRIMARY KEY (link_id, dimension, value)
+	)`,
+	`CREATE INDEX IF NOT EXISTS idx_link_stats_dimension ON link_stats (dimension, value)`,
+	`CREATE TABLE IF NOT EXISTS link_rules (
+		id       INTEGER PRIMARY KEY AUTOINCREMENT,
+		link_id  INTEGER NOT NULL REFERENCES links (id) ON DELETE CASCADE,
+		position INTEGER NOT NULL,
+		name     TEXT    NOT NULL,
+		os       TEXT    NOT NULL DEFAULT '',
+		device   TEXT    NOT NULL DEFAULT '',
+		bot      INTEGER NULL,
+		url      TEXT    NOT NULL
+	)`,
+	`CREATE INDEX IF NOT EXISTS idx_link_rules_link_id ON link_rules (link_id, position)`,
+	`CREATE TABLE IF NOT EXISTS link_variants (
+		id       INTEGER PRIMARY KEY AUTOINCREMENT,
+		link_id  INTEGER NOT NULL REFERENCES links (id) ON DELETE CASCADE,
+		position INTEGER NOT NULL,
+		name     TEXT    NOT NULL,
+		url      TEXT    NOT NULL,
+		weight   INTEGER NOT NULL
+	)`,
+	`CREATE INDEX IF NOT EXISTS idx_link_variants_link_id ON link_variants (link_id, position)`,
+	`CREATE TABLE IF NOT EXISTS link_geo (
+		link_id INTEGER NOT NULL REFERENCES links (id) ON DELETE CASCADE,
+		country TEXT    NOT NULL,
+		url     TEXT    NOT NULL,
+		PRIMARY KEY (link_id, country)
+	)`,
+	`CREATE TABLE IF NOT EXISTS domains (
+		id            INTEGER PRIMARY KEY AUTOINCREMENT,
+		host          TEXT    NOT NULL UNIQUE,
+		redirect_type INTEGER NULL,
+		not_found_url TEXT    NULL,
+		code_length   INTEGER NULL,
+		created_at    TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+	)`,
+	`CREATE TABLE IF NOT EXISTS webhooks (
+		id         INTEGER PRIMARY KEY AUTOINCREMENT,
+		url        TEXT    NOT NULL,
+		events     TEXT    NOT NULL,
+		secret     TEXT    NOT NULL,
+		created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+	)`,
+	// next_attempt_at хранится в миллисекундах Unix, чтобы сравнение в SQL не зависело
+	// от формата и часового пояса записи времени.
+	`CREATE TABLE IF NOT EXISTS webhook_deliveries (
+		id               INTEGER PRIMARY KEY AUTOINCREMENT,
+		webhook_id       INTEGER NOT NULL REFERENCES webhooks (id) ON DELETE CASCADE,
+		event_id         TEXT    NOT NULL,
+		event            TEXT    NOT NULL,
+		payload          TEXT    NOT NULL,
+		status           TEXT    NOT NULL,
+		attempts         INTEGER NOT NULL DEFAULT 0,
+		next_attempt_at  INTEGER NULL,
+		last_status_code INTEGER NULL,
+		last_error       TEXT    NULL,
+		created_at       TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+		updated_at       TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+	)`,
+	`CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_due ON webhook_deliveries (status, next_attempt_at)`,
+	`CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_webhook_id ON webhook_deliveries (webhook_id, id)`,
+	`CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_updated_at ON webhook_deliveries (status, updated_at)`,
+	`CREATE TABLE IF NOT EXISTS webhook_attempts (
+		id          INTEGER PRIMARY KEY AUTOINCREMENT,
+		delivery_id INTEGER NOT NULL REFERENCES webhook_deliveries (id) ON DELETE CASCADE,
+		attempt     INTEGER NOT NULL,
+		status_code INTEGER NULL,
+		error       TEXT    NULL,
+		duration_ms INTEGER NOT NULL,
+		created_at  TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+	)`,
+	`CREATE INDEX IF NOT EXISTS idx_webhook_attempts_delivery_id ON webhook_attempts (delivery_id, id)`,
+	// Ключи Idempotency-Key запросов создания. status 0 - запрос еще выполняется;
+	// expires_at хранится в миллисекундах Unix, как next_attempt_at.
+	`CREATE TABLE IF NOT EXISTS idempotency_keys (
+		scope        TEXT    NOT NULL,
+		key          TEXT    NOT NULL,
+		request_hash TEXT    NOT NULL,
+		status       INTEGER NOT NULL DEFAULT 0,
+		content_type TEXT    NULL,
+		body         BLOB    NULL,
+		expires_at   INTEGER NOT NULL,
+		PRIMARY KEY (scope, key)
+	)`,
+	`CREATE INDEX IF NOT EXISTS idx_idempotency_keys_expires_at ON idempotency_keys (expires_at)`,
+}
+
+func Migrate(db *sql.DB) error {
+	for _, ddl := range tableMigrations {
+		if _, err := db.Exec(ddl); err != nil {
+			return fmt.Errorf("ошибка при миграции: %w", err)
+		}
+	}
+
+	for _, m := range columnMigrations {
+		columns, err := tableColumns(context.Background(), db, m.table)
+		if err != nil {
+			return err
+		}
+		if columnIn(columns, m.column) {
+			continue
+		}
+		if _, err := db.Exec(m.ddl); err != nil {
+			return fmt.Errorf("ошибка при миграции %s.%s: %w", m.table, m.column, err)
+		}
+	}
+
+	if err := scopeLinkCodesToDomains(db); err != nil {
+		return err
+	}
+
+	if err := normalizeLinkExpiry(db); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// PendingMigrations возвращает таблицы, индексы и столбцы, которые Migrate еще не создал.
+func PendingMigrations(ctx context.Context, db *sql.DB) ([]string, error) {
+	var pending []string
+
+	objects := []string{linksDomainCodeIndex}
+	for _, ddl := range tableMigrations {
+		if m := createdObject.FindStringSubmatch(ddl); m != nil {
+			objects = append(objects, m[1])
+		}
+	}
+	for _, name := range objects {
+		var count int
+		if err := db.QueryRowContext(ctx, "SELECT COUNT(*) FROM sqlite_master WHERE name = ?", name).Scan(&count); err != nil {
+			return nil, fmt.Errorf("ошибка при проверке миграций: %w", err)
+		}
+		if count == 0 {
+			pending = append(pending, name)
+		}
+	}
+
+	for _, m := range columnMigrations {
+		columns, err := tableColumns(ctx, db, m.table)
+		if err != nil {
+			return nil, err
+		}
+		if !columnIn(columns, m.column) {
+			pending = append(pending, m.table+"."+m.column)
+		}
+	}
+
+	return pending, nil
+}
+
+// scopeLinkCodesToDomains пересоздает таблицу links из старых версий схемы, где код
+// был уникален глобально, чтобы один и тот же код мог существовать в разных доменах.
+func scopeLinkCodesToDomains(db *sql.DB) error {
+	var tableSQL string
+	if err := db.QueryRow("SELECT sql FROM sqlite_master WHERE type = 'table' AND name = 'links'").Scan(&tableSQL); err != nil {
+		return fmt.Errorf("ошибка при чтении структуры таблицы links: %w", err)
+	}
+
+	if globalCodeUnique.MatchString(tableSQL) {
+		if err := rebuildLinksTable(db); err != nil {
+			return fmt.Errorf("ошибка при миграции links: %w", err)
+		}
+	}
+
+	if _, err := db.Exec("CREATE UNIQUE INDEX IF NOT EXISTS " + linksDomainCodeIndex + " ON links (domain_id, code)"); err != nil {
+		return fmt.Errorf("ошибка при миграции links: %w", err)
+	}
+
+	return nil
+}
+
+// utcSuffix - окончание, с которым драйвер записывает время в UTC.
+const utcSuffix = " +0000 UTC"
+
+// normalizeLinkExpiry переводит в UTC сроки действия, записанные старыми версиями в
+// часовом поясе сервера: иначе их нельзя сравнивать со временем как строки.
+func normalizeLinkExpiry(db *sql.DB) error {
+	rows, err := db.Query("SELECT id, expires_at FROM links WHERE expires_at IS NOT NULL AND expires_at NOT LIKE ?", "%"+utcSuffix)
+	if err != nil {
+		return fmt.Errorf("ошибка при миграции links.expires_at: %w", err)
+	}
+	expiry := map[int64]time.Time{}
+	for rows.Next() {
+		var id int64
+		var expires time.Time
+		if err := rows.Scan(&id, &expires); err != nil {
+			rows.Close()
+			return fmt.Errorf("ошибка при миграции links.expires_at: %w", err)
+		}
+		expiry[id] = expires
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("ошибка при миграции links.expires_at: %w", err)
+	}
+
+	for id, expires := range expiry {
+		if _, err := db.Exec("UPDATE links SET expires_at = ? WHERE id = ?", expires.UTC(), id); err != nil {
+			return fmt.Errorf("ошибка при миграции links.expires_at: %w", err)
+		}
+	}
+
+	return nil
+}
+
+func rebuildLinksTable(db *sql.DB) error {
+	columns, err := tableColumns(context.Background(), db, "links")
+	if err != nil {
+		return err
+	}
+	columnList := strings.Join(columns, ", ")
+
+	ctx := context.Background()
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	// Внешние ключи отключаются, чтобы DROP TABLE не удалил каскадом связанные строки.
+	if _, err := conn.ExecContext(ctx, "PRAGMA foreign_keys=OFF"); err != nil {
+		return err
+	}
+	defer conn.ExecContext(ctx, "PRAGMA foreign_keys=ON")
+
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	statements := []string{
+		fmt.Sprintf(linksTableDDL, "links_new"),
+		fmt.Sprintf("INSERT INTO links_new (%s) SELECT %s FROM links", columnList, columnList),
+		"DROP TABLE links",
+		"ALTER TABLE links_new RENAME TO links",
+		"CREATE INDEX IF NOT EXISTS idx_links_expires_at ON links (expires_at)",
+	}
+	for _, stmt := range statements {
+		if _, err := tx.ExecContext(ctx, stmt); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+func columnIn(columns []string, column string) bool {
+	for _, c := range columns {
+		if c == column {
+			return true
+		}
+	}
+	return false
+}
+
+func tableColumns(ctx context.Context, db *sql.DB, table string) ([]string, error) {
+	rows, err := db.QueryContext(ctx, fmt.Sprintf("PRAGMA table_info(%s)", table))
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при чтении структуры таблицы %s: %w", table, err)
+	}
+	defer rows.Close()
+
+	var columns []string
+
+	for rows.Next() {
+		var (
+			cid       int
+			name      string
+			colType   string
+			notNull   int
+			dfltValue sql.NullString
+			pk        int
+		)
+		if err := rows.Scan(&cid, &name, &colType, &notNull, &dfltValue, &pk); err != nil {
+			return nil, err
+		}
+		columns = append(columns, name)
+	}
+
+	return columns, rows.Err()
+}
@@ -0,0 +1,20 @@
+package db
+
+import (
+	"context"
+	"time"
+
+	"tinyurl/internal/metrics"
+	"tinyurl/internal/tracing"
+)
+
+// observe открывает спан запроса к базе; возвращаемая функция вызывается через
+// defer с указателем на ошибку и записывает спан и метрики длительности.
+func observe(ctx context.Context, operation string) (context.Context, func(*error)) {
+	start := time.Now()
+	ctx, end := tracing.StartQuery(ctx, operation)
+	return ctx, func(err *error) {
+		metrics.ObserveQuery(operation, start, err)
+		end(*err)
+	}
+}
@@ -0,0 +1,85 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"tinyurl/internal/models"
+)
+
+const (
+	DimensionCampaign = "campaign"
+	DimensionRule     = "rule"
+	DimensionVariant  = "variant"
+	DimensionCountry  = "country"
+)
+
+func IncrementBreakdown(ctx context.Context, db *sql.DB, linkID int64, dimension, value string) (err error) {
+	ctx, done := observe(ctx, "increment_breakdown")
+	defer done(&err)
+
+	_, err = db.ExecContext(ctx, `
+		INSERT INTO link_stats (link_id, dimension, value, hits) 
+		VALUES (?, ?, ?, 1) 
+		ON CONFLICT (link_id, dimension, value) DO UPDATE SET hits = hits + 1`,
+		linkID, dimension, value)
+	if err != nil {
+		return fmt.Errorf("ошибка при обновлении статистики: %w", err)
+	}
+
+	return nil
+}
+
+func GetBreakdown(ctx context.Context, db *sql.DB, linkID int64) (breakdown models.Breakdown, err error) {
+	ctx, done := observe(ctx, "get_breakdown")
+	defer done(&err)
+
+	rows, err := db.QueryContext(ctx, "SELECT dimension, value, hits FROM link_stats WHERE link_id = ?", linkID)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при получении статистики: %w", err)
+	}
+	defer rows.Close()
+
+	breakdown = models.Breakdown{}
+	for rows.Next() {
+		var dimension, value string
+		var hits int64
+		if err := rows.Scan(&dimension, &value, &hits); err != nil {
+			return nil, fmt.Errorf("ошибка при чтении статистики: %w", err)
+		}
+		if breakdown[dimension] == nil {
+			breakdown[dimension] = map[string]int64{}
+		}
+		breakdown[dimension][value] = hits
+	}
+
+	return breakdown, rows.Err()
+}
+
+func GetCampaignStats(ctx context.Context, db *sql.DB) (stats []models.CampaignStats, err error) {
+	ctx, done := observe(ctx, "get_campaign_stats")
+	defer done(&err)
+
+	rows, err := db.QueryContext(ctx, `
+		SELECT value, SUM(hits), COUNT(DISTINCT link_id) 
+		FROM link_stats 
+		WHERE dimension = ? 
+		GROUP BY value 
+		ORDER BY SUM(hits) DESC, value`, DimensionCampaign)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при получении статистики кампаний: %w", err)
+	}
+	defer rows.Close()
+
+	stats = []models.CampaignStats{}
+	for rows.Next() {
+		var c models.CampaignStats
+		if err := rows.Scan(&c.Campaign, &c.HitCount, &c.Links); err != nil {
+			return nil, fmt.Errorf("ошибка при чтении статистики кампаний: %w", err)
+		}
+		stats = append(stats, c)
+	}
+
+	return stats, rows.Err()
+}
@@ -0,0 +1,104 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"tinyurl/internal/models"
+)
+
+func insertRules(tx *sql.Tx, linkID int64, rules []models.TargetRule) error {
+	for i, rule := range rules {
+		var bot interface{}
+		if rule.Bot != nil {
+			bot = *rule.Bot
+		}
+
+		_, err := tx.Exec(`
+			INSERT INTO link_rules (link_id, position, name, os, device, bot, url) 
+			VALUES (?, ?, ?, ?, ?, ?, ?)`,
+			linkID, i, rule.Name, rule.OS, rule.Device, bot, rule.URL)
+		if err != nil {
+			return fmt.Errorf("ошибка при сохранении правила: %w", err)
+		}
+	}
+
+	return nil
+}
+
+func insertVariants(tx *sql.Tx, linkID int64, variants []models.Variant) error {
+	for i, variant := range variants {
+		_, err := tx.Exec(`
+			INSERT INTO link_variants (link_id, position, name, url, weight) 
+			VALUES (?, ?, ?, ?, ?)`,
+			linkID, i, variant.Name, variant.URL, variant.Weight)
+		if err != nil {
+			return fmt.Errorf("ошибка при сохранении варианта: %w", err)
+		}
+	}
+
+	return nil
+}
+
+func insertGeoTargets(tx *sql.Tx, linkID int64, targets map[string]string) error {
+	for country, url := range targets {
+		_, err := tx.Exec("INSERT INTO link_geo (link_id, country, url) VALUES (?, ?, ?)", linkID, country, url)
+		if err != nil {
+			return fmt.Errorf("ошибка при сохранении гео-правила: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// loadTargeting загружает правила, варианты и гео-правила ссылки одним запросом.
+func loadTargeting(ctx context.Context, db *sql.DB, link *models.Link) (err error) {
+	ctx, done := observe(ctx, "get_link_targeting")
+	defer done(&err)
+
+	rows, err := db.QueryContext(ctx, `
+		SELECT 'rule', position, name, os, device, bot, url, 0, '' 
+		FROM link_rules 
+		WHERE link_id = ? 
+		UNION ALL 
+		SELECT 'variant', position, name, '', '', NULL, url, weight, '' 
+		FROM link_variants 
+		WHERE link_id = ? 
+		UNION ALL 
+		SELECT 'geo', 0, '', '', '', NULL, url, 0, country 
+		FROM link_geo 
+		WHERE link_id = ? 
+		ORDER BY 1, 2`, link.ID, link.ID, link.ID)
+	if err != nil {
+		return fmt.Errorf("ошибка при получении правил: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var kind, name, os, device, url, country string
+		var position, weight int
+		var bot sql.NullBool
+		if err := rows.Scan(&kind, &position, &name, &os, &device, &bot, &url, &weight, &country); err != nil {
+			return fmt.Errorf("ошибка при чтении правила: %w", err)
+		}
+
+		switch kind {
+		case "rule":
+			rule := models.TargetRule{Name: name, OS: os, Device: device, URL: url}
+			if bot.Valid {
+				rule.Bot = &bot.Bool
+			}
+			link.Rules = append(link.Rules, rule)
+		case "variant":
+			link.Variants = append(link.Variants, models.Variant{Name: name, URL: url, Weight: weight})
+		case "geo":
+			if link.GeoTargets == nil {
+				link.GeoTargets = map[string]string{}
+			}
+			link.GeoTargets[country] = url
+		}
+	}
+
+	return rows.Err()
+}
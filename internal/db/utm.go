@@ -0,0 +1,119 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"tinyurl/internal/models"
+)
+
+func InsertUTMTemplate(ctx context.Context, db *sql.DB, t *models.UTMTemplate) (err error) {
+	ctx, done := observe(ctx, "insert_utm_template")
+	defer done(&err)
+
+	_, err = db.ExecContext(ctx, `
+		INSERT INTO utm_templates (name, utm_source, utm_medium, utm_campaign, utm_term, utm_content) 
+		VALUES (?, ?, ?, ?, ?, ?)`,
+		t.Name, t.Source, t.Medium, t.Campaign, t.Term, t.Content)
+	if err != nil {
+		return fmt.Errorf("ошибка при создании UTM-шаблона: %w", err)
+	}
+
+	return nil
+}
+
+func GetUTMTemplate(ctx context.Context, db *sql.DB, name string) (_ *models.UTMTemplate, err error) {
+	ctx, done := observe(ctx, "get_utm_template")
+	defer done(&err)
+
+	var t models.UTMTemplate
+	err = db.QueryRowContext(ctx, `
+		SELECT id, name, utm_source, utm_medium, utm_campaign, utm_term, utm_content, created_at 
+		FROM utm_templates 
+		WHERE name = ?`, name).Scan(
+		&t.ID, &t.Name, &t.Source, &t.Medium, &t.Campaign, &t.Term, &t.Content, &t.CreatedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("ошибка при получении UTM-шаблона: %w", err)
+	}
+
+	return &t, nil
+}
+
+func ListUTMTemplates(ctx context.Context, db *sql.DB) (templates []models.UTMTemplate, err error) {
+	ctx, done := observe(ctx, "list_utm_templates")
+	defer done(&err)
+
+	rows, err := db.QueryContext(ctx, `
+		SELECT id, name, utm_source, utm_medium, utm_campaign, utm_term, utm_content, created_at 
+		FROM utm_templates 
+		ORDER BY name`)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при получении UTM-шаблонов: %w", err)
+	}
+	defer rows.Close()
+
+	templates = []models.UTMTemplate{}
+	for rows.Next() {
+		var t models.UTMTemplate
+		if err := rows.Scan(&t.ID, &t.Name, &t.Source, &t.Medium, &t.Campaign, &t.Term, &t.Content, &t.CreatedAt); err != nil {
+			return nil, fmt.Errorf("ошибка при чтении UTM-шаблона: %w", err)
+		}
+		templates = append(templates, t)
+	}
+
+	return templates, rows.Err()
+}
+
+func UpdateUTMTemplate(ctx context.Context, db *sql.DB, t *models.UTMTemplate) (updated bool, err error) {
+	ctx, done := observe(ctx, "update_utm_template")
+	defer done(&err)
+
+	result, err := db.ExecContext(ctx, `
+		UPDATE utm_templates 
+		SET utm_source = ?, utm_medium = ?, utm_campaign = ?, utm_term = ?, utm_content = ? 
+		WHERE name = ?`,
+		t.Source, t.Medium, t.Campaign, t.Term, t.Content, t.Name)
+	if err != nil {
+		return false, fmt.Errorf("ошибка при обновлении UTM-шаблона: %w", err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return affected > 0, nil
+}
+
+func DeleteUTMTemplate(ctx context.Context, db *sql.DB, name string) (deleted bool, err error) {
+	ctx, done := observe(ctx, "delete_utm_template")
+	defer done(&err)
+
+	result, err := db.ExecContext(ctx, "DELETE FROM utm_templates WHERE name = ?", name)
+	if err != nil {
+		return false, fmt.Errorf("ошибка при удалении UTM-шаблона: %w", err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return affected > 0, nil
+}
+
+func CountLinksWithUTMTemplate(ctx context.Context, db *sql.DB, name string) (count int64, err error) {
+	ctx, done := observe(ctx, "count_links_with_utm_template")
+	defer done(&err)
+
+	if err = db.QueryRowContext(ctx, "SELECT COUNT(*) FROM links WHERE utm_template = ?", name).Scan(&count); err != nil {
+		return 0, fmt.Errorf("ошибка при подсчете ссылок: %w", err)
+	}
+
+	return count, nil
+}
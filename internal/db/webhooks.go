@@ -0,0 +1,477 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"tinyurl/internal/models"
+)
+
+const webhookColumns = "id, url, events, secret, created_at"
+
+const deliveryColumns = `id, webhook_id, event_id, event, payload, status, attempts, next_attempt_at, last_status_code,
+	last_error, created_at, updated_at`
+
+func scanWebhook(row rowScanner) (*models.Webhook, error) {
+	var w models.Webhook
+	var events string
+
+	if err := row.Scan(&w.ID, &w.URL, &events, &w.Secret, &w.CreatedAt); err != nil {
+		return nil, err
+	}
+	w.Events = strings.Split(events, ",")
+
+	return &w, nil
+}
+
+func scanDelivery(row rowScanner) (*models.WebhookDelivery, error) {
+	var d models.WebhookDelivery
+	var nextAttempt, lastStatus sql.NullInt64
+	var lastError sql.NullString
+
+	err := row.Scan(&d.ID, &d.WebhookID, &d.EventID, &d.Event, &d.Payload, &d.Status, &d.Attempts, &nextAttempt,
+		&lastStatus, &lastError, &d.CreatedAt, &d.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	if nextAttempt.Valid {
+		t := time.UnixMilli(nextAttempt.Int64)
+		d.NextAttemptAt = &t
+	}
+	d.LastStatusCode = int(lastStatus.Int64)
+	d.LastError = lastError.String
+
+	return &d, nil
+}
+
+// InsertWebhook сохраняет подписку и заполняет ее ID и время создания.
+func InsertWebhook(ctx context.Context, db *sql.DB, w *models.Webhook) (err error) {
+	ctx, done := observe(ctx, "insert_webhook")
+	defer done(&err)
+
+	result, err := db.ExecContext(ctx, "INSERT INTO webhooks (url, events, secret) VALUES (?, ?, ?)",
+		w.URL, strings.Join(w.Events, ","), w.Secret)
+	if err != nil {
+		return fmt.Errorf("ошибка при создании вебхука: %w", err)
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return fmt.Errorf("ошибка при создании вебхука: %w", err)
+	}
+
+	if err = db.QueryRowContext(ctx, "SELECT created_at FROM webhooks WHERE id = ?", id).Scan(&w.CreatedAt); err != nil {
+		return fmt.Errorf("ошибка при создании вебхука: %w", err)
+	}
+	w.ID = id
+
+	return nil
+}
+
+func GetWebhook(ctx context.Context, db *sql.DB, id int64) (w *models.Webhook, err error) {
+	ctx, done := observe(ctx, "get_webhook")
+	defer done(&err)
+
+	w, err = scanWebhook(db.QueryRowContext(ctx, "SELECT "+webhookColumns+" FROM webhooks WHERE id = ?", id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("ошибка при получении вебхука: %w", err)
+	}
+
+	return w, nil
+}
+
+func ListWebhooks(ctx context.Context, db *sql.DB) (webhooks []models.Webhook, err error) {
+	ctx, done := observe(ctx, "list_webhooks")
+	defer done(&err)
+
+	rows, err := db.QueryContext(ctx, "SELECT "+webhookColumns+" FROM webhooks ORDER BY id")
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при получении вебхуков: %w", err)
+	}
+	defer rows.Close()
+
+	webhooks = []models.Webhook{}
+	for rows.Next() {
+		w, err := scanWebhook(rows)
+		if err != nil {
+			return nil, fmt.Errorf("ошибка при чтении вебхука: %w", err)
+		}
+		webhooks = append(webhooks, *w)
+	}
+
+	return webhooks, rows.Err()
+}
+
+// WebhooksForEvent возвращает подписки на тип события event.
+func WebhooksForEvent(ctx context.Context, db *sql.DB, event string) (webhooks []models.Webhook, err error) {
+	ctx, done := observe(ctx, "webhooks_for_event")
+	defer done(&err)
+
+	rows, err := db.QueryContext(ctx, "SELECT "+webhookColumns+" FROM webhooks ORDER BY id")
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при получении вебхуков: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		w, err := scanWebhook(rows)
+		if err != nil {
+			return nil, fmt.Errorf("ошибка при чтении вебхука: %w", err)
+		}
+		if slices.Contains(w.Events, event) {
+			webhooks = append(webhooks, *w)
+		}
+	}
+
+	return webhooks, rows.Err()
+}
+
+// DeleteWebhook удаляет подписку вместе с очередью и журналом доставок.
+func DeleteWebhook(ctx context.Context, db *sql.DB, id int64) (deleted bool, err error) {
+	ctx, done := observe(ctx, "delete_webhook")
+	defer done(&err)
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return false, fmt.Errorf("ошибка при удалении вебхука: %w", err)
+	}
+	defer tx.Rollback()
+
+	statements := []string{
+		"DELETE FROM webhook_attempts WHERE delivery_id IN (SELECT id FROM webhook_deliveries WHERE webhook_id = ?)",
+		"DELETE FROM webhook_deliveries WHERE webhook_id = ?",
+	}
+	for _, stmt := range statements {
+		if _, err = tx.ExecContext(ctx, stmt, id); err != nil {
+			return false, fmt.Errorf("ошибка при удалении вебхука: %w", err)
+		}
+	}
+
+	result, err := tx.ExecContext(ctx, "DELETE FROM webhooks WHERE id = ?", id)
+	if err != nil {
+		return false, fmt.Errorf("ошибка при удалении вебхука: %w", err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return false, fmt.Errorf("ошибка при удалении вебхука: %w", err)
+	}
+
+	return affected > 0, nil
+}
+
+// InsertDeliveries ставит доставки в очередь с первой попыткой в момент now.
+func InsertDeliveries(ctx context.Context, db *sql.DB, deliveries []models.WebhookDelivery, now time.Time) (err error) {
+	ctx, done := observe(ctx, "insert_deliveries")
+	defer done(&err)
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("ошибка при постановке доставки в очередь: %w", err)
+	}
+	defer tx.Rollback()
+
+	for _, d := range deliveries {
+		_, err = tx.ExecContext(ctx, `
+			INSERT INTO webhook_deliveries (webhook_id, event_id, event, payload, status, next_attempt_at)
+			VALUES (?, ?, ?, ?, ?, ?)`,
+			d.WebhookID, d.EventID, d.Event, d.Payload, models.DeliveryPending, now.UnixMilli())
+		if err != nil {
+			return fmt.Errorf("ошибка при постановке доставки в очередь: %w", err)
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("ошибка при постановке доставки в очередь: %w", err)
+	}
+
+	return nil
+}
+
+// DueDeliveries возвращает до limit ожидающих доставок, время попытки которых
+// наступило, но не больше perWebhook доставок одного вебхука, чтобы очередь
+// одного получателя не занимала весь проход.
+func DueDeliveries(ctx context.Context, db *sql.DB, now time.Time, limit, perWebhook int) (deliveries []models.WebhookDelivery, err error) {
+	ctx, done := observe(ctx, "due_deliveries")
+	defer done(&err)
+
+	rows, err := db.QueryContext(ctx, "SELECT "+deliveryColumns+` FROM (
+			SELECT *, ROW_NUMBER() OVER (PARTITION BY webhook_id ORDER BY next_attempt_at, id) AS n
+			FROM webhook_deliveries WHERE status = ? AND next_attempt_at <= ?
+		) WHERE n <= ? ORDER BY next_attempt_at, id LIMIT ?`,
+		models.DeliveryPending, now.UnixMilli(), perWebhook, limit)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при получении очереди доставок: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		d, err := scanDelivery(rows)
+		if err != nil {
+			return nil, fmt.Errorf("ошибка при чтении доставки: %w", err)
+		}
+		deliveries = append(deliveries, *d)
+	}
+
+	return deliveries, rows.Err()
+}
+
+// RecordAttempt записывает попытку в журнал и сохраняет новое состояние доставки d.
+func RecordAttempt(ctx context.Context, db *sql.DB, d *models.WebhookDelivery, attempt models.WebhookAttempt) (err error) {
+	ctx, done := observe(ctx, "record_attempt")
+	defer done(&err)
+
+	var statusCode, attemptError, nextAttempt interface{}
+	if attempt.StatusCode != 0 {
+		statusCode = attempt.StatusCode
+	}
+	if attempt.Error != "" {
+		attemptError = attempt.Error
+	}
+	if d.NextAttemptAt != nil {
+		nextAttempt = d.NextAttemptAt.UnixMilli()
+	}
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("ошибка при записи попытки доставки: %w", err)
+	}
+	defer tx.Rollback()
+
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO webhook_attempts (delivery_id, attempt, status_code, error, duration_ms)
+		VALUES (?, ?, ?, ?, ?)`,
+		d.ID, attempt.Attempt, statusCode, attemptError, attempt.DurationMS)
+	if err != nil {
+		return fmt.Errorf("ошибка при записи попытки доставки: %w", err)
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		UPDATE webhook_deliveries
+		SET status = ?, attempts = ?, next_attempt_at = ?, last_status_code = ?, last_error = ?, updated_at = CURRENT_TIMESTAMP
+		WHERE id = ?`,
+		d.Status, d.Attempts, nextAttempt, statusCode, attemptError, d.ID)
+	if err != nil {
+		return fmt.Errorf("ошибка при записи попытки доставки: %w", err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("ошибка при записи попытки доставки: %w", err)
+	}
+
+	return nil
+}
+
+// PruneDeliveries удаляет доставленные и dead доставки, не менявшиеся с момента
+// before, вместе с журналом попыток и возвращает число удаленных доставок.
+func PruneDeliveries(ctx context.Context, db *sql.DB, before time.Time) (pruned int64, err error) {
+	ctx, done := observe(ctx, "prune_deliveries")
+	defer done(&err)
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("ошибка при очистке доставок: %w", err)
+	}
+	defer tx.Rollback()
+
+	// updated_at хранится как CURRENT_TIMESTAMP: строка UTC с точностью до секунды.
+	args := []interface{}{models.DeliveryDelivered, models.DeliveryDead, before.UTC().Format(time.DateTime)}
+	_, err = tx.ExecContext(ctx, `
+		DELETE FROM webhook_attempts WHERE delivery_id IN (
+			SELECT id FROM webhook_deliveries WHERE status IN (?, ?) AND updated_at < ?
+		)`, args...)
+	if err != nil {
+		return 0, fmt.Errorf("ошибка при очистке доставок: %w", err)
+	}
+
+	result, err := tx.ExecContext(ctx, "DELETE FROM webhook_deliveries WHERE status IN (?, ?) AND updated_at < ?", args...)
+	if err != nil {
+		return 0, fmt.Errorf("ошибка при очистке доставок: %w", err)
+	}
+	if pruned, err = result.RowsAffected(); err != nil {
+		return 0, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return 0, fmt.Errorf("ошибка при очистке доставок: %w", err)
+	}
+
+	return pruned, nil
+}
+
+// ListDeliveries возвращает последние limit доставок вебхука с журналом попыток.
+// Пустой status означает доставки в любом статусе.
+func ListDeliveries(ctx context.Context, db *sql.DB, webhookID int64, status string, limit int) (deliveries []models.WebhookDelivery, err error) {
+	ctx, done := observe(ctx, "list_deliveries")
+	defer done(&err)
+
+	query := "SELECT " + deliveryColumns + " FROM webhook_deliveries WHERE webhook_id = ?"
+	args := []interface{}{webhookID}
+	if status != "" {
+		query += " AND status = ?"
+		args = append(args, status)
+	}
+	query += " ORDER BY id DESC LIMIT ?"
+	args = append(args, limit)
+
+	rows, err := db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при получении доставок: %w", err)
+	}
+	defer rows.Close()
+
+	deliveries = []models.WebhookDelivery{}
+	for rows.Next() {
+		d, err := scanDelivery(rows)
+		if err != nil {
+			return nil, fmt.Errorf("ошибка при чтении доставки: %w", err)
+		}
+		deliveries = append(deliveries, *d)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if err = loadAttempts(ctx, db, deliveries); err != nil {
+		return nil, err
+	}
+
+	return deliveries, nil
+}
+
+// GetDelivery возвращает доставку вебхука с журналом попыток.
+func GetDelivery(ctx context.Context, db *sql.DB, webhookID, id int64) (d *models.WebhookDelivery, err error) {
+	ctx, done := observe(ctx, "get_delivery")
+	defer done(&err)
+
+	d, err = scanDelivery(db.QueryRowContext(ctx, "SELECT "+deliveryColumns+" FROM webhook_deliveries WHERE webhook_id = ? AND id = ?",
+		webhookID, id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("ошибка при получении доставки: %w", err)
+	}
+
+	deliveries := []models.WebhookDelivery{*d}
+	if err = loadAttempts(ctx, db, deliveries); err != nil {
+		return nil, err
+	}
+
+	return &deliveries[0], nil
+}
+
+// loadAttempts заполняет журналы попыток доставок одним запросом.
+func loadAttempts(ctx context.Context, db *sql.DB, deliveries []models.WebhookDelivery) (err error) {
+	if len(deliveries) == 0 {
+		return nil
+	}
+
+	ctx, done := observe(ctx, "get_attempts")
+	defer done(&err)
+
+	byID := make(map[int64]*models.WebhookDelivery, len(deliveries))
+	args := make([]interface{}, len(deliveries))
+	for i := range deliveries {
+		deliveries[i].Log = []models.WebhookAttempt{}
+		byID[deliveries[i].ID] = &deliveries[i]
+		args[i] = deliveries[i].ID
+	}
+
+	rows, err := db.QueryContext(ctx, `
+		SELECT delivery_id, attempt, status_code, error, duration_ms, created_at
+		FROM webhook_attempts WHERE delivery_id IN (?`+strings.Repeat(", ?", len(args)-1)+`) ORDER BY id`, args...)
+	if err != nil {
+		return fmt.Errorf("ошибка при получении журнала доставки: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var deliveryID int64
+		var a models.WebhookAttempt
+		var statusCode sql.NullInt64
+		var attemptError sql.NullString
+		if err := rows.Scan(&deliveryID, &a.Attempt, &statusCode, &attemptError, &a.DurationMS, &a.CreatedAt); err != nil {
+			return fmt.Errorf("ошибка при чтении журнала доставки: %w", err)
+		}
+		a.StatusCode = int(statusCode.Int64)
+		a.Error = attemptError.String
+		d := byID[deliveryID]
+		d.Log = append(d.Log, a)
+	}
+
+	return rows.Err()
+}
+
+// RetryDelivery возвращает доставку в очередь с новым набором попыток.
+func RetryDelivery(ctx context.Context, db *sql.DB, webhookID, id int64, now time.Time) (retried bool, err error) {
+	ctx, done := observe(ctx, "retry_delivery")
+	defer done(&err)
+
+	result, err := db.ExecContext(ctx, `
+		UPDATE webhook_deliveries
+		SET status = ?, attempts = 0, next_attempt_at = ?, updated_at = CURRENT_TIMESTAMP
+		WHERE webhook_id = ? AND id = ?`,
+		models.DeliveryPending, now.UnixMilli(), webhookID, id)
+	if err != nil {
+		return false, fmt.Errorf("ошибка при повторе доставки: %w", err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return affected > 0, nil
+}
+
+// ExpiredLinks возвращает до limit ссылок, истекших к моменту now, о которых еще не
+// отправлено событие. expires_at хранится в UTC, поэтому сравнивается в SQL по
+// индексу idx_links_expires_at.
+func ExpiredLinks(ctx context.Context, db *sql.DB, now time.Time, limit int) (links []models.Link, err error) {
+	ctx, done := observe(ctx, "expired_links")
+	defer done(&err)
+
+	rows, err := db.QueryContext(ctx, "SELECT "+linkColumns+`
+		FROM links WHERE expires_at <= ? AND expired_notified = 0
+		ORDER BY expires_at LIMIT ?`, now.UTC(), limit)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при получении истекших ссылок: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		link, err := scanLink(rows)
+		if err != nil {
+			return nil, fmt.Errorf("ошибка при чтении ссылки: %w", err)
+		}
+		links = append(links, *link)
+	}
+
+	return links, rows.Err()
+}
+
+// SetExpiryNotified отмечает, отправлено ли событие об истечении ссылки.
+func SetExpiryNotified(ctx context.Context, db *sql.DB, linkID int64, notified bool) (err error) {
+	ctx, done := observe(ctx, "set_expiry_notified")
+	defer done(&err)
+
+	if _, err = db.ExecContext(ctx, "UPDATE links SET expired_notified = ? WHERE id = ?", notified, linkID); err != nil {
+		return fmt.Errorf("ошибка при обновлении ссылки: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,127 @@
+package geoip
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/oschwald/maxminddb-golang"
+)
+
+const UnknownCountry = "unknown"
+
+type countryRecord struct {
+	Country struct {
+		ISOCode string `maxminddb:"iso_code"`
+	} `maxminddb:"country"`
+}
+
+// Resolver определяет страну по IP с помощью базы в формате MaxMind (.mmdb)
+// и перечитывает файл базы, когда он меняется на диске.
+type Resolver struct {
+	path string
+
+	mu      sync.RWMutex
+	reader  *maxminddb.Reader
+	modTime time.Time
+}
+
+func Open(path string) (*Resolver, error) {
+	r := &Resolver{path: path}
+	if err := r.Reload(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+func (r *Resolver) Reload() error {
+	info, err := os.Stat(r.path)
+	if err != nil {
+		return fmt.Errorf("ошибка при чтении базы GeoIP: %w", err)
+	}
+
+	reader, err := maxminddb.Open(r.path)
+	if err != nil {
+		return fmt.Errorf("ошибка при открытии базы GeoIP: %w", err)
+	}
+
+	r.mu.Lock()
+	old := r.reader
+	r.reader = reader
+	r.modTime = info.ModTime()
+	r.mu.Unlock()
+
+	if old != nil {
+		old.Close()
+	}
+	return nil
+}
+
+// Watch проверяет файл базы с заданным интервалом и перезагружает его при изменении.
+func (r *Resolver) Watch(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			info, err := os.Stat(r.path)
+			if err != nil {
+				slog.Error("Ошибка при проверке базы GeoIP", "path", r.path, "error", err)
+				continue
+			}
+
+			r.mu.RLock()
+			changed := !info.ModTime().Equal(r.modTime)
+			r.mu.RUnlock()
+
+			if !changed {
+				continue
+			}
+			if err := r.Reload(); err != nil {
+				slog.Error("Ошибка при перезагрузке базы GeoIP", "path", r.path, "error", err)
+				continue
+			}
+			slog.Info("База GeoIP перезагружена", "path", r.path)
+		}
+	}
+}
+
+// Country возвращает ISO-код страны в верхнем регистре или UnknownCountry.
+func (r *Resolver) Country(ip net.IP) string {
+	if ip == nil {
+		return UnknownCountry
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if r.reader == nil {
+		return UnknownCountry
+	}
+
+	var record countryRecord
+	if err := r.reader.Lookup(ip, &record); err != nil || record.Country.ISOCode == "" {
+		return UnknownCountry
+	}
+	return strings.ToUpper(record.Country.ISOCode)
+}
+
+func (r *Resolver) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.reader == nil {
+		return nil
+	}
+	err := r.reader.Close()
+	r.reader = nil
+	return err
+}
@@ -0,0 +1,263 @@
+// Package grpcserver реализует gRPC API поверх тех же операций со ссылками, что и
+// HTTP-обработчики.
+package grpcserver
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	pb "tinyurl/api/tinyurl/v1"
+	"tinyurl/internal/apierror"
+	"tinyurl/internal/handlers"
+	"tinyurl/internal/i18n"
+	"tinyurl/internal/logging"
+	"tinyurl/internal/models"
+)
+
+// ErrorDomain - значение ErrorInfo.Domain в ошибках сервиса.
+const ErrorDomain = "tinyurl"
+
+type Service struct {
+	pb.UnimplementedTinyURLServer
+	server *handlers.Server
+}
+
+// New создает gRPC-сервер с сервисом TinyURL. Язык сообщений об ошибках берется из
+// метаданных accept-language, по умолчанию - lang.
+func New(server *handlers.Server, logger *slog.Logger, lang i18n.Lang) *grpc.Server {
+	s := grpc.NewServer(grpc.ChainUnaryInterceptor(logCalls(logger), negotiateLang(lang)))
+	pb.RegisterTinyURLServer(s, &Service{server: server})
+	return s
+}
+
+func (s *Service) Shorten(ctx context.Context, req *pb.ShortenRequest) (*pb.ShortenResponse, error) {
+	shorten := models.ShortenRequest{
+		URL:          req.GetUrl(),
+		Alias:        req.GetAlias(),
+		TTLDays:      int(req.GetTtlDays()),
+		RedirectType: int(req.GetRedirectType()),
+		Passthrough:  req.GetPassthrough(),
+		UTMTemplate:  req.GetUtmTemplate(),
+		UTMApply:     req.GetUtmApply(),
+		Rules:        rulesFromProto(req.GetRules()),
+		Variants:     variantsFromProto(req.GetVariants()),
+		Sticky:       req.GetSticky(),
+		Geo:          req.GetGeo(),
+		Interstitial: req.GetInterstitial(),
+		Domain:       req.GetDomain(),
+	}
+
+	// Без BaseURL адрес домена по умолчанию берется из HTTP-запроса, которого здесь
+	// нет, и ссылка получилась бы относительной.
+	domain, err := s.server.LookupDomain(ctx, shorten.Domain)
+	if err != nil {
+		return nil, toStatus(ctx, err)
+	}
+	if strings.HasPrefix(s.server.ShortURL(domain, ""), "/") {
+		return nil, toStatus(ctx, apierror.New(http.StatusInternalServerError, apierror.BaseURLRequired, i18n.ErrBaseURLRequired))
+	}
+
+	domain, code, err := s.server.CreateLink(ctx, shorten)
+	if err != nil {
+		return nil, toStatus(ctx, err)
+	}
+	return &pb.ShortenResponse{Code: code, ShortUrl: s.server.ShortURL(domain, code)}, nil
+}
+
+func (s *Service) Resolve(ctx context.Context, req *pb.ResolveRequest) (*pb.ResolveResponse, error) {
+	domain, err := s.server.LookupDomain(ctx, req.GetDomain())
+	if err != nil {
+		return nil, toStatus(ctx, err)
+	}
+	link, redirectType, err := s.server.ResolveLink(ctx, domain, req.GetCode())
+	if err != nil {
+		return nil, toStatus(ctx, err)
+	}
+	return &pb.ResolveResponse{Url: link.URL, RedirectType: int32(redirectType)}, nil
+}
+
+func (s *Service) GetStats(ctx context.Context, req *pb.GetStatsRequest) (*pb.GetStatsResponse, error) {
+	domain, err := s.server.LookupDomain(ctx, req.GetDomain())
+	if err != nil {
+		return nil, toStatus(ctx, err)
+	}
+	link, err := s.server.FindLink(ctx, domain, req.GetCode())
+	if err != nil {
+		return nil, toStatus(ctx, err)
+	}
+	stats, err := s.server.LinkStats(ctx, domain, link)
+	if err != nil {
+		return nil, toStatus(ctx, err)
+	}
+
+	resp := &pb.GetStatsResponse{Link: s.linkToProto(link, domain), Breakdown: make(map[string]*pb.Counts)}
+	for dimension, values := range stats.Breakdown {
+		resp.Breakdown[dimension] = &pb.Counts{Hits: values}
+	}
+	return resp, nil
+}
+
+func (s *Service) Update(ctx context.Context, req *pb.UpdateRequest) (*pb.Link, error) {
+	domain, err := s.server.LookupDomain(ctx, req.GetDomain())
+	if err != nil {
+		return nil, toStatus(ctx, err)
+	}
+
+	update := models.LinkUpdate{URL: req.Url, Passthrough: req.Passthrough, Interstitial: req.Interstitial}
+	if req.TtlDays != nil {
+		ttl := int(req.GetTtlDays())
+		update.TTLDays = &ttl
+	}
+	if req.RedirectType != nil {
+		redirectType := int(req.GetRedirectType())
+		update.RedirectType = &redirectType
+	}
+
+	link, err := s.server.UpdateLink(ctx, domain, req.GetCode(), update)
+	if err != nil {
+		return nil, toStatus(ctx, err)
+	}
+	return s.linkToProto(link, domain), nil
+}
+
+func (s *Service) Delete(ctx context.Context, req *pb.DeleteRequest) (*pb.DeleteResponse, error) {
+	domain, err := s.server.LookupDomain(ctx, req.GetDomain())
+	if err != nil {
+		return nil, toStatus(ctx, err)
+	}
+	if err := s.server.DeleteLink(ctx, domain, req.GetCode()); err != nil {
+		return nil, toStatus(ctx, err)
+	}
+	return &pb.DeleteResponse{}, nil
+}
+
+func (s *Service) List(ctx context.Context, req *pb.ListRequest) (*pb.ListResponse, error) {
+	domain, err := s.server.LookupDomain(ctx, req.GetDomain())
+	if err != nil {
+		return nil, toStatus(ctx, err)
+	}
+	links, next, err := s.server.ListLinks(ctx, domain, req.GetSearch(), int(req.GetPageSize()), req.GetPageToken())
+	if err != nil {
+		return nil, toStatus(ctx, err)
+	}
+
+	resp := &pb.ListResponse{Links: make([]*pb.Link, len(links)), NextPageToken: next}
+	for i := range links {
+		resp.Links[i] = s.linkToProto(&links[i], domain)
+	}
+	return resp, nil
+}
+
+var statusCodes = map[int]codes.Code{
+	http.StatusBadRequest: codes.InvalidArgument,
+	http.StatusNotFound:   codes.NotFound,
+	http.StatusConflict:   codes.AlreadyExists,
+}
+
+// toStatus переводит ошибку операции в статус gRPC с деталью ErrorInfo. Внутренние
+// ошибки логируются, клиент получает только код internal_error.
+func toStatus(ctx context.Context, err error) error {
+	var e *apierror.Error
+	if !errors.As(err, &e) {
+		logging.FromContext(ctx).Error(i18n.T(i18n.Default, i18n.ErrInternal), "error", err)
+		e = apierror.New(http.StatusInternalServerError, apierror.Internal, i18n.ErrInternal)
+	}
+
+	code, ok := statusCodes[e.Status]
+	if !ok {
+		code = codes.Internal
+	}
+
+	st := status.New(code, i18n.T(i18n.FromContext(ctx), e.Key, e.Args...))
+	info := &errdetails.ErrorInfo{Reason: string(e.Code), Domain: ErrorDomain}
+	if e.Field != "" {
+		info.Metadata = map[string]string{"field": e.Field}
+	}
+	if detailed, err := st.WithDetails(info); err == nil {
+		st = detailed
+	}
+	return st.Err()
+}
+
+// negotiateLang выбирает язык ответа по метаданным accept-language.
+func negotiateLang(fallback i18n.Lang) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		lang := fallback
+		if md, ok := metadata.FromIncomingContext(ctx); ok {
+			if values := md.Get("accept-language"); len(values) > 0 {
+				lang = i18n.Negotiate(values[0], fallback)
+			}
+		}
+		return handler(i18n.WithLang(ctx, lang), req)
+	}
+}
+
+// logCalls пишет в лог каждый вызов с кодом ответа и длительностью.
+func logCalls(logger *slog.Logger) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		start := time.Now()
+		resp, err := handler(ctx, req)
+		logger.LogAttrs(ctx, slog.LevelInfo, "gRPC-запрос",
+			slog.String("method", info.FullMethod),
+			slog.String("code", status.Code(err).String()),
+			slog.Duration("duration", time.Since(start)))
+		return resp, err
+	}
+}
+
+// linkToProto переводит ссылку в сообщение API. redirect_type - действующий код
+// перенаправления с учетом домена и настроек сервера, как в REST API.
+func (s *Service) linkToProto(link *models.Link, domain *models.Domain) *pb.Link {
+	l := &pb.Link{
+		Code:         link.Code,
+		Domain:       domain.Host,
+		Url:          link.URL,
+		CreatedAt:    timestamppb.New(link.CreatedAt),
+		HitCount:     link.HitCount,
+		RedirectType: int32(s.server.RedirectTypeFor(link, domain)),
+		Passthrough:  link.Passthrough,
+		UtmTemplate:  link.UTMTemplate,
+		Sticky:       link.StickyVariant,
+		Geo:          link.GeoTargets,
+		Interstitial: link.Interstitial,
+	}
+	if link.ExpiresAt != nil {
+		l.ExpiresAt = timestamppb.New(*link.ExpiresAt)
+	}
+	for _, rule := range link.Rules {
+		l.Rules = append(l.Rules, &pb.TargetRule{Name: rule.Name, Os: rule.OS, Device: rule.Device, Bot: rule.Bot, Url: rule.URL})
+	}
+	for _, variant := range link.Variants {
+		l.Variants = append(l.Variants, &pb.Variant{Name: variant.Name, Url: variant.URL, Weight: int32(variant.Weight)})
+	}
+	return l
+}
+
+func rulesFromProto(rules []*pb.TargetRule) []models.TargetRule {
+	var result []models.TargetRule
+	for _, rule := range rules {
+		result = append(result, models.TargetRule{
+			Name: rule.GetName(), OS: rule.GetOs(), Device: rule.GetDevice(), Bot: rule.Bot, URL: rule.GetUrl(),
+		})
+	}
+	return result
+}
+
+func variantsFromProto(variants []*pb.Variant) []models.Variant {
+	var result []models.Variant
+	for _, variant := range variants {
+		result = append(result, models.Variant{Name: variant.GetName(), URL: variant.GetUrl(), Weight: int(variant.GetWeight())})
+	}
+	return result
+}
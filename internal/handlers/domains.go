@@ -0,0 +1,237 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"tinyurl/internal/apierror"
+	"tinyurl/internal/db"
+	"tinyurl/internal/i18n"
+	"tinyurl/internal/models"
+	"tinyurl/internal/utils"
+)
+
+const (
+	defaultCodeLength = 6
+	minCodeLength     = 4
+	maxCodeLength     = 32
+)
+
+var hostPattern = regexp.MustCompile(`^[a-z0-9]([a-z0-9-]*[a-z0-9])?(\.[a-z0-9]([a-z0-9-]*[a-z0-9])?)*(:[0-9]{1,5})?$`)
+
+func defaultDomain() *models.Domain {
+	return &models.Domain{ID: db.DefaultDomainID}
+}
+
+func normalizeHost(host string) string {
+	return strings.TrimSuffix(strings.ToLower(strings.TrimSpace(host)), ".")
+}
+
+// domainForHost находит домен по заголовку Host. Сначала ищется точное совпадение,
+// затем хост без порта; неизвестные хосты относятся к домену по умолчанию.
+func (s *Server) domainForHost(ctx context.Context, host string) (*models.Domain, error) {
+	host = normalizeHost(host)
+	if host == "" {
+		return defaultDomain(), nil
+	}
+
+	domain, err := db.GetDomain(ctx, s.DB, host)
+	if err != nil || domain != nil {
+		return domain, err
+	}
+
+	if hostname, _, err := net.SplitHostPort(host); err == nil {
+		domain, err := db.GetDomain(ctx, s.DB, hostname)
+		if err != nil || domain != nil {
+			return domain, err
+		}
+	}
+
+	return defaultDomain(), nil
+}
+
+// domainForRequest учитывает параметр ?domain= и возвращает nil, если такого домена нет.
+func (s *Server) domainForRequest(r *http.Request) (*models.Domain, error) {
+	if host := r.URL.Query().Get("domain"); host != "" {
+		return db.GetDomain(r.Context(), s.DB, normalizeHost(host))
+	}
+	return s.domainForHost(r.Context(), s.requestHost(r))
+}
+
+func (s *Server) requestHost(r *http.Request) string {
+	_, host := utils.PublicOrigin(r, s.TrustedProxies)
+	return host
+}
+
+func (s *Server) shortURL(r *http.Request, domain *models.Domain, code string) string {
+	return fmt.Sprintf("%s/r/%s", s.publicBaseURL(r, domain), url.PathEscape(code))
+}
+
+// publicBaseURL строит адрес, по которому клиенты открывают короткие ссылки домена.
+// Собственные домены используют свой хост со схемой BaseURL или исходного запроса.
+// Без запроса (r == nil) схемой собственного домена считается https.
+func (s *Server) publicBaseURL(r *http.Request, domain *models.Domain) string {
+	if domain.ID == db.DefaultDomainID {
+		if s.BaseURL != "" || r == nil {
+			return s.BaseURL
+		}
+		return utils.PublicBaseURL(r, s.TrustedProxies)
+	}
+
+	scheme := "https"
+	if r != nil {
+		scheme, _ = utils.PublicOrigin(r, s.TrustedProxies)
+	}
+	if s.BaseURL != "" {
+		if u, err := url.Parse(s.BaseURL); err == nil {
+			scheme = u.Scheme
+		}
+	}
+	return fmt.Sprintf("%s://%s", scheme, domain.Host)
+}
+
+func linkNotFound(w http.ResponseWriter, r *http.Request, domain *models.Domain) {
+	if domain.NotFoundURL != "" {
+		w.Header().Set("Cache-Control", "private, no-store")
+		http.Redirect(w, r, domain.NotFoundURL, http.StatusFound)
+		return
+	}
+	linkMissing(w, r)
+}
+
+func (s *Server) DomainsHandler(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		domains, err := db.ListDomains(r.Context(), s.DB)
+		if err != nil {
+			serverError(w, r, i18n.ErrDomainsLookup, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, domains)
+	case http.MethodPost:
+		var d models.Domain
+		if err := json.NewDecoder(r.Body).Decode(&d); err != nil {
+			writeError(w, r, http.StatusBadRequest, apierror.InvalidJSON, i18n.ErrInvalidJSON)
+			return
+		}
+		d.Host = normalizeHost(d.Host)
+		if !hostPattern.MatchString(d.Host) {
+			invalidField(w, r, apierror.InvalidHost, "host", i18n.ErrInvalidHost)
+			return
+		}
+		if !validDomainSettings(w, r, &d) {
+			return
+		}
+		if err := db.InsertDomain(r.Context(), s.DB, &d); err != nil {
+			if db.IsUniqueError(err) {
+				writeError(w, r, http.StatusConflict, apierror.DomainExists, i18n.ErrDomainExists)
+				return
+			}
+			serverError(w, r, i18n.ErrDatabase, err)
+			return
+		}
+		created, err := db.GetDomain(r.Context(), s.DB, d.Host)
+		if err != nil || created == nil {
+			serverError(w, r, i18n.ErrDatabase, err)
+			return
+		}
+		writeJSON(w, http.StatusCreated, created)
+	default:
+		methodNotAllowed(w, r, http.MethodGet, http.MethodPost)
+	}
+}
+
+func (s *Server) DomainHandler(w http.ResponseWriter, r *http.Request) {
+	host := normalizeHost(pathValue(r, "host", "/domains/"))
+	if host == "" {
+		domainMissing(w, r)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		d, err := db.GetDomain(r.Context(), s.DB, host)
+		if err != nil {
+			serverError(w, r, i18n.ErrDomainLookup, err)
+			return
+		}
+		if d == nil {
+			domainMissing(w, r)
+			return
+		}
+		writeJSON(w, http.StatusOK, d)
+	case http.MethodPut:
+		var d models.Domain
+		if err := json.NewDecoder(r.Body).Decode(&d); err != nil {
+			writeError(w, r, http.StatusBadRequest, apierror.InvalidJSON, i18n.ErrInvalidJSON)
+			return
+		}
+		d.Host = host
+		if !validDomainSettings(w, r, &d) {
+			return
+		}
+		found, err := db.UpdateDomain(r.Context(), s.DB, &d)
+		if err != nil {
+			serverError(w, r, i18n.ErrDatabase, err)
+			return
+		}
+		if !found {
+			domainMissing(w, r)
+			return
+		}
+		updated, err := db.GetDomain(r.Context(), s.DB, host)
+		if err != nil || updated == nil {
+			serverError(w, r, i18n.ErrDatabase, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, updated)
+	case http.MethodDelete:
+		d, err := db.GetDomain(r.Context(), s.DB, host)
+		if err != nil {
+			serverError(w, r, i18n.ErrDatabase, err)
+			return
+		}
+		if d == nil {
+			domainMissing(w, r)
+			return
+		}
+		links, err := db.CountDomainLinks(r.Context(), s.DB, d.ID)
+		if err != nil {
+			serverError(w, r, i18n.ErrDatabase, err)
+			return
+		}
+		if links > 0 {
+			writeError(w, r, http.StatusConflict, apierror.DomainInUse, i18n.ErrDomainInUse)
+			return
+		}
+		if _, err := db.DeleteDomain(r.Context(), s.DB, host); err != nil {
+			serverError(w, r, i18n.ErrDatabase, err)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		methodNotAllowed(w, r, http.MethodGet, http.MethodPut, http.MethodDelete)
+	}
+}
+
+func validDomainSettings(w http.ResponseWriter, r *http.Request, d *models.Domain) bool {
+	if d.RedirectType != 0 && !utils.IsValidRedirectType(d.RedirectType) {
+		invalidField(w, r, apierror.InvalidRedirectType, "redirect_type", i18n.ErrInvalidRedirectType)
+		return false
+	}
+	if d.CodeLength != 0 && (d.CodeLength < minCodeLength || d.CodeLength > maxCodeLength) {
+		invalidField(w, r, apierror.InvalidCodeLength, "code_length", i18n.ErrInvalidCodeLength, minCodeLength, maxCodeLength)
+		return false
+	}
+	if d.NotFoundURL != "" && !utils.IsHTTPURL(d.NotFoundURL) {
+		invalidField(w, r, apierror.InvalidNotFoundURL, "not_found_url", i18n.ErrInvalidNotFoundURL)
+		return false
+	}
+	return true
+}
@@ -0,0 +1,63 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"tinyurl/internal/apierror"
+	"tinyurl/internal/i18n"
+	"tinyurl/internal/logging"
+)
+
+func writeError(w http.ResponseWriter, r *http.Request, status int, code apierror.Code, key i18n.Key, args ...any) {
+	apierror.Write(w, r, apierror.New(status, code, key, args...))
+}
+
+func invalidField(w http.ResponseWriter, r *http.Request, code apierror.Code, field string, key i18n.Key, args ...any) {
+	apierror.Write(w, r, apierror.Invalid(code, field, key, args...))
+}
+
+func notFound(w http.ResponseWriter, r *http.Request) {
+	writeError(w, r, http.StatusNotFound, apierror.NotFound, i18n.ErrNotFound)
+}
+
+func linkMissing(w http.ResponseWriter, r *http.Request) {
+	writeError(w, r, http.StatusNotFound, apierror.LinkNotFound, i18n.ErrLinkNotFound)
+}
+
+func domainMissing(w http.ResponseWriter, r *http.Request) {
+	writeError(w, r, http.StatusNotFound, apierror.DomainNotFound, i18n.ErrDomainNotFound)
+}
+
+func utmTemplateMissing(w http.ResponseWriter, r *http.Request) {
+	writeError(w, r, http.StatusNotFound, apierror.UTMTemplateNotFound, i18n.ErrUTMTemplateNotFound)
+}
+
+func methodNotAllowed(w http.ResponseWriter, r *http.Request, allowed ...string) {
+	w.Header().Set("Allow", strings.Join(allowed, ", "))
+	writeError(w, r, http.StatusMethodNotAllowed, apierror.MethodNotAllowed, i18n.ErrMethodNotAllowed, strings.Join(allowed, ", "))
+}
+
+// serverError логирует причину ошибки с идентификатором запроса и отвечает 500.
+// Подробности ошибки клиенту не передаются.
+func serverError(w http.ResponseWriter, r *http.Request, key i18n.Key, err error) {
+	logging.FromContext(r.Context()).Error(i18n.T(i18n.Default, key), "error", err)
+	writeError(w, r, http.StatusInternalServerError, apierror.Internal, key)
+}
+
+// writeServiceError отдает ошибку общих операций со ссылками: *apierror.Error как
+// есть, остальные ошибки - как внутренние с сообщением key.
+func writeServiceError(w http.ResponseWriter, r *http.Request, key i18n.Key, err error) {
+	var e *apierror.Error
+	if errors.As(err, &e) {
+		apierror.Write(w, r, e)
+		return
+	}
+	serverError(w, r, key, err)
+}
+
+// NotFoundHandler отвечает на запросы к незарегистрированным маршрутам.
+func (s *Server) NotFoundHandler(w http.ResponseWriter, r *http.Request) {
+	notFound(w, r)
+}
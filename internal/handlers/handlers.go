@@ -1,76 +1,87 @@
 package handlers
 
 import (
+	"context"
 	"database/sql"
 	"encoding/json"
-	"fmt"
-	"log"
+	"errors"
 	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
+	"tinyurl/internal/apierror"
 	"tinyurl/internal/db"
+	"tinyurl/internal/i18n"
+	"tinyurl/internal/logging"
+	"tinyurl/internal/metrics"
 	"tinyurl/internal/models"
+	"tinyurl/internal/tracing"
 	"tinyurl/internal/utils"
+	"tinyurl/internal/webhooks"
 )
 
 type Server struct {
-	DB *sql.DB
+	DB                  *sql.DB
+	DefaultRedirectType int
+	GeoIP               CountryResolver
+	// BaseURL - канонический адрес сервиса для коротких ссылок домена по умолчанию.
+	// Если не задан, адрес берется из запроса с учетом TrustedProxies.
+	BaseURL        string
+	TrustedProxies utils.TrustedProxies
+	// Events получает события о создании, изменении, удалении ссылок и переходах.
+	// Если не задан, события не публикуются.
+	Events EventPublisher
+	// IdempotencyTTL - сколько хранятся ответы на запросы с Idempotency-Key.
+	IdempotencyTTL time.Duration
+
+	shuttingDown atomic.Bool
+	// migrated запоминает, что /readyz уже нашел все миграции примененными: пока
+	// сервер работает, схема не откатывается, и проверять ее снова не нужно.
+	migrated atomic.Bool
+	// hits учитывает переходы, которые еще записываются в фоне.
+	hits sync.WaitGroup
 }
 
 func NewServer(db *sql.DB) *Server {
-	return &Server{DB: db}
+	return &Server{DB: db, DefaultRedirectType: http.StatusFound, IdempotencyTTL: DefaultIdempotencyTTL}
 }
 
 func (s *Server) ShortenHandler(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
-		http.Error(w, "Только метод POST разрешен", http.StatusMethodNotAllowed)
+		methodNotAllowed(w, r, http.MethodPost)
 		return
 	}
 
 	var req models.ShortenRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, "Некорректный JSON", http.StatusBadRequest)
-		return
-	}
-
-	if req.URL == "" {
-		http.Error(w, "URL обязателен", http.StatusBadRequest)
+		writeError(w, r, http.StatusBadRequest, apierror.InvalidJSON, i18n.ErrInvalidJSON)
 		return
 	}
 
-	code := req.Alias
-	var err error
-
-	if code == "" {
-		for tries := 0; tries < 5; tries++ {
-			code = utils.GenerateRandomCode(6)
-			if err = db.InsertLink(s.DB, code, req.URL, req.TTLDays); err == nil {
-				break
-			}
-			if !db.IsUniqueError(err) {
-				http.Error(w, "Ошибка базы данных", http.StatusInternalServerError)
-				return
-			}
-		}
+	// Без поля domain ссылка создается в домене хоста запроса, как и переход по ней:
+	// иначе short_url с этим хостом вел бы в другой домен.
+	if req.Domain == "" {
+		domain, err := s.domainForHost(r.Context(), s.requestHost(r))
 		if err != nil {
-			http.Error(w, "Не удалось создать уникальный код, попробуйте снова", http.StatusInternalServerError)
-			return
-		}
-	} else {
-		if err = db.InsertLink(s.DB, code, req.URL, req.TTLDays); err != nil {
-			if db.IsUniqueError(err) {
-				http.Error(w, "Этот алиас уже занят", http.StatusConflict)
-				return
-			}
-			http.Error(w, "Ошибка базы данных", http.StatusInternalServerError)
+			writeServiceError(w, r, i18n.ErrDatabase, err)
 			return
 		}
+		req.Domain = domain.Host
+	}
+
+	domain, code, err := s.CreateLink(r.Context(), req)
+	if err != nil {
+		writeServiceError(w, r, i18n.ErrDatabase, err)
+		return
 	}
+	annotateCode(r, code)
 
-	host := utils.GetHost(r)
 	resp := models.ShortenResponse{
 		Code:     code,
-		ShortURL: fmt.Sprintf("%s/r/%s", host, code),
+		ShortURL: s.shortURL(r, domain, code),
 	}
 
 	w.Header().Set("Content-Type", "application/json")
@@ -78,62 +89,289 @@ func (s *Server) ShortenHandler(w http.ResponseWriter, r *http.Request) {
 }
 
 func (s *Server) RedirectHandler(w http.ResponseWriter, r *http.Request) {
-	code := r.URL.Path[len("/r/"):]
-	if code == "" {
-		http.NotFound(w, r)
+	code, tail, preview, ok := splitRedirectPath(r)
+	if !ok {
+		notFound(w, r)
+		return
+	}
+	annotateCode(r, code)
+
+	query := r.URL.Query()
+	rawQuery := r.URL.RawQuery
+	if _, ok := query[previewParam]; ok {
+		preview = true
+		query.Del(previewParam)
+		rawQuery = query.Encode()
+	}
+
+	domain, err := s.domainForHost(r.Context(), s.requestHost(r))
+	if err != nil {
+		redirectOutcome(r, "error")
+		serverError(w, r, i18n.ErrLinkLookup, err)
 		return
 	}
 
-	link, err := db.GetLink(s.DB, code)
+	link, err := db.GetLinkInDomain(r.Context(), s.DB, domain.ID, code)
 	if err != nil {
-		http.Error(w, "Ошибка при получении ссылки", http.StatusInternalServerError)
+		redirectOutcome(r, "error")
+		serverError(w, r, i18n.ErrLinkLookup, err)
 		return
 	}
 
-	if link == nil {
-		http.NotFound(w, r)
+	if link == nil || (tail != "" && !link.Passthrough) {
+		redirectOutcome(r, "not_found")
+		linkNotFound(w, r, domain)
 		return
 	}
 
 	if link.ExpiresAt != nil && time.Now().After(*link.ExpiresAt) {
-		http.NotFound(w, r)
+		redirectOutcome(r, "expired")
+		linkNotFound(w, r, domain)
+		return
+	}
+
+	if preview {
+		destination, _, err := s.resolveDestination(nil, r, link, tail, rawQuery)
+		if err != nil {
+			redirectOutcome(r, "error")
+			writeResolveError(w, r, err)
+			return
+		}
+		redirectOutcome(r, "preview")
+		renderPreview(w, r, link, destination, false)
+		return
+	}
+
+	destination, dimensions, err := s.resolveDestination(w, r, link, tail, rawQuery)
+	if err != nil {
+		redirectOutcome(r, "error")
+		writeResolveError(w, r, err)
+		return
+	}
+
+	s.recordHit(r, domain, link, dimensions)
+
+	if link.Interstitial {
+		redirectOutcome(r, "interstitial")
+		renderPreview(w, r, link, destination, true)
+		return
+	}
+
+	redirectOutcome(r, "redirect")
+
+	redirectType := s.RedirectTypeFor(link, domain)
+	setRedirectCacheHeaders(w, redirectType)
+	http.Redirect(w, r, destination, redirectType)
+}
+
+var errInvalidPassthrough = errors.New("некорректный путь или параметры запроса")
+
+// resolveDestination выбирает целевой URL для посетителя и измерения статистики перехода.
+// При w == nil вариант A/B-теста не закрепляется за посетителем.
+func (s *Server) resolveDestination(w http.ResponseWriter, r *http.Request, link *models.Link, tail, rawQuery string) (string, map[string]string, error) {
+	destination := link.URL
+	dimensions := map[string]string{}
+	rule := matchRule(link.Rules, r.UserAgent())
+	if len(link.Rules) > 0 {
+		dimensions[db.DimensionRule] = defaultRuleName
+		if rule != nil {
+			destination = rule.URL
+			dimensions[db.DimensionRule] = rule.Name
+		}
+	}
+	country := ""
+	if s.GeoIP != nil {
+		country = s.GeoIP.Country(utils.ClientIP(r, s.TrustedProxies))
+		dimensions[db.DimensionCountry] = country
+	}
+
+	geoMatched := false
+	if rule == nil {
+		if target, ok := link.GeoTargets[country]; ok {
+			destination = target
+			geoMatched = true
+		}
+	}
+
+	if rule == nil && !geoMatched {
+		if variant := pickVariant(w, r, link); variant != nil {
+			destination = variant.URL
+			dimensions[db.DimensionVariant] = variant.Name
+		}
+	}
+
+	if link.UTMAtRedirect && link.UTMTemplate != "" {
+		template, err := db.GetUTMTemplate(r.Context(), s.DB, link.UTMTemplate)
+		if err != nil {
+			return "", nil, err
+		}
+		if template != nil {
+			if destination, err = utils.ApplyQueryParams(destination, template.Params()); err != nil {
+				return "", nil, err
+			}
+		}
+	}
+
+	if link.Passthrough {
+		var err error
+		destination, err = utils.AppendPassthrough(destination, tail, rawQuery)
+		if err != nil {
+			return "", nil, errInvalidPassthrough
+		}
+	}
+
+	if campaign := utils.QueryParam(destination, "utm_campaign"); campaign != "" {
+		dimensions[db.DimensionCampaign] = campaign
+	}
+
+	return destination, dimensions, nil
+}
+
+func writeResolveError(w http.ResponseWriter, r *http.Request, err error) {
+	if errors.Is(err, errInvalidPassthrough) {
+		writeError(w, r, http.StatusBadRequest, apierror.InvalidPassthrough, i18n.ErrInvalidPassthrough)
 		return
 	}
+	serverError(w, r, i18n.ErrLinkLookup, err)
+}
 
+// annotateCode добавляет код ссылки в access-лог и спан запроса.
+func annotateCode(r *http.Request, code string) {
+	logging.AddAttrs(r.Context(), "code", code)
+	tracing.Annotate(r.Context(), tracing.AttrCode.String(code))
+}
+
+func redirectOutcome(r *http.Request, outcome string) {
+	metrics.RedirectResult(outcome)
+	tracing.Annotate(r.Context(), tracing.AttrOutcome.String(outcome))
+}
+
+func shortenOutcome(ctx context.Context, outcome string) {
+	metrics.ShortenResult(outcome)
+	tracing.Annotate(ctx, tracing.AttrOutcome.String(outcome))
+}
+
+func (s *Server) recordHit(r *http.Request, domain *models.Domain, link *models.Link, dimensions map[string]string) {
+	logger := logging.FromContext(r.Context()).With("code", link.Code)
+	// Запись идет после ответа, поэтому отмена запроса не должна ее прерывать.
+	ctx := context.WithoutCancel(r.Context())
+	metrics.HitQueued()
+	s.hits.Add(1)
 	go func() {
-		if err := db.IncrementHitCount(s.DB, code); err != nil {
-			log.Printf("Ошибка при увеличении счетчика для %s: %v", code, err)
+		defer s.hits.Done()
+		defer metrics.HitRecorded()
+		if err := db.IncrementLinkHitCount(ctx, s.DB, link.ID); err != nil {
+			logger.Error("Ошибка при увеличении счетчика", "error", err)
+		}
+		for dimension, value := range dimensions {
+			if err := db.IncrementBreakdown(ctx, s.DB, link.ID, dimension, value); err != nil {
+				logger.Error("Ошибка при обновлении статистики", "dimension", dimension, "error", err)
+			}
+		}
+		if s.Events != nil {
+			clicked := *link
+			clicked.HitCount++
+			s.publish(ctx, webhooks.LinkClicked, domain, &clicked, dimensions)
 		}
 	}()
+}
 
-	http.Redirect(w, r, link.URL, http.StatusFound)
+// WaitHits ждет, пока запишутся все переходы, учтенные в фоне. Вызывается при
+// остановке после завершения HTTP-сервера и перед закрытием базы.
+func (s *Server) WaitHits() {
+	s.hits.Wait()
+}
+
+func splitRedirectPath(r *http.Request) (code, tail string, preview, ok bool) {
+	rest := strings.TrimPrefix(r.URL.EscapedPath(), "/r/")
+	rawCode := rest
+	if i := strings.Index(rest, "/"); i >= 0 {
+		rawCode, tail = rest[:i], rest[i:]
+	}
+
+	code, err := url.PathUnescape(rawCode)
+	if err != nil {
+		return "", "", false, false
+	}
+	if strings.HasSuffix(code, previewSuffix) {
+		code = strings.TrimSuffix(code, previewSuffix)
+		preview = true
+	}
+	if code == "" {
+		return "", "", false, false
+	}
+	return code, tail, preview, true
+}
+
+// RedirectTypeFor возвращает код перенаправления ссылки: собственный, домена или
+// сервера по умолчанию.
+func (s *Server) RedirectTypeFor(link *models.Link, domain *models.Domain) int {
+	if link.RedirectType != 0 {
+		return link.RedirectType
+	}
+	if domain.RedirectType != 0 {
+		return domain.RedirectType
+	}
+	return s.DefaultRedirectType
+}
+
+// setRedirectCacheHeaders запрещает кэширование перенаправлений. Любую ссылку
+// можно изменить через PATCH, поэтому даже постоянное перенаправление браузер и
+// CDN должны перепроверять у сервера: иначе они продолжат вести на старый адрес,
+// а переходы не попадут в статистику и вебхуки.
+func setRedirectCacheHeaders(w http.ResponseWriter, redirectType int) {
+	if utils.IsPermanentRedirect(redirectType) {
+		w.Header().Set("Cache-Control", "private, max-age=0, must-revalidate")
+		return
+	}
+	w.Header().Set("Cache-Control", "private, no-store")
 }
 
 func (s *Server) StatsHandler(w http.ResponseWriter, r *http.Request) {
-	code := r.URL.Path[len("/stats/"):]
+	code := pathValue(r, "code", "/stats/")
 	if code == "" {
-		http.NotFound(w, r)
+		if r.URL.Query().Get("group_by") == db.DimensionCampaign {
+			s.CampaignStatsHandler(w, r)
+			return
+		}
+		notFound(w, r)
 		return
 	}
+	annotateCode(r, code)
 
-	link, err := db.GetLink(s.DB, code)
+	domain, err := s.domainForRequest(r)
 	if err != nil {
-		http.Error(w, "Ошибка при получении статистики", http.StatusInternalServerError)
+		serverError(w, r, i18n.ErrStatsLookup, err)
 		return
 	}
 
-	if link == nil {
-		http.NotFound(w, r)
+	if domain == nil {
+		domainMissing(w, r)
 		return
 	}
 
-	stats := models.StatsResponse{
-		URL:       link.URL,
-		CreatedAt: link.CreatedAt,
-		ExpiresAt: link.ExpiresAt,
-		HitCount:  link.HitCount,
+	link, err := s.FindLink(r.Context(), domain, code)
+	if err != nil {
+		writeServiceError(w, r, i18n.ErrStatsLookup, err)
+		return
+	}
+
+	stats, err := s.LinkStats(r.Context(), domain, link)
+	if err != nil {
+		serverError(w, r, i18n.ErrStatsLookup, err)
+		return
 	}
 
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(stats)
 }
+
+func (s *Server) CampaignStatsHandler(w http.ResponseWriter, r *http.Request) {
+	stats, err := db.GetCampaignStats(r.Context(), s.DB)
+	if err != nil {
+		serverError(w, r, i18n.ErrStatsLookup, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, stats)
+}
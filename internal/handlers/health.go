@@ -0,0 +1,89 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"time"
+
+	"tinyurl/internal/db"
+	"tinyurl/internal/i18n"
+	"tinyurl/internal/logging"
+	"tinyurl/internal/version"
+)
+
+const readinessTimeout = 2 * time.Second
+
+type readinessResponse struct {
+	Status string            `json:"status"`
+	Checks map[string]string `json:"checks"`
+}
+
+// BeginShutdown переводит сервер в состояние остановки: /readyz начинает отвечать 503,
+// чтобы балансировщик перестал направлять новые запросы.
+func (s *Server) BeginShutdown() {
+	s.shuttingDown.Store(true)
+}
+
+// Drain начинает остановку и ждет delay, прежде чем сервер закроет соединения.
+// За это время проверки /readyz успевают получить 503, и балансировщик убирает
+// экземпляр из ротации, пока тот еще принимает запросы.
+func (s *Server) Drain(delay time.Duration) {
+	s.BeginShutdown()
+	time.Sleep(delay)
+}
+
+func (s *Server) HealthHandler(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+}
+
+func (s *Server) ReadyHandler(w http.ResponseWriter, r *http.Request) {
+	resp := readinessResponse{Status: "ok", Checks: map[string]string{
+		"database":   "ok",
+		"migrations": "ok",
+		"shutdown":   "ok",
+	}}
+
+	lang := i18n.FromContext(r.Context())
+	if s.shuttingDown.Load() {
+		resp.Checks["shutdown"] = i18n.T(lang, i18n.ReadyShuttingDown)
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), readinessTimeout)
+	defer cancel()
+
+	// Причины сбоев пишутся в журнал, а пробам отдается только итог проверки.
+	logger := logging.FromContext(ctx)
+	if err := s.DB.PingContext(ctx); err != nil {
+		logger.Error(i18n.T(i18n.Default, i18n.ReadyCheckFailed), "check", "database", "error", err)
+		resp.Checks["database"] = i18n.T(lang, i18n.ReadyCheckFailed)
+		resp.Checks["migrations"] = i18n.T(lang, i18n.ReadyNotChecked)
+	} else if !s.migrated.Load() {
+		pending, err := db.PendingMigrations(ctx, s.DB)
+		switch {
+		case err != nil:
+			logger.Error(i18n.T(i18n.Default, i18n.ReadyCheckFailed), "check", "migrations", "error", err)
+			resp.Checks["migrations"] = i18n.T(lang, i18n.ReadyCheckFailed)
+		case len(pending) > 0:
+			resp.Checks["migrations"] = i18n.T(lang, i18n.ReadyMigrationsPending, strings.Join(pending, ", "))
+		default:
+			s.migrated.Store(true)
+		}
+	}
+
+	status := http.StatusOK
+	for _, result := range resp.Checks {
+		if result != "ok" {
+			resp.Status = "unavailable"
+			status = http.StatusServiceUnavailable
+			break
+		}
+	}
+
+	w.Header().Set("Cache-Control", "no-store")
+	writeJSON(w, status, resp)
+}
+
+func (s *Server) VersionHandler(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, version.Get())
+}
@@ -0,0 +1,176 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"time"
+
+	"tinyurl/internal/apierror"
+	"tinyurl/internal/db"
+	"tinyurl/internal/i18n"
+	"tinyurl/internal/logging"
+	"tinyurl/internal/models"
+	"tinyurl/internal/openapi"
+)
+
+const (
+	// IdempotencyKeyHeader - заголовок с ключом идемпотентности запроса создания.
+	IdempotencyKeyHeader = "Idempotency-Key"
+	// IdempotentReplayedHeader помечает ответ, повторенный по ключу.
+	IdempotentReplayedHeader = "Idempotent-Replayed"
+
+	// DefaultIdempotencyTTL - сколько хранится ответ по ключу, если
+	// Server.IdempotencyTTL не задан.
+	DefaultIdempotencyTTL = 24 * time.Hour
+
+	// idempotencyLease - на сколько ключ занимается на время выполнения запроса.
+	// Если сервер упал, не дождавшись ответа, повтор занимает ключ после истечения
+	// резерва, а не ждет весь IdempotencyTTL.
+	idempotencyLease = time.Minute
+
+	maxIdempotencyKeyLength = 255
+)
+
+var idempotencyKeyHeader = openapi.Parameter{
+	Name: IdempotencyKeyHeader, In: "header", Schema: &openapi.Schema{Type: "string"},
+	Description: "Ключ идемпотентности: повтор запроса с тем же ключом и телом возвращает исходный ответ",
+}
+
+// Idempotent делает запрос POST с заголовком Idempotency-Key идемпотентным.
+// Первый запрос с ключом выполняется и его ответ сохраняется на IdempotencyTTL;
+// повтор с тем же телом получает сохраненный ответ, повтор с другим телом - 422.
+// Пока первый запрос выполняется, повтор получает 409, а если ответа нет дольше
+// idempotencyLease, ключ занимает повтор. Ответы 5xx не сохраняются, чтобы запрос можно было повторить. Остальные
+// запросы передаются next без изменений.
+func (s *Server) Idempotent(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		key, ok := r.Header[IdempotencyKeyHeader]
+		if r.Method != http.MethodPost || !ok {
+			next(w, r)
+			return
+		}
+		if len(key) != 1 || !validIdempotencyKey(key[0]) {
+			writeError(w, r, http.StatusBadRequest, apierror.InvalidIdempotencyKey, i18n.ErrIdempotencyKey, maxIdempotencyKeyLength)
+			return
+		}
+
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			writeError(w, r, http.StatusBadRequest, apierror.InvalidJSON, i18n.ErrInvalidJSON)
+			return
+		}
+		r.Body = io.NopCloser(bytes.NewReader(body))
+
+		ttl := s.IdempotencyTTL
+		if ttl <= 0 {
+			ttl = DefaultIdempotencyTTL
+		}
+		now := time.Now()
+		req := models.IdempotentRequest{
+			Scope:       r.Method + " " + r.URL.Path,
+			Key:         key[0],
+			RequestHash: requestHash(r, s.requestHost(r), body),
+			ExpiresAt:   now.Add(idempotencyLease),
+		}
+		reservedUntil := req.ExpiresAt
+
+		existing, err := db.ReserveIdempotencyKey(r.Context(), s.DB, req, now)
+		if err != nil {
+			serverError(w, r, i18n.ErrDatabase, err)
+			return
+		}
+		if existing != nil {
+			replayIdempotent(w, r, existing, req.RequestHash)
+			return
+		}
+
+		rec := &responseRecorder{ResponseWriter: w, status: http.StatusOK}
+		next(rec, r)
+
+		// Запрос выполнен, даже если клиент уже отключился: ответ сохраняется
+		// или ключ освобождается независимо от контекста запроса.
+		ctx := context.WithoutCancel(r.Context())
+		if rec.status >= http.StatusInternalServerError {
+			if err := db.ReleaseIdempotencyKey(ctx, s.DB, req.Scope, req.Key, reservedUntil); err != nil {
+				logging.FromContext(ctx).Error(i18n.T(i18n.Default, i18n.ErrDatabase), "error", err)
+			}
+			return
+		}
+		req.Status = rec.status
+		req.ContentType = rec.Header().Get("Content-Type")
+		req.Body = rec.body.Bytes()
+		req.ExpiresAt = time.Now().Add(ttl)
+		if err := db.SaveIdempotentResponse(ctx, s.DB, req, reservedUntil); err != nil {
+			logging.FromContext(ctx).Error(i18n.T(i18n.Default, i18n.ErrDatabase), "error", err)
+		}
+	}
+}
+
+// replayIdempotent отвечает на повтор запроса с уже использованным ключом.
+func replayIdempotent(w http.ResponseWriter, r *http.Request, saved *models.IdempotentRequest, hash string) {
+	switch {
+	case saved.RequestHash != hash:
+		writeError(w, r, http.StatusUnprocessableEntity, apierror.IdempotencyKeyReused, i18n.ErrIdempotencyReused)
+	case saved.Status == 0:
+		writeError(w, r, http.StatusConflict, apierror.IdempotencyInProgress, i18n.ErrIdempotencyBusy)
+	default:
+		if saved.ContentType != "" {
+			w.Header().Set("Content-Type", saved.ContentType)
+		}
+		w.Header().Set(IdempotentReplayedHeader, "true")
+		w.WriteHeader(saved.Status)
+		w.Write(saved.Body)
+	}
+}
+
+// validIdempotencyKey допускает от 1 до 255 печатных символов ASCII.
+func validIdempotencyKey(key string) bool {
+	if key == "" || len(key) > maxIdempotencyKeyLength {
+		return false
+	}
+	for i := 0; i < len(key); i++ {
+		if key[i] < 0x20 || key[i] > 0x7e {
+			return false
+		}
+	}
+	return true
+}
+
+// requestHash - отпечаток запроса: тот же ключ с другим отпечатком означает
+// повторное использование ключа для другого запроса. Хост входит в отпечаток:
+// от него зависят домен ссылки, если поле domain не передано, и short_url в ответе.
+func requestHash(r *http.Request, host string, body []byte) string {
+	h := sha256.New()
+	for _, part := range []string{r.Method, host, r.URL.RequestURI()} {
+		io.WriteString(h, part)
+		h.Write([]byte{0})
+	}
+	h.Write(body)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// responseRecorder передает ответ клиенту и копирует его для сохранения.
+type responseRecorder struct {
+	http.ResponseWriter
+	status      int
+	wroteHeader bool
+	body        bytes.Buffer
+}
+
+func (r *responseRecorder) WriteHeader(status int) {
+	if !r.wroteHeader {
+		r.status = status
+		r.wroteHeader = true
+	}
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *responseRecorder) Write(p []byte) (int, error) {
+	r.wroteHeader = true
+	r.body.Write(p)
+	return r.ResponseWriter.Write(p)
+}
@@ -0,0 +1,137 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"tinyurl/internal/apierror"
+	"tinyurl/internal/i18n"
+	"tinyurl/internal/models"
+)
+
+// Обработчики /api/v1 для управления ссылками: список, изменение, удаление и
+// просмотр цели без учета перехода.
+
+// requestDomain возвращает домен из параметра domain или заголовка Host; при
+// ошибке ответ уже записан.
+func (s *Server) requestDomain(w http.ResponseWriter, r *http.Request, key i18n.Key) *models.Domain {
+	domain, err := s.domainForRequest(r)
+	if err != nil {
+		serverError(w, r, key, err)
+		return nil
+	}
+	if domain == nil {
+		domainMissing(w, r)
+		return nil
+	}
+	return domain
+}
+
+func (s *Server) ListLinksHandler(w http.ResponseWriter, r *http.Request) {
+	domain := s.requestDomain(w, r, i18n.ErrLinkLookup)
+	if domain == nil {
+		return
+	}
+
+	query := r.URL.Query()
+	pageSize := 0
+	if raw := query.Get("page_size"); raw != "" {
+		size, err := strconv.Atoi(raw)
+		if err != nil {
+			invalidField(w, r, apierror.InvalidPageSize, "page_size", i18n.ErrInvalidPageSize)
+			return
+		}
+		pageSize = size
+	}
+
+	links, next, err := s.ListLinks(r.Context(), domain, query.Get("search"), pageSize, query.Get("page_token"))
+	if err != nil {
+		writeServiceError(w, r, i18n.ErrLinkLookup, err)
+		return
+	}
+
+	resp := models.LinkList{Links: make([]models.LinkSummary, len(links)), NextPageToken: next}
+	for i, link := range links {
+		resp.Links[i] = models.LinkSummary{
+			Code:      link.Code,
+			Domain:    domain.Host,
+			ShortURL:  s.shortURL(r, domain, link.Code),
+			URL:       link.URL,
+			CreatedAt: link.CreatedAt,
+			ExpiresAt: link.ExpiresAt,
+			HitCount:  link.HitCount,
+		}
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// UpdateLinkHandler меняет только переданные поля ссылки и возвращает ее в том же
+// виде, что и GET /links/{code}.
+func (s *Server) UpdateLinkHandler(w http.ResponseWriter, r *http.Request) {
+	code := r.PathValue("code")
+	annotateCode(r, code)
+
+	var update models.LinkUpdate
+	if err := json.NewDecoder(r.Body).Decode(&update); err != nil {
+		writeError(w, r, http.StatusBadRequest, apierror.InvalidJSON, i18n.ErrInvalidJSON)
+		return
+	}
+
+	domain := s.requestDomain(w, r, i18n.ErrLinkLookup)
+	if domain == nil {
+		return
+	}
+
+	link, err := s.UpdateLink(r.Context(), domain, code, update)
+	if err != nil {
+		writeServiceError(w, r, i18n.ErrDatabase, err)
+		return
+	}
+
+	stats, err := s.LinkStats(r.Context(), domain, link)
+	if err != nil {
+		serverError(w, r, i18n.ErrStatsLookup, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, stats)
+}
+
+func (s *Server) DeleteLinkHandler(w http.ResponseWriter, r *http.Request) {
+	code := r.PathValue("code")
+	annotateCode(r, code)
+
+	domain := s.requestDomain(w, r, i18n.ErrLinkLookup)
+	if domain == nil {
+		return
+	}
+
+	if err := s.DeleteLink(r.Context(), domain, code); err != nil {
+		writeServiceError(w, r, i18n.ErrDatabase, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ResolveHandler показывает, куда ведет ссылка, не засчитывая переход. Правила,
+// варианты и гео не применяются: возвращается основной URL ссылки.
+func (s *Server) ResolveHandler(w http.ResponseWriter, r *http.Request) {
+	code := r.PathValue("code")
+	annotateCode(r, code)
+
+	domain := s.requestDomain(w, r, i18n.ErrLinkLookup)
+	if domain == nil {
+		return
+	}
+
+	link, redirectType, err := s.ResolveLink(r.Context(), domain, code)
+	if err != nil {
+		writeServiceError(w, r, i18n.ErrLinkLookup, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, models.ResolveResponse{
+		URL:          link.URL,
+		ShortURL:     s.shortURL(r, domain, link.Code),
+		RedirectType: redirectType,
+	})
+}
@@ -0,0 +1,363 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"tinyurl/internal/apierror"
+	"tinyurl/internal/db"
+	"tinyurl/internal/i18n"
+	"tinyurl/internal/logging"
+	"tinyurl/internal/metrics"
+	"tinyurl/internal/models"
+	"tinyurl/internal/utils"
+	"tinyurl/internal/webhooks"
+)
+
+// Операции со ссылками, общие для HTTP- и gRPC-API. Ошибки, которые нужно показать
+// клиенту, возвращаются как *apierror.Error; остальные ошибки - внутренние.
+
+const (
+	defaultPageSize = 50
+	maxPageSize     = 1000
+)
+
+// isValidAlias проверяет, что алиас целиком станет кодом в пути /r/{code}: в нем нет
+// разделителей пути и запроса, экранирования и пробельных символов, а окончание
+// previewSuffix не переключает переход на предпросмотр.
+func isValidAlias(alias string) bool {
+	if alias == "." || alias == ".." || strings.HasSuffix(alias, previewSuffix) {
+		return false
+	}
+	return !strings.ContainsFunc(alias, func(r rune) bool {
+		return strings.ContainsRune(`/?#%\`, r) || unicode.IsSpace(r) || unicode.IsControl(r)
+	})
+}
+
+// CreateLink проверяет запрос на сокращение и сохраняет ссылку. Возвращает домен
+// ссылки и ее код.
+func (s *Server) CreateLink(ctx context.Context, req models.ShortenRequest) (*models.Domain, string, error) {
+	if req.URL == "" {
+		return nil, "", apierror.Invalid(apierror.URLRequired, "url", i18n.ErrURLRequired)
+	}
+	if !utils.IsHTTPURL(req.URL) {
+		return nil, "", apierror.Invalid(apierror.InvalidURL, "url", i18n.ErrInvalidURL)
+	}
+
+	if req.RedirectType != 0 && !utils.IsValidRedirectType(req.RedirectType) {
+		return nil, "", apierror.Invalid(apierror.InvalidRedirectType, "redirect_type", i18n.ErrInvalidRedirectType)
+	}
+
+	domain := defaultDomain()
+	if req.Domain != "" {
+		found, err := db.GetDomain(ctx, s.DB, normalizeHost(req.Domain))
+		if err != nil {
+			return nil, "", err
+		}
+		if found == nil {
+			return nil, "", apierror.Invalid(apierror.DomainNotFound, "domain", i18n.ErrDomainNotFound)
+		}
+		domain = found
+	}
+
+	if err := validateRules(req.Rules); err != nil {
+		return nil, "", err
+	}
+
+	if err := validateVariants(req.Variants); err != nil {
+		return nil, "", err
+	}
+
+	if req.Sticky && len(req.Variants) == 0 {
+		return nil, "", apierror.Invalid(apierror.StickyWithoutVariant, "sticky", i18n.ErrStickyWithoutVariants)
+	}
+
+	geoTargets, err := normalizeGeoTargets(req.Geo)
+	if err != nil {
+		return nil, "", err
+	}
+
+	opts := models.LinkOptions{
+		DomainID:      domain.ID,
+		RedirectType:  req.RedirectType,
+		Passthrough:   req.Passthrough,
+		Rules:         req.Rules,
+		Variants:      req.Variants,
+		StickyVariant: req.Sticky,
+		GeoTargets:    geoTargets,
+		Interstitial:  req.Interstitial,
+	}
+	destination := req.URL
+
+	if req.UTMTemplate != "" {
+		template, err := db.GetUTMTemplate(ctx, s.DB, req.UTMTemplate)
+		if err != nil {
+			return nil, "", err
+		}
+		if template == nil {
+			return nil, "", apierror.Invalid(apierror.UTMTemplateNotFound, "utm_template", i18n.ErrUTMTemplateNotFound)
+		}
+		opts.UTMTemplate = template.Name
+
+		switch req.UTMApply {
+		case "", models.UTMApplyCreate:
+			destination, err = utils.ApplyQueryParams(destination, template.Params())
+			if err != nil {
+				return nil, "", apierror.Invalid(apierror.InvalidURL, "url", i18n.ErrInvalidURL)
+			}
+			for i := range opts.Rules {
+				opts.Rules[i].URL, err = utils.ApplyQueryParams(opts.Rules[i].URL, template.Params())
+				if err != nil {
+					return nil, "", apierror.Invalid(apierror.InvalidURL, fmt.Sprintf("rules[%d].url", i), i18n.ErrInvalidRuleURL)
+				}
+			}
+			for i := range opts.Variants {
+				opts.Variants[i].URL, err = utils.ApplyQueryParams(opts.Variants[i].URL, template.Params())
+				if err != nil {
+					return nil, "", apierror.Invalid(apierror.InvalidURL, fmt.Sprintf("variants[%d].url", i), i18n.ErrInvalidVariantURL)
+				}
+			}
+			for country, target := range opts.GeoTargets {
+				opts.GeoTargets[country], err = utils.ApplyQueryParams(target, template.Params())
+				if err != nil {
+					return nil, "", apierror.Invalid(apierror.InvalidURL, "geo."+country, i18n.ErrInvalidGeoURL)
+				}
+			}
+		case models.UTMApplyRedirect:
+			opts.UTMAtRedirect = true
+		default:
+			return nil, "", apierror.Invalid(apierror.InvalidUTMApply, "utm_apply", i18n.ErrInvalidUTMApply)
+		}
+	} else if req.UTMApply != "" {
+		return nil, "", apierror.Invalid(apierror.InvalidUTMApply, "utm_apply", i18n.ErrUTMApplyWithoutTmpl)
+	}
+
+	code := req.Alias
+	if code != "" && !isValidAlias(code) {
+		return nil, "", apierror.Invalid(apierror.InvalidAlias, "alias", i18n.ErrInvalidAlias)
+	}
+
+	if code == "" {
+		codeLength := defaultCodeLength
+		if domain.CodeLength > 0 {
+			codeLength = domain.CodeLength
+		}
+		for tries := 0; tries < 5; tries++ {
+			code = utils.GenerateRandomCode(codeLength)
+			if err = db.InsertLinkWithOptions(ctx, s.DB, code, destination, req.TTLDays, opts); err == nil {
+				break
+			}
+			if !db.IsUniqueError(err) {
+				shortenOutcome(ctx, "error")
+				return nil, "", err
+			}
+			metrics.CodeCollision()
+		}
+		if err != nil {
+			shortenOutcome(ctx, "error")
+			logging.FromContext(ctx).Error("Не удалось создать уникальный код", "error", err)
+			return nil, "", apierror.New(http.StatusInternalServerError, apierror.CodeGenerationFailed, i18n.ErrCodeGeneration)
+		}
+	} else {
+		if err = db.InsertLinkWithOptions(ctx, s.DB, code, destination, req.TTLDays, opts); err != nil {
+			if db.IsUniqueError(err) {
+				shortenOutcome(ctx, "alias_taken")
+				return nil, "", &apierror.Error{Status: http.StatusConflict, Code: apierror.AliasTaken, Field: "alias", Key: i18n.ErrAliasTaken}
+			}
+			shortenOutcome(ctx, "error")
+			return nil, "", err
+		}
+	}
+
+	shortenOutcome(ctx, "created")
+	if s.Events != nil {
+		if link, err := db.GetLinkInDomain(ctx, s.DB, domain.ID, code); err == nil && link != nil {
+			s.publish(ctx, webhooks.LinkCreated, domain, link, nil)
+		}
+	}
+	return domain, code, nil
+}
+
+// LookupDomain возвращает домен по имени хоста; пустое имя - домен по умолчанию.
+func (s *Server) LookupDomain(ctx context.Context, host string) (*models.Domain, error) {
+	if host == "" {
+		return defaultDomain(), nil
+	}
+	domain, err := db.GetDomain(ctx, s.DB, normalizeHost(host))
+	if err != nil {
+		return nil, err
+	}
+	if domain == nil {
+		return nil, apierror.New(http.StatusNotFound, apierror.DomainNotFound, i18n.ErrDomainNotFound)
+	}
+	return domain, nil
+}
+
+// FindLink возвращает ссылку домена, в том числе просроченную.
+func (s *Server) FindLink(ctx context.Context, domain *models.Domain, code string) (*models.Link, error) {
+	link, err := db.GetLinkInDomain(ctx, s.DB, domain.ID, code)
+	if err != nil {
+		return nil, err
+	}
+	if link == nil {
+		return nil, apierror.New(http.StatusNotFound, apierror.LinkNotFound, i18n.ErrLinkNotFound)
+	}
+	return link, nil
+}
+
+// ResolveLink возвращает действующую ссылку и тип перенаправления для нее. Переход
+// в статистике не учитывается.
+func (s *Server) ResolveLink(ctx context.Context, domain *models.Domain, code string) (*models.Link, int, error) {
+	link, err := s.FindLink(ctx, domain, code)
+	if err != nil {
+		return nil, 0, err
+	}
+	if link.ExpiresAt != nil && time.Now().After(*link.ExpiresAt) {
+		return nil, 0, apierror.New(http.StatusNotFound, apierror.LinkNotFound, i18n.ErrLinkNotFound)
+	}
+	return link, s.RedirectTypeFor(link, domain), nil
+}
+
+// LinkStats собирает статистику ссылки домена.
+func (s *Server) LinkStats(ctx context.Context, domain *models.Domain, link *models.Link) (*models.StatsResponse, error) {
+	breakdown, err := db.GetBreakdown(ctx, s.DB, link.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.StatsResponse{
+		URL:          link.URL,
+		CreatedAt:    link.CreatedAt,
+		ExpiresAt:    link.ExpiresAt,
+		HitCount:     link.HitCount,
+		RedirectType: s.RedirectTypeFor(link, domain),
+		Passthrough:  link.Passthrough,
+		UTMTemplate:  link.UTMTemplate,
+		Breakdown:    breakdown,
+		Rules:        link.Rules,
+		Variants:     link.Variants,
+		Sticky:       link.StickyVariant,
+		Geo:          link.GeoTargets,
+		Interstitial: link.Interstitial,
+		Domain:       domain.Host,
+	}, nil
+}
+
+// UpdateLink применяет изменение к ссылке домена и возвращает ее новое состояние.
+func (s *Server) UpdateLink(ctx context.Context, domain *models.Domain, code string, update models.LinkUpdate) (*models.Link, error) {
+	if update.URL != nil && *update.URL == "" {
+		return nil, apierror.Invalid(apierror.URLRequired, "url", i18n.ErrURLRequired)
+	}
+	if update.URL != nil && !utils.IsHTTPURL(*update.URL) {
+		return nil, apierror.Invalid(apierror.InvalidURL, "url", i18n.ErrInvalidURL)
+	}
+	if update.TTLDays != nil && *update.TTLDays < 0 {
+		return nil, apierror.Invalid(apierror.InvalidTTL, "ttl_days", i18n.ErrInvalidTTL)
+	}
+	if update.RedirectType != nil && *update.RedirectType != 0 && !utils.IsValidRedirectType(*update.RedirectType) {
+		return nil, apierror.Invalid(apierror.InvalidRedirectType, "redirect_type", i18n.ErrInvalidRedirectType)
+	}
+
+	link, err := s.FindLink(ctx, domain, code)
+	if err != nil {
+		return nil, err
+	}
+
+	if update.URL != nil {
+		link.URL = *update.URL
+	}
+	if update.TTLDays != nil {
+		link.ExpiresAt = nil
+		if *update.TTLDays > 0 {
+			expires := time.Now().AddDate(0, 0, *update.TTLDays)
+			link.ExpiresAt = &expires
+		}
+	}
+	if update.RedirectType != nil {
+		link.RedirectType = *update.RedirectType
+	}
+	if update.Passthrough != nil {
+		link.Passthrough = *update.Passthrough
+	}
+	if update.Interstitial != nil {
+		link.Interstitial = *update.Interstitial
+	}
+
+	if err := db.UpdateLink(ctx, s.DB, link); err != nil {
+		return nil, err
+	}
+	// Новый срок действия - повод снова сообщить об истечении.
+	if update.TTLDays != nil {
+		if err := db.SetExpiryNotified(ctx, s.DB, link.ID, false); err != nil {
+			return nil, err
+		}
+	}
+	s.publish(ctx, webhooks.LinkUpdated, domain, link, nil)
+	return link, nil
+}
+
+// DeleteLink удаляет ссылку домена вместе со статистикой.
+func (s *Server) DeleteLink(ctx context.Context, domain *models.Domain, code string) error {
+	link, err := s.FindLink(ctx, domain, code)
+	if err != nil {
+		return err
+	}
+
+	deleted, err := db.DeleteLink(ctx, s.DB, link.ID)
+	if err != nil {
+		return err
+	}
+	if !deleted {
+		return apierror.New(http.StatusNotFound, apierror.LinkNotFound, i18n.ErrLinkNotFound)
+	}
+	s.publish(ctx, webhooks.LinkDeleted, domain, link, nil)
+	return nil
+}
+
+// ListLinks возвращает страницу ссылок домена и токен следующей страницы. Пустой
+// токен означает, что страниц больше нет; непустой search отбирает ссылки по
+// подстроке кода или URL.
+func (s *Server) ListLinks(ctx context.Context, domain *models.Domain, search string, pageSize int, pageToken string) ([]models.Link, string, error) {
+	if pageSize < 0 {
+		return nil, "", apierror.Invalid(apierror.InvalidPageSize, "page_size", i18n.ErrInvalidPageSize)
+	}
+	filter := models.LinkFilter{DomainID: domain.ID, Search: search, Limit: pageSize}
+	if filter.Limit == 0 {
+		filter.Limit = defaultPageSize
+	}
+	if filter.Limit > maxPageSize {
+		filter.Limit = maxPageSize
+	}
+	if pageToken != "" {
+		id, err := strconv.ParseInt(pageToken, 10, 64)
+		if err != nil || id <= 0 {
+			return nil, "", apierror.Invalid(apierror.InvalidPageToken, "page_token", i18n.ErrInvalidPageToken)
+		}
+		filter.AfterID = id
+	}
+
+	// Лишняя ссылка показывает, есть ли следующая страница.
+	filter.Limit++
+	links, err := db.ListLinks(ctx, s.DB, filter)
+	if err != nil {
+		return nil, "", err
+	}
+
+	next := ""
+	if len(links) == filter.Limit {
+		links = links[:len(links)-1]
+		next = strconv.FormatInt(links[len(links)-1].ID, 10)
+	}
+	return links, next, nil
+}
+
+// ShortURL возвращает короткую ссылку вне HTTP-запроса. Для домена по умолчанию
+// адрес известен только при заданном BaseURL, иначе возвращается путь /r/<code>.
+func (s *Server) ShortURL(domain *models.Domain, code string) string {
+	return s.shortURL(nil, domain, code)
+}
@@ -0,0 +1,95 @@
+package handlers
+
+import (
+	"html/template"
+	"net/http"
+	"time"
+
+	"tinyurl/internal/i18n"
+	"tinyurl/internal/logging"
+	"tinyurl/internal/models"
+)
+
+const (
+	previewSuffix = "+"
+	previewParam  = "preview"
+)
+
+// previewTemplates содержит страницу предпросмотра для каждого языка: функция t
+// шаблона переводит ключи каталога на язык шаблона.
+var previewTemplates = func() map[i18n.Lang]*template.Template {
+	templates := make(map[i18n.Lang]*template.Template)
+	for _, lang := range i18n.Languages() {
+		templates[lang] = template.Must(template.New("preview").Funcs(template.FuncMap{
+			"t": func(key i18n.Key, args ...any) string { return i18n.T(lang, key, args...) },
+		}).Parse(previewHTML))
+	}
+	return templates
+}()
+
+const previewHTML = `<!DOCTYPE html>
+<html lang="{{.Lang}}">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<meta name="robots" content="noindex">
+<title>{{t "preview.title" .Code}}</title>
+<style>
+body { font-family: system-ui, sans-serif; max-width: 40rem; margin: 3rem auto; padding: 0 1rem; color: #222; }
+.destination { word-break: break-all; padding: .75rem; background: #f4f4f4; border-radius: .25rem; }
+.notice { padding: .75rem; border-left: 4px solid #e0a800; background: #fff8e1; }
+dl { display: grid; grid-template-columns: max-content 1fr; gap: .25rem 1rem; }
+dt { color: #666; }
+.continue { display: inline-block; margin-top: 1rem; padding: .5rem 1rem; background: #0b5ed7; color: #fff; border-radius: .25rem; text-decoration: none; }
+</style>
+</head>
+<body>
+<h1>{{t "preview.heading" .Code}}</h1>
+<p>{{t "preview.leads_to"}}</p>
+<p class="destination">{{.Destination}}</p>
+<dl>
+<dt>{{t "preview.created"}}</dt><dd>{{.CreatedAt}}</dd>
+{{if .ExpiresAt}}<dt>{{t "preview.expires"}}</dt><dd>{{.ExpiresAt}}</dd>{{end}}
+<dt>{{t "preview.hits"}}</dt><dd>{{.HitCount}}</dd>
+</dl>
+<p class="notice">{{t "preview.notice"}}</p>
+<a class="continue" href="{{.Destination}}" rel="noopener noreferrer nofollow">{{t "preview.continue"}}</a>
+</body>
+</html>
+`
+
+type previewData struct {
+	Lang        i18n.Lang
+	Code        string
+	Destination string
+	CreatedAt   string
+	ExpiresAt   string
+	HitCount    int64
+}
+
+// renderPreview показывает страницу с адресом назначения вместо перенаправления.
+// counted означает, что текущий переход уже учтен в статистике.
+func renderPreview(w http.ResponseWriter, r *http.Request, link *models.Link, destination string, counted bool) {
+	lang := i18n.FromContext(r.Context())
+	data := previewData{
+		Lang:        lang,
+		Code:        link.Code,
+		Destination: destination,
+		CreatedAt:   link.CreatedAt.Format(time.RFC1123),
+		HitCount:    link.HitCount,
+	}
+	if counted {
+		data.HitCount++
+	}
+	if link.ExpiresAt != nil {
+		data.ExpiresAt = link.ExpiresAt.Format(time.RFC1123)
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Header().Set("Cache-Control", "private, no-store")
+	w.Header().Set("X-Robots-Tag", "noindex")
+	i18n.SetHeaders(w, lang)
+	if err := previewTemplates[lang].Execute(w, data); err != nil {
+		logging.FromContext(r.Context()).Error("Ошибка при отображении страницы предпросмотра", "code", link.Code, "error", err)
+	}
+}
@@ -0,0 +1,122 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"tinyurl/internal/apierror"
+	"tinyurl/internal/db"
+	"tinyurl/internal/i18n"
+	"tinyurl/internal/qr"
+)
+
+func (s *Server) QRHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		methodNotAllowed(w, r, http.MethodGet)
+		return
+	}
+
+	code := pathValue(r, "code", "/qr/")
+	if code == "" {
+		notFound(w, r)
+		return
+	}
+	annotateCode(r, code)
+
+	opts, err := parseQROptions(r.URL.Query())
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+
+	domain, err := s.domainForRequest(r)
+	if err != nil {
+		serverError(w, r, i18n.ErrLinkLookup, err)
+		return
+	}
+	if domain == nil {
+		domainMissing(w, r)
+		return
+	}
+
+	link, err := db.GetLinkInDomain(r.Context(), s.DB, domain.ID, code)
+	if err != nil {
+		serverError(w, r, i18n.ErrLinkLookup, err)
+		return
+	}
+	if link == nil {
+		linkMissing(w, r)
+		return
+	}
+
+	image, err := qr.Render(s.shortURL(r, domain, link.Code), opts)
+	var sizeErr *qr.SizeError
+	if errors.As(err, &sizeErr) {
+		apierror.Write(w, r, apierror.Invalid(apierror.InvalidQROptions, "size", i18n.ErrQRSizeTooSmall, sizeErr.Min))
+		return
+	}
+	if err != nil {
+		serverError(w, r, i18n.ErrQRGeneration, err)
+		return
+	}
+
+	// Адрес в коде зависит от хоста запроса и ссылку можно удалить, поэтому общие
+	// кэши изображение не хранят, а браузер - недолго.
+	w.Header().Set("Content-Type", opts.ContentType())
+	w.Header().Set("Cache-Control", "private, max-age=300")
+	w.Write(image)
+}
+
+func parseQROptions(query url.Values) (qr.Options, error) {
+	opts := qr.DefaultOptions()
+
+	if v := query.Get("format"); v != "" {
+		opts.Format = v
+	}
+	if v := query.Get("level"); v != "" {
+		opts.Level = v
+	}
+	if v := query.Get("size"); v != "" {
+		size, err := strconv.Atoi(v)
+		if err != nil {
+			return opts, apierror.Invalid(apierror.InvalidQROptions, "size", i18n.ErrInvalidQRSize, v)
+		}
+		opts.Size = size
+	}
+	if v := query.Get("margin"); v != "" {
+		margin, err := strconv.Atoi(v)
+		if err != nil {
+			return opts, apierror.Invalid(apierror.InvalidQROptions, "margin", i18n.ErrInvalidQRMargin, v)
+		}
+		opts.Margin = margin
+	}
+	if v := query.Get("fg"); v != "" {
+		fg, err := qr.ParseColor(v)
+		if err != nil {
+			return opts, apierror.Invalid(apierror.InvalidQROptions, "fg", i18n.ErrInvalidColor, v)
+		}
+		opts.Foreground = fg
+	}
+	if v := query.Get("bg"); v != "" {
+		bg, err := qr.ParseColor(v)
+		if err != nil {
+			return opts, apierror.Invalid(apierror.InvalidQROptions, "bg", i18n.ErrInvalidColor, v)
+		}
+		opts.Background = bg
+	}
+
+	// Те же проверки, что и в qr.Options.Validate, но с полем и переводом сообщения.
+	switch {
+	case opts.Format != qr.FormatPNG && opts.Format != qr.FormatSVG:
+		return opts, apierror.Invalid(apierror.InvalidQROptions, "format", i18n.ErrQRFormat)
+	case opts.Size < qr.MinSize || opts.Size > qr.MaxSize:
+		return opts, apierror.Invalid(apierror.InvalidQROptions, "size", i18n.ErrQRSizeRange, qr.MinSize, qr.MaxSize)
+	case !qr.IsValidLevel(opts.Level):
+		return opts, apierror.Invalid(apierror.InvalidQROptions, "level", i18n.ErrQRLevel)
+	case opts.Margin < 0 || opts.Margin > qr.MaxMargin:
+		return opts, apierror.Invalid(apierror.InvalidQROptions, "margin", i18n.ErrQRMarginRange, qr.MaxMargin)
+	}
+	return opts, nil
+}
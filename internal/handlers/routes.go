@@ -0,0 +1,222 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+
+	"tinyurl/internal/models"
+	"tinyurl/internal/openapi"
+)
+
+// APIPrefix - префикс версионированного API. Старые маршруты (/shorten, /stats/ и
+// другие) сохранены для совместимости.
+const APIPrefix = "/api/v1"
+
+// APIVersion - версия документа OpenAPI. Меняется при изменении контракта /api/v1.
+const APIVersion = "1.0.0"
+
+type route struct {
+	openapi.Operation
+	handler http.HandlerFunc
+}
+
+var (
+	domainQuery = openapi.Parameter{
+		Name: "domain", In: "query", Schema: &openapi.Schema{Type: "string"},
+		Description: "Домен ссылки; по умолчанию определяется по заголовку Host",
+	}
+	qrQuery = []openapi.Parameter{
+		domainQuery,
+		{Name: "format", In: "query", Schema: &openapi.Schema{Type: "string"}, Description: "png или svg"},
+		{Name: "size", In: "query", Schema: &openapi.Schema{Type: "integer"}, Description: "Размер в пикселях"},
+		{Name: "level", In: "query", Schema: &openapi.Schema{Type: "string"}, Description: "Уровень коррекции: L, M, Q, H"},
+		{Name: "fg", In: "query", Schema: &openapi.Schema{Type: "string"}, Description: "Цвет модулей RRGGBB или RRGGBBAA"},
+		{Name: "bg", In: "query", Schema: &openapi.Schema{Type: "string"}, Description: "Цвет фона RRGGBB или RRGGBBAA"},
+		{Name: "margin", In: "query", Schema: &openapi.Schema{Type: "integer"}, Description: "Отступ в модулях"},
+	}
+	listQuery = []openapi.Parameter{
+		domainQuery,
+		{Name: "search", In: "query", Schema: &openapi.Schema{Type: "string"}, Description: "Подстрока кода или URL"},
+		{Name: "page_size", In: "query", Schema: &openapi.Schema{Type: "integer"}, Description: "Размер страницы, по умолчанию 50, не больше 1000"},
+		{Name: "page_token", In: "query", Schema: &openapi.Schema{Type: "string"}, Description: "next_page_token предыдущей страницы"},
+	}
+	deliveryStatusQuery = openapi.Parameter{
+		Name: "status", In: "query", Schema: &openapi.Schema{Type: "string"},
+		Description: "pending, delivered или dead",
+	}
+)
+
+func (s *Server) apiRoutes() []route {
+	return []route{
+		{openapi.Operation{
+			Method: http.MethodPost, Path: "/links", ID: "createLink", Summary: "Создать короткую ссылку", Tag: "links",
+			Query: []openapi.Parameter{idempotencyKeyHeader}, Request: models.ShortenRequest{}, Status: http.StatusOK, Response: models.ShortenResponse{},
+			Errors: []int{http.StatusBadRequest, http.StatusConflict, http.StatusUnprocessableEntity},
+		}, s.Idempotent(s.ShortenHandler)},
+		{openapi.Operation{
+			Method: http.MethodGet, Path: "/links/{code}", ID: "getLink", Summary: "Получить ссылку и статистику", Tag: "links",
+			Query: []openapi.Parameter{domainQuery}, Status: http.StatusOK, Response: models.StatsResponse{},
+			Errors: []int{http.StatusNotFound},
+		}, s.StatsHandler},
+		{openapi.Operation{
+			Method: http.MethodGet, Path: "/links", ID: "listLinks", Summary: "Список ссылок домена", Tag: "links",
+			Query: listQuery, Status: http.StatusOK, Response: models.LinkList{},
+			Errors: []int{http.StatusBadRequest, http.StatusNotFound},
+		}, s.ListLinksHandler},
+		{openapi.Operation{
+			Method: http.MethodPatch, Path: "/links/{code}", ID: "updateLink", Summary: "Изменить ссылку", Tag: "links",
+			Query: []openapi.Parameter{domainQuery}, Request: models.LinkUpdate{}, Status: http.StatusOK, Response: models.StatsResponse{},
+			Errors: []int{http.StatusBadRequest, http.StatusNotFound},
+		}, s.UpdateLinkHandler},
+		{openapi.Operation{
+			Method: http.MethodDelete, Path: "/links/{code}", ID: "deleteLink", Summary: "Удалить ссылку со статистикой", Tag: "links",
+			Query: []openapi.Parameter{domainQuery}, Status: http.StatusNoContent,
+			Errors: []int{http.StatusNotFound},
+		}, s.DeleteLinkHandler},
+		{openapi.Operation{
+			Method: http.MethodGet, Path: "/links/{code}/resolve", ID: "resolveLink", Summary: "Цель ссылки без учета перехода", Tag: "links",
+			Query: []openapi.Parameter{domainQuery}, Status: http.StatusOK, Response: models.ResolveResponse{},
+			Errors: []int{http.StatusNotFound},
+		}, s.ResolveHandler},
+		{openapi.Operation{
+			Method: http.MethodGet, Path: "/links/{code}/qr", ID: "getLinkQR", Summary: "Получить QR-код ссылки", Tag: "links",
+			Query: qrQuery, Status: http.StatusOK, ContentTypes: []string{"image/png", "image/svg+xml"},
+			Errors: []int{http.StatusBadRequest, http.StatusNotFound},
+		}, s.QRHandler},
+		{openapi.Operation{
+			Method: http.MethodGet, Path: "/campaigns", ID: "listCampaigns", Summary: "Статистика по UTM-кампаниям", Tag: "stats",
+			Status: http.StatusOK, Response: []models.CampaignStats{},
+		}, s.CampaignStatsHandler},
+		{openapi.Operation{
+			Method: http.MethodGet, Path: "/domains", ID: "listDomains", Summary: "Список доменов", Tag: "domains",
+			Status: http.StatusOK, Response: []models.Domain{},
+		}, s.DomainsHandler},
+		{openapi.Operation{
+			Method: http.MethodPost, Path: "/domains", ID: "createDomain", Summary: "Добавить домен", Tag: "domains",
+			Query: []openapi.Parameter{idempotencyKeyHeader}, Request: models.Domain{}, Status: http.StatusCreated, Response: models.Domain{},
+			Errors: []int{http.StatusBadRequest, http.StatusConflict, http.StatusUnprocessableEntity},
+		}, s.Idempotent(s.DomainsHandler)},
+		{openapi.Operation{
+			Method: http.MethodGet, Path: "/domains/{host}", ID: "getDomain", Summary: "Получить домен", Tag: "domains",
+			Status: http.StatusOK, Response: models.Domain{},
+			Errors: []int{http.StatusNotFound},
+		}, s.DomainHandler},
+		{openapi.Operation{
+			Method: http.MethodPut, Path: "/domains/{host}", ID: "updateDomain", Summary: "Изменить настройки домена", Tag: "domains",
+			Request: models.Domain{}, Status: http.StatusOK, Response: models.Domain{},
+			Errors: []int{http.StatusBadRequest, http.StatusNotFound},
+		}, s.DomainHandler},
+		{openapi.Operation{
+			Method: http.MethodDelete, Path: "/domains/{host}", ID: "deleteDomain", Summary: "Удалить домен без ссылок", Tag: "domains",
+			Status: http.StatusNoContent,
+			Errors: []int{http.StatusNotFound, http.StatusConflict},
+		}, s.DomainHandler},
+		{openapi.Operation{
+			Method: http.MethodGet, Path: "/utm-templates", ID: "listUTMTemplates", Summary: "Список UTM-шаблонов", Tag: "utm-templates",
+			Status: http.StatusOK, Response: []models.UTMTemplate{},
+		}, s.UTMTemplatesHandler},
+		{openapi.Operation{
+			Method: http.MethodPost, Path: "/utm-templates", ID: "createUTMTemplate", Summary: "Создать UTM-шаблон", Tag: "utm-templates",
+			Query: []openapi.Parameter{idempotencyKeyHeader}, Request: models.UTMTemplate{}, Status: http.StatusCreated, Response: models.UTMTemplate{},
+			Errors: []int{http.StatusBadRequest, http.StatusConflict, http.StatusUnprocessableEntity},
+		}, s.Idempotent(s.UTMTemplatesHandler)},
+		{openapi.Operation{
+			Method: http.MethodGet, Path: "/utm-templates/{name}", ID: "getUTMTemplate", Summary: "Получить UTM-шаблон", Tag: "utm-templates",
+			Status: http.StatusOK, Response: models.UTMTemplate{},
+			Errors: []int{http.StatusNotFound},
+		}, s.UTMTemplateHandler},
+		{openapi.Operation{
+			Method: http.MethodPut, Path: "/utm-templates/{name}", ID: "updateUTMTemplate", Summary: "Изменить UTM-шаблон", Tag: "utm-templates",
+			Request: models.UTMTemplate{}, Status: http.StatusOK, Response: models.UTMTemplate{},
+			Errors: []int{http.StatusBadRequest, http.StatusNotFound},
+		}, s.UTMTemplateHandler},
+		{openapi.Operation{
+			Method: http.MethodDelete, Path: "/utm-templates/{name}", ID: "deleteUTMTemplate", Summary: "Удалить неиспользуемый UTM-шаблон", Tag: "utm-templates",
+			Status: http.StatusNoContent,
+			Errors: []int{http.StatusNotFound, http.StatusConflict},
+		}, s.UTMTemplateHandler},
+		{openapi.Operation{
+			Method: http.MethodGet, Path: "/webhooks", ID: "listWebhooks", Summary: "Список вебхуков", Tag: "webhooks",
+			Status: http.StatusOK, Response: []models.Webhook{},
+		}, s.ListWebhooksHandler},
+		{openapi.Operation{
+			Method: http.MethodPost, Path: "/webhooks", ID: "createWebhook", Summary: "Подписаться на события ссылок", Tag: "webhooks",
+			Query: []openapi.Parameter{idempotencyKeyHeader}, Request: models.Webhook{}, Status: http.StatusCreated, Response: models.Webhook{},
+			Errors: []int{http.StatusBadRequest, http.StatusConflict, http.StatusUnprocessableEntity},
+		}, s.Idempotent(s.CreateWebhookHandler)},
+		{openapi.Operation{
+			Method: http.MethodGet, Path: "/webhooks/{id}", ID: "getWebhook", Summary: "Получить вебхук", Tag: "webhooks",
+			Status: http.StatusOK, Response: models.Webhook{},
+			Errors: []int{http.StatusNotFound},
+		}, s.GetWebhookHandler},
+		{openapi.Operation{
+			Method: http.MethodDelete, Path: "/webhooks/{id}", ID: "deleteWebhook", Summary: "Удалить вебхук и его доставки", Tag: "webhooks",
+			Status: http.StatusNoContent,
+			Errors: []int{http.StatusNotFound},
+		}, s.DeleteWebhookHandler},
+		{openapi.Operation{
+			Method: http.MethodGet, Path: "/webhooks/{id}/deliveries", ID: "listWebhookDeliveries", Summary: "Доставки вебхука с журналом попыток", Tag: "webhooks",
+			Query: []openapi.Parameter{deliveryStatusQuery}, Status: http.StatusOK, Response: []models.WebhookDelivery{},
+			Errors: []int{http.StatusBadRequest, http.StatusNotFound},
+		}, s.WebhookDeliveriesHandler},
+		{openapi.Operation{
+			Method: http.MethodPost, Path: "/webhooks/{id}/deliveries/{delivery}/retry", ID: "retryWebhookDelivery", Summary: "Повторить доставку", Tag: "webhooks",
+			Status: http.StatusOK, Response: models.WebhookDelivery{},
+			Errors: []int{http.StatusNotFound},
+		}, s.RetryDeliveryHandler},
+		{openapi.Operation{
+			Method: http.MethodGet, Path: "/openapi.json", ID: "getOpenAPI", Summary: "Спецификация OpenAPI", Tag: "meta",
+			Status: http.StatusOK, Response: map[string]any{},
+		}, s.OpenAPIHandler},
+	}
+}
+
+// RegisterAPI регистрирует маршруты /api/v1 с методом в шаблоне. Для каждого пути
+// также регистрируется шаблон без метода: он отвечает 405 с заголовком Allow в
+// формате problem+json вместо текстового ответа ServeMux.
+func (s *Server) RegisterAPI(mux *http.ServeMux) {
+	var paths []string
+	allowed := make(map[string][]string)
+	for _, rt := range s.apiRoutes() {
+		path := APIPrefix + rt.Path
+		mux.HandleFunc(rt.Method+" "+path, rt.handler)
+		if _, ok := allowed[path]; !ok {
+			paths = append(paths, path)
+		}
+		allowed[path] = append(allowed[path], rt.Method)
+	}
+
+	for _, path := range paths {
+		methods := allowed[path]
+		mux.HandleFunc(path, func(w http.ResponseWriter, r *http.Request) {
+			methodNotAllowed(w, r, methods...)
+		})
+	}
+}
+
+// OpenAPI возвращает спецификацию /api/v1, построенную по таблице маршрутов.
+func (s *Server) OpenAPI() *openapi.Document {
+	routes := s.apiRoutes()
+	ops := make([]openapi.Operation, len(routes))
+	for i, rt := range routes {
+		ops[i] = rt.Operation
+	}
+	return openapi.Build(openapi.Info{
+		Title:       "TinyURL API",
+		Description: "Сокращение ссылок, статистика, домены, UTM-шаблоны и вебхуки",
+		Version:     APIVersion,
+	}, APIPrefix, ops)
+}
+
+func (s *Server) OpenAPIHandler(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, s.OpenAPI())
+}
+
+// pathValue возвращает параметр шаблона маршрута /api/v1, а для старых маршрутов,
+// зарегистрированных по префиксу, - остаток пути после prefix.
+func pathValue(r *http.Request, name, prefix string) string {
+	if v := r.PathValue(name); v != "" {
+		return v
+	}
+	return strings.TrimPrefix(r.URL.Path, prefix)
+}
@@ -0,0 +1,180 @@
+package handlers
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"net"
+	"net/http"
+	"regexp"
+	"strings"
+
+	"tinyurl/internal/apierror"
+	"tinyurl/internal/i18n"
+	"tinyurl/internal/models"
+	"tinyurl/internal/useragent"
+	"tinyurl/internal/utils"
+)
+
+var countryCodePattern = regexp.MustCompile(`^[A-Z]{2}$`)
+
+type CountryResolver interface {
+	Country(ip net.IP) string
+}
+
+const (
+	maxTargetRules      = 20
+	maxVariants         = 10
+	defaultRuleName     = "default"
+	variantCookiePrefix = "tinyurl_v_"
+	variantCookieMaxAge = 30 * 24 * 60 * 60
+)
+
+func validateRules(rules []models.TargetRule) error {
+	if len(rules) > maxTargetRules {
+		return apierror.Invalid(apierror.InvalidRules, "rules", i18n.ErrTooManyRules, maxTargetRules)
+	}
+
+	names := make(map[string]bool, len(rules))
+	for i := range rules {
+		rule := &rules[i]
+		if rule.Name == "" {
+			rule.Name = fmt.Sprintf("rule_%d", i+1)
+		}
+		if rule.Name == defaultRuleName || names[rule.Name] {
+			return apierror.Invalid(apierror.InvalidRules, fmt.Sprintf("rules[%d].name", i), i18n.ErrDuplicateRule, rule.Name)
+		}
+		names[rule.Name] = true
+
+		if rule.URL == "" {
+			return apierror.Invalid(apierror.InvalidRules, fmt.Sprintf("rules[%d].url", i), i18n.ErrRuleURLRequired, rule.Name)
+		}
+		if !utils.IsHTTPURL(rule.URL) {
+			return apierror.Invalid(apierror.InvalidURL, fmt.Sprintf("rules[%d].url", i), i18n.ErrInvalidRuleURL)
+		}
+		if rule.OS != "" && !useragent.IsValidOS(rule.OS) {
+			return apierror.Invalid(apierror.InvalidRules, fmt.Sprintf("rules[%d].os", i), i18n.ErrUnknownOS, rule.Name, rule.OS)
+		}
+		if rule.Device != "" && !useragent.IsValidDevice(rule.Device) {
+			return apierror.Invalid(apierror.InvalidRules, fmt.Sprintf("rules[%d].device", i), i18n.ErrUnknownDevice, rule.Name, rule.Device)
+		}
+	}
+
+	return nil
+}
+
+func matchRule(rules []models.TargetRule, userAgent string) *models.TargetRule {
+	if len(rules) == 0 {
+		return nil
+	}
+
+	info := useragent.Parse(userAgent)
+	for i := range rules {
+		rule := &rules[i]
+		if rule.OS != "" && rule.OS != info.OS {
+			continue
+		}
+		if rule.Device != "" && rule.Device != info.Device {
+			continue
+		}
+		if rule.Bot != nil && *rule.Bot != info.Bot {
+			continue
+		}
+		return rule
+	}
+
+	return nil
+}
+
+func validateVariants(variants []models.Variant) error {
+	if len(variants) > maxVariants {
+		return apierror.Invalid(apierror.InvalidVariants, "variants", i18n.ErrTooManyVariants, maxVariants)
+	}
+
+	names := make(map[string]bool, len(variants))
+	for i := range variants {
+		variant := &variants[i]
+		if variant.Name == "" {
+			variant.Name = fmt.Sprintf("variant_%d", i+1)
+		}
+		if names[variant.Name] {
+			return apierror.Invalid(apierror.InvalidVariants, fmt.Sprintf("variants[%d].name", i), i18n.ErrDuplicateVariant, variant.Name)
+		}
+		names[variant.Name] = true
+
+		if variant.URL == "" {
+			return apierror.Invalid(apierror.InvalidVariants, fmt.Sprintf("variants[%d].url", i), i18n.ErrVariantURLRequired, variant.Name)
+		}
+		if !utils.IsHTTPURL(variant.URL) {
+			return apierror.Invalid(apierror.InvalidURL, fmt.Sprintf("variants[%d].url", i), i18n.ErrInvalidVariantURL)
+		}
+		if variant.Weight <= 0 {
+			return apierror.Invalid(apierror.InvalidVariants, fmt.Sprintf("variants[%d].weight", i), i18n.ErrVariantWeight, variant.Name)
+		}
+	}
+
+	return nil
+}
+
+func pickVariant(w http.ResponseWriter, r *http.Request, link *models.Link) *models.Variant {
+	if len(link.Variants) == 0 {
+		return nil
+	}
+
+	cookieName := variantCookiePrefix + link.Code
+	if link.StickyVariant {
+		if cookie, err := r.Cookie(cookieName); err == nil {
+			for i := range link.Variants {
+				if link.Variants[i].Name == cookie.Value {
+					return &link.Variants[i]
+				}
+			}
+		}
+	}
+
+	weights := make([]int, len(link.Variants))
+	total := 0
+	for i, variant := range link.Variants {
+		weights[i] = variant.Weight
+		total += variant.Weight
+	}
+	variant := &link.Variants[utils.WeightedIndex(weights, rand.IntN(total))]
+
+	if link.StickyVariant && w != nil {
+		http.SetCookie(w, &http.Cookie{
+			Name:     cookieName,
+			Value:    variant.Name,
+			Path:     "/r/" + link.Code,
+			MaxAge:   variantCookieMaxAge,
+			HttpOnly: true,
+			SameSite: http.SameSiteLaxMode,
+		})
+	}
+
+	return variant
+}
+
+func normalizeGeoTargets(targets map[string]string) (map[string]string, error) {
+	if len(targets) == 0 {
+		return nil, nil
+	}
+
+	normalized := make(map[string]string, len(targets))
+	for country, target := range targets {
+		code := strings.ToUpper(country)
+		if !countryCodePattern.MatchString(code) {
+			return nil, apierror.Invalid(apierror.InvalidGeo, "geo."+country, i18n.ErrInvalidCountry, country)
+		}
+		if target == "" {
+			return nil, apierror.Invalid(apierror.InvalidGeo, "geo."+country, i18n.ErrCountryURLRequired, code)
+		}
+		if !utils.IsHTTPURL(target) {
+			return nil, apierror.Invalid(apierror.InvalidURL, "geo."+country, i18n.ErrInvalidGeoURL)
+		}
+		if _, ok := normalized[code]; ok {
+			return nil, apierror.Invalid(apierror.InvalidGeo, "geo."+country, i18n.ErrDuplicateCountry, code)
+		}
+		normalized[code] = target
+	}
+
+	return normalized, nil
+}
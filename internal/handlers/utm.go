@@ -0,0 +1,147 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"regexp"
+
+	"tinyurl/internal/apierror"
+	"tinyurl/internal/db"
+	"tinyurl/internal/i18n"
+	"tinyurl/internal/models"
+)
+
+var templateNamePattern = regexp.MustCompile(`^[A-Za-z0-9_-]{1,64}$`)
+
+func (s *Server) UTMTemplatesHandler(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		templates, err := db.ListUTMTemplates(r.Context(), s.DB)
+		if err != nil {
+			serverError(w, r, i18n.ErrUTMTemplatesLookup, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, templates)
+	case http.MethodPost:
+		var t models.UTMTemplate
+		if err := json.NewDecoder(r.Body).Decode(&t); err != nil {
+			writeError(w, r, http.StatusBadRequest, apierror.InvalidJSON, i18n.ErrInvalidJSON)
+			return
+		}
+		if !templateNamePattern.MatchString(t.Name) {
+			invalidField(w, r, apierror.InvalidUTMTemplate, "name", i18n.ErrInvalidTemplateName)
+			return
+		}
+		if !validUTMTemplate(w, r, &t) {
+			return
+		}
+		if err := db.InsertUTMTemplate(r.Context(), s.DB, &t); err != nil {
+			if db.IsUniqueError(err) {
+				writeError(w, r, http.StatusConflict, apierror.UTMTemplateExists, i18n.ErrUTMTemplateExists)
+				return
+			}
+			serverError(w, r, i18n.ErrDatabase, err)
+			return
+		}
+		created, err := db.GetUTMTemplate(r.Context(), s.DB, t.Name)
+		if err != nil || created == nil {
+			serverError(w, r, i18n.ErrDatabase, err)
+			return
+		}
+		writeJSON(w, http.StatusCreated, created)
+	default:
+		methodNotAllowed(w, r, http.MethodGet, http.MethodPost)
+	}
+}
+
+func (s *Server) UTMTemplateHandler(w http.ResponseWriter, r *http.Request) {
+	name := pathValue(r, "name", "/utm-templates/")
+	if name == "" {
+		utmTemplateMissing(w, r)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		t, err := db.GetUTMTemplate(r.Context(), s.DB, name)
+		if err != nil {
+			serverError(w, r, i18n.ErrUTMTemplateLookup, err)
+			return
+		}
+		if t == nil {
+			utmTemplateMissing(w, r)
+			return
+		}
+		writeJSON(w, http.StatusOK, t)
+	case http.MethodPut:
+		var t models.UTMTemplate
+		if err := json.NewDecoder(r.Body).Decode(&t); err != nil {
+			writeError(w, r, http.StatusBadRequest, apierror.InvalidJSON, i18n.ErrInvalidJSON)
+			return
+		}
+		t.Name = name
+		if !validUTMTemplate(w, r, &t) {
+			return
+		}
+		found, err := db.UpdateUTMTemplate(r.Context(), s.DB, &t)
+		if err != nil {
+			serverError(w, r, i18n.ErrDatabase, err)
+			return
+		}
+		if !found {
+			utmTemplateMissing(w, r)
+			return
+		}
+		updated, err := db.GetUTMTemplate(r.Context(), s.DB, name)
+		if err != nil || updated == nil {
+			serverError(w, r, i18n.ErrDatabase, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, updated)
+	case http.MethodDelete:
+		inUse, err := db.CountLinksWithUTMTemplate(r.Context(), s.DB, name)
+		if err != nil {
+			serverError(w, r, i18n.ErrDatabase, err)
+			return
+		}
+		if inUse > 0 {
+			writeError(w, r, http.StatusConflict, apierror.UTMTemplateInUse, i18n.ErrUTMTemplateInUse)
+			return
+		}
+		found, err := db.DeleteUTMTemplate(r.Context(), s.DB, name)
+		if err != nil {
+			serverError(w, r, i18n.ErrDatabase, err)
+			return
+		}
+		if !found {
+			utmTemplateMissing(w, r)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		methodNotAllowed(w, r, http.MethodGet, http.MethodPut, http.MethodDelete)
+	}
+}
+
+func validUTMTemplate(w http.ResponseWriter, r *http.Request, t *models.UTMTemplate) bool {
+	field := ""
+	switch {
+	case t.Source == "":
+		field = "utm_source"
+	case t.Medium == "":
+		field = "utm_medium"
+	case t.Campaign == "":
+		field = "utm_campaign"
+	}
+	if field != "" {
+		invalidField(w, r, apierror.InvalidUTMTemplate, field, i18n.ErrUTMFieldsRequired)
+		return false
+	}
+	return true
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,213 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"tinyurl/internal/apierror"
+	"tinyurl/internal/db"
+	"tinyurl/internal/i18n"
+	"tinyurl/internal/logging"
+	"tinyurl/internal/models"
+	"tinyurl/internal/utils"
+	"tinyurl/internal/webhooks"
+)
+
+// EventPublisher принимает события жизненного цикла ссылок, например для рассылки
+// вебхуков. Ошибка публикации не отменяет операцию со ссылкой.
+type EventPublisher interface {
+	Publish(ctx context.Context, eventType string, data webhooks.EventData) error
+	// InvalidateWebhooks вызывается после создания и удаления вебхука, чтобы
+	// события не рассылались по устаревшему списку подписок.
+	InvalidateWebhooks()
+}
+
+const (
+	minSecretLength     = 16
+	defaultDeliveryList = 100
+)
+
+// webhooksChanged сбрасывает кэш подписок получателя событий.
+func (s *Server) webhooksChanged() {
+	if s.Events != nil {
+		s.Events.InvalidateWebhooks()
+	}
+}
+
+// publish отправляет событие о ссылке, если у сервера есть получатель событий.
+func (s *Server) publish(ctx context.Context, eventType string, domain *models.Domain, link *models.Link, dimensions map[string]string) {
+	if s.Events == nil {
+		return
+	}
+	data := webhooks.LinkData(domain.Host, link)
+	data.Dimensions = dimensions
+	if err := s.Events.Publish(ctx, eventType, data); err != nil {
+		logging.FromContext(ctx).Error("Ошибка при публикации события", "event", eventType, "code", link.Code, "error", err)
+	}
+}
+
+func webhookMissing(w http.ResponseWriter, r *http.Request) {
+	writeError(w, r, http.StatusNotFound, apierror.WebhookNotFound, i18n.ErrWebhookNotFound)
+}
+
+// webhookFromPath возвращает вебхук по параметру {id}; при ошибке ответ уже записан.
+func (s *Server) webhookFromPath(w http.ResponseWriter, r *http.Request) *models.Webhook {
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		webhookMissing(w, r)
+		return nil
+	}
+	hook, err := db.GetWebhook(r.Context(), s.DB, id)
+	if err != nil {
+		serverError(w, r, i18n.ErrWebhooksLookup, err)
+		return nil
+	}
+	if hook == nil {
+		webhookMissing(w, r)
+		return nil
+	}
+	return hook
+}
+
+func (s *Server) ListWebhooksHandler(w http.ResponseWriter, r *http.Request) {
+	hooks, err := db.ListWebhooks(r.Context(), s.DB)
+	if err != nil {
+		serverError(w, r, i18n.ErrWebhooksLookup, err)
+		return
+	}
+	for i := range hooks {
+		hooks[i].Secret = ""
+	}
+	writeJSON(w, http.StatusOK, hooks)
+}
+
+// CreateWebhookHandler создает подписку. Если secret не задан, он генерируется;
+// в ответе secret возвращается один раз.
+func (s *Server) CreateWebhookHandler(w http.ResponseWriter, r *http.Request) {
+	var hook models.Webhook
+	if err := json.NewDecoder(r.Body).Decode(&hook); err != nil {
+		writeError(w, r, http.StatusBadRequest, apierror.InvalidJSON, i18n.ErrInvalidJSON)
+		return
+	}
+
+	if !utils.IsHTTPURL(hook.URL) {
+		invalidField(w, r, apierror.InvalidWebhook, "url", i18n.ErrInvalidWebhookURL)
+		return
+	}
+	if len(hook.Events) == 0 {
+		invalidField(w, r, apierror.InvalidWebhook, "events", i18n.ErrWebhookEvents)
+		return
+	}
+	seen := make(map[string]bool)
+	events := hook.Events[:0]
+	for _, event := range hook.Events {
+		if !webhooks.IsValidEventType(event) {
+			invalidField(w, r, apierror.InvalidWebhook, "events", i18n.ErrUnknownEvent, event)
+			return
+		}
+		if !seen[event] {
+			seen[event] = true
+			events = append(events, event)
+		}
+	}
+	hook.Events = events
+	switch {
+	case hook.Secret == "":
+		hook.Secret = webhooks.NewSecret()
+	case len(hook.Secret) < minSecretLength:
+		invalidField(w, r, apierror.InvalidWebhook, "secret", i18n.ErrInvalidSecret, minSecretLength)
+		return
+	}
+
+	if err := db.InsertWebhook(r.Context(), s.DB, &hook); err != nil {
+		serverError(w, r, i18n.ErrDatabase, err)
+		return
+	}
+	s.webhooksChanged()
+	writeJSON(w, http.StatusCreated, hook)
+}
+
+func (s *Server) GetWebhookHandler(w http.ResponseWriter, r *http.Request) {
+	hook := s.webhookFromPath(w, r)
+	if hook == nil {
+		return
+	}
+	hook.Secret = ""
+	writeJSON(w, http.StatusOK, hook)
+}
+
+func (s *Server) DeleteWebhookHandler(w http.ResponseWriter, r *http.Request) {
+	hook := s.webhookFromPath(w, r)
+	if hook == nil {
+		return
+	}
+	deleted, err := db.DeleteWebhook(r.Context(), s.DB, hook.ID)
+	if err != nil {
+		serverError(w, r, i18n.ErrDatabase, err)
+		return
+	}
+	s.webhooksChanged()
+	if !deleted {
+		webhookMissing(w, r)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// WebhookDeliveriesHandler возвращает последние доставки вебхука с журналом
+// попыток, новые первыми. Параметр status отбирает доставки в одном статусе.
+func (s *Server) WebhookDeliveriesHandler(w http.ResponseWriter, r *http.Request) {
+	hook := s.webhookFromPath(w, r)
+	if hook == nil {
+		return
+	}
+
+	status := r.URL.Query().Get("status")
+	switch status {
+	case "", models.DeliveryPending, models.DeliveryDelivered, models.DeliveryDead:
+	default:
+		invalidField(w, r, apierror.InvalidWebhook, "status", i18n.ErrInvalidDeliveryStatus)
+		return
+	}
+
+	deliveries, err := db.ListDeliveries(r.Context(), s.DB, hook.ID, status, defaultDeliveryList)
+	if err != nil {
+		serverError(w, r, i18n.ErrWebhooksLookup, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, deliveries)
+}
+
+// RetryDeliveryHandler возвращает доставку, например из dead, в очередь с новым
+// набором попыток.
+func (s *Server) RetryDeliveryHandler(w http.ResponseWriter, r *http.Request) {
+	hook := s.webhookFromPath(w, r)
+	if hook == nil {
+		return
+	}
+
+	id, err := strconv.ParseInt(r.PathValue("delivery"), 10, 64)
+	if err != nil {
+		writeError(w, r, http.StatusNotFound, apierror.DeliveryNotFound, i18n.ErrDeliveryNotFound)
+		return
+	}
+	retried, err := db.RetryDelivery(r.Context(), s.DB, hook.ID, id, time.Now())
+	if err != nil {
+		serverError(w, r, i18n.ErrDatabase, err)
+		return
+	}
+	if !retried {
+		writeError(w, r, http.StatusNotFound, apierror.DeliveryNotFound, i18n.ErrDeliveryNotFound)
+		return
+	}
+
+	delivery, err := db.GetDelivery(r.Context(), s.DB, hook.ID, id)
+	if err != nil || delivery == nil {
+		serverError(w, r, i18n.ErrWebhooksLookup, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, delivery)
+}
@@ -0,0 +1,167 @@
+package i18n
+
+var en = map[Key]string{
+	ErrInternal:              "Internal server error",
+	ErrDatabase:              "Database error",
+	ErrLinkLookup:            "Failed to load the link",
+	ErrStatsLookup:           "Failed to load statistics",
+	ErrDomainsLookup:         "Failed to load domains",
+	ErrDomainLookup:          "Failed to load the domain",
+	ErrUTMTemplatesLookup:    "Failed to load UTM templates",
+	ErrUTMTemplateLookup:     "Failed to load the UTM template",
+	ErrQRGeneration:          "Failed to generate the QR code",
+	ErrNotFound:              "Not found",
+	ErrMethodNotAllowed:      "Allowed methods: %s",
+	ErrInvalidJSON:           "Invalid JSON",
+	ErrURLRequired:           "URL is required",
+	ErrInvalidURL:            "Invalid URL",
+	ErrInvalidAlias:          "An alias cannot contain whitespace or the characters / ? # % \\, end with +, or be . or ..",
+	ErrInvalidRuleURL:        "Invalid URL in rule",
+	ErrInvalidVariantURL:     "Invalid URL in variant",
+	ErrInvalidGeoURL:         "Invalid URL in geo rule",
+	ErrInvalidRedirectType:   "redirect_type must be one of 301, 302, 307, 308",
+	ErrInvalidTTL:            "ttl_days must not be negative",
+	ErrInvalidPageToken:      "Invalid page_token",
+	ErrInvalidPageSize:       "page_size must be a non-negative integer",
+	ErrStickyWithoutVariants: "sticky requires variants",
+	ErrInvalidUTMApply:       "utm_apply must be create or redirect",
+	ErrUTMApplyWithoutTmpl:   "utm_apply requires utm_template",
+	ErrCodeGeneration:        "Could not generate a unique code, please try again",
+	ErrBaseURLRequired:       "TINYURL_BASE_URL is not set, the short URL cannot be built",
+	ErrAliasTaken:            "This alias is already taken",
+	ErrInvalidPassthrough:    "Invalid path or query parameters",
+	ErrLinkNotFound:          "Link not found",
+	ErrDomainNotFound:        "Domain not found",
+	ErrInvalidHost:           "Invalid host name",
+	ErrDomainExists:          "Domain already exists",
+	ErrDomainInUse:           "The domain still has links",
+	ErrInvalidCodeLength:     "code_length must be between %d and %d",
+	ErrInvalidNotFoundURL:    "not_found_url must be an absolute http(s) URL",
+	ErrUTMTemplateNotFound:   "UTM template not found",
+	ErrInvalidTemplateName:   "Template name may only contain Latin letters, digits, _ and -",
+	ErrUTMTemplateExists:     "A template with this name already exists",
+	ErrUTMTemplateInUse:      "The template is used by links",
+	ErrUTMFieldsRequired:     "utm_source, utm_medium and utm_campaign are required",
+	ErrTooManyRules:          "at most %d rules are allowed",
+	ErrDuplicateRule:         "rule name %q is already used",
+	ErrRuleURLRequired:       "rule %q: url is required",
+	ErrUnknownOS:             "rule %q: unknown OS %q",
+	ErrUnknownDevice:         "rule %q: unknown device type %q",
+	ErrTooManyVariants:       "at most %d variants are allowed",
+	ErrDuplicateVariant:      "variant name %q is already used",
+	ErrVariantURLRequired:    "variant %q: url is required",
+	ErrVariantWeight:         "variant %q: weight must be positive",
+	ErrInvalidCountry:        "invalid country code %q",
+	ErrCountryURLRequired:    "country %s: url is required",
+	ErrDuplicateCountry:      "country %s is listed more than once",
+	ErrInvalidQRSize:         "invalid size %q",
+	ErrInvalidQRMargin:       "invalid margin %q",
+	ErrInvalidColor:          "invalid color %q",
+	ErrQRFormat:              "format must be png or svg",
+	ErrQRSizeRange:           "size must be between %d and %d",
+	ErrQRLevel:               "recovery level must be L, M, Q or H",
+	ErrQRMarginRange:         "margin must be between 0 and %d",
+	ErrQRSizeTooSmall:        "the QR code with its margin does not fit, size must be at least %d",
+	ErrWebhooksLookup:        "Failed to load webhooks",
+	ErrWebhookNotFound:       "Webhook not found",
+	ErrDeliveryNotFound:      "Delivery not found",
+	ErrInvalidWebhookURL:     "url must be an absolute http(s) URL",
+	ErrWebhookEvents:         "events must contain at least one event type",
+	ErrUnknownEvent:          "unknown event type %q",
+	ErrInvalidSecret:         "secret must be at least %d characters long",
+	ErrInvalidDeliveryStatus: "status must be pending, delivered or dead",
+	ErrIdempotencyKey:        "Idempotency-Key must contain 1 to %d printable ASCII characters",
+	ErrIdempotencyReused:     "idempotency key was already used for a different request",
+	ErrIdempotencyBusy:       "a request with this idempotency key is still in progress",
+
+	ReadyShuttingDown:      "server is shutting down",
+	ReadyNotChecked:        "not checked",
+	ReadyCheckFailed:       "check failed",
+	ReadyMigrationsPending: "not applied: %s",
+
+	PreviewTitle:    "Following link %s",
+	PreviewHeading:  "Short link %s",
+	PreviewLeadsTo:  "This link leads to:",
+	PreviewCreated:  "Created",
+	PreviewExpires:  "Expires",
+	PreviewHits:     "Visits",
+	PreviewNotice:   "Short links hide the real address. Make sure you trust the destination site before following the link and entering any personal data there.",
+	PreviewContinue: "Continue",
+
+	CLIRootShort:       "TinyURL CLI - shorten links from the command line",
+	CLIServerFlag:      "TinyURL server address",
+	CLILangFlag:        "Message language: en or ru (defaults to LC_ALL, LC_MESSAGES or LANG)",
+	CLIShortShort:      "Shorten a URL",
+	CLIAliasFlag:       "Custom alias for the link",
+	CLITTLFlag:         "Link lifetime in days (0 = never expires)",
+	CLIStatsShort:      "Show statistics for a code",
+	CLIQRShort:         "Save the QR code of a short link as PNG or SVG",
+	CLIQROutputFlag:    "Output file (.png or .svg, defaults to <code>.png)",
+	CLIQRSizeFlag:      "Image size in pixels",
+	CLIQRLevelFlag:     "Error correction level: L, M, Q, H",
+	CLIQRFgFlag:        "Module color (RRGGBB or RRGGBBAA)",
+	CLIQRBgFlag:        "Background color (RRGGBB or RRGGBBAA)",
+	CLIQRMarginFlag:    "Margin in modules",
+	CLIProbeShort:      "Check that the server is available (for HEALTHCHECK); exits with 1 on failure",
+	CLIEndpointFlag:    "Check: readyz or healthz",
+	CLITimeoutFlag:     "Request timeout",
+	CLIInvalidLang:     "unsupported language %q, available: en and ru",
+	CLIRequestFailed:   "failed to send the request: %v",
+	CLIShortURL:        "Short link:",
+	CLICreated:         "Created:",
+	CLIExpires:         "Expires:",
+	CLINever:           "never",
+	CLIHits:            "Visits:",
+	CLIQRExtension:     "file extension must be .png or .svg",
+	CLIQRSaved:         "QR code saved:",
+	CLIInvalidProbe:    "endpoint must be readyz or healthz",
+	CLIUnavailable:     "server is unavailable: %v",
+	CLINotReady:        "server is not ready (%d): %s",
+	CLIServerError:     "server returned error %d: %s",
+	CLIProblem:         "error [%s]: %s",
+	CLIProblemField:    "field: %s",
+	CLIProblemRequest:  "request: %s",
+	CLIListShort:       "List links of a domain",
+	CLIDeleteShort:     "Delete a link together with its statistics",
+	CLIUpdateShort:     "Change the URL or lifetime of a link",
+	CLIResolveShort:    "Show where a link leads without counting a visit",
+	CLIOpenShort:       "Open a short link in the browser",
+	CLIDomainFlag:      "Link domain (defaults to the server's domain)",
+	CLISearchFlag:      "Substring of the code or URL",
+	CLIPageSizeFlag:    "Links per page (at most 1000)",
+	CLIPageTokenFlag:   "Page token from the previous output",
+	CLIAllFlag:         "Fetch all pages",
+	CLIUpdateURLFlag:   "New destination URL",
+	CLIUpdateTTLFlag:   "New lifetime in days from now (0 = never expires)",
+	CLIOpenTargetFlag:  "Open the destination URL directly without counting a visit",
+	CLIListHeader:      "CODE\tVISITS\tEXPIRES\tURL",
+	CLINoLinks:         "No links found",
+	CLINextPage:        "Next page: --page %s",
+	CLIDeleted:         "Link deleted:",
+	CLINothingToUpdate: "specify --url or --ttl",
+	CLIDestination:     "Destination:",
+	CLIRedirectType:    "Redirect type:",
+	CLIOpening:         "Opening:",
+	CLIBrowserFailed:   "failed to open the browser: %v",
+	CLIOutputFlag:      "Output format: table, json, yaml or template=<Go template>",
+	CLIQuietFlag:       "Print only the essential value, such as the short URL",
+	CLIInvalidOutput:   "unsupported output format %q, available: table, json, yaml, template=<Go template>",
+	CLIInvalidTemplate: "invalid output template: %v",
+	CLIQRLegacyFile:    "warning: -o/--output with a file name is deprecated for qr, use -f/--file",
+	CLIConfigShort:     "Manage CLI settings and profiles",
+	CLIConfigSetShort:  "Set a profile key: server, token, ttl or output (an empty value removes it)",
+	CLIConfigGetShort:  "Show a profile key or the whole profile",
+	CLIUseProfileShort: "Make a profile the default one",
+	CLIProfileFlag:     "Settings profile (defaults to the current profile from the config file)",
+	CLITokenFlag:       "Token for the Authorization: Bearer header (overrides TINYURL_TOKEN and the profile)",
+	CLIConfigRead:      "failed to read settings from %s: %v",
+	CLIConfigKey:       "unknown key %q, available: server, token, ttl, output",
+	CLIConfigServer:    "invalid server address %q: an http or https URL is expected",
+	CLIConfigTTL:       "invalid lifetime %q: a non-negative number of days is expected",
+	CLIProfileNotFound: "profile %q not found",
+	CLIConfigSaved:     "Saved: %s.%s",
+	CLIProfile:         "Profile:",
+	CLIProfileSwitched: "Current profile:",
+	CLICompletionShort: "Generate a shell completion script",
+	CLICompletionLong:  "Generate a completion script for bash, zsh, fish or PowerShell.\n\nLink codes of stats, qr, delete, update, resolve and open are completed from the server and cached for a short time.\n\n  bash:       source <(tinyurl completion bash)\n  zsh:        tinyurl completion zsh > \"${fpath[1]}/_tinyurl\"\n  fish:       tinyurl completion fish > ~/.config/fish/completions/tinyurl.fish\n  PowerShell: tinyurl completion powershell | Out-String | Invoke-Expression",
+}
@@ -0,0 +1,148 @@
+package i18n
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Lang - код языка сообщений (ISO 639-1).
+type Lang string
+
+const (
+	English Lang = "en"
+	Russian Lang = "ru"
+
+	// Default используется, если клиент не указал поддерживаемый язык.
+	Default = Russian
+)
+
+var catalogs = map[Lang]map[Key]string{
+	English: en,
+	Russian: ru,
+}
+
+// Languages возвращает поддерживаемые языки.
+func Languages() []Lang {
+	return []Lang{English, Russian}
+}
+
+// Catalog возвращает сообщения языка; используется в тестах полноты переводов.
+func Catalog(lang Lang) map[Key]string {
+	return catalogs[lang]
+}
+
+// Parse разбирает код языка в форматах ru, en-US или en_US.UTF-8.
+func Parse(s string) (Lang, bool) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	if i := strings.IndexAny(s, ".@"); i >= 0 {
+		s = s[:i]
+	}
+	if i := strings.IndexAny(s, "-_"); i >= 0 {
+		s = s[:i]
+	}
+	lang := Lang(s)
+	_, ok := catalogs[lang]
+	return lang, ok
+}
+
+// T возвращает сообщение на языке lang. Если перевода нет, используется язык
+// по умолчанию, а в крайнем случае - сам ключ.
+func T(lang Lang, key Key, args ...any) string {
+	msg, ok := catalogs[lang][key]
+	if !ok {
+		if msg, ok = catalogs[Default][key]; !ok {
+			msg = string(key)
+		}
+	}
+	if len(args) > 0 {
+		return fmt.Sprintf(msg, args...)
+	}
+	return msg
+}
+
+// Negotiate выбирает язык по заголовку Accept-Language с учетом весов q.
+func Negotiate(header string, fallback Lang) Lang {
+	type candidate struct {
+		lang Lang
+		q    float64
+	}
+
+	var candidates []candidate
+	for _, part := range strings.Split(header, ",") {
+		tag, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		q := 1.0
+		if v, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			parsed, err := strconv.ParseFloat(v, 64)
+			if err != nil {
+				continue
+			}
+			q = parsed
+		}
+		if q <= 0 {
+			continue
+		}
+
+		lang, ok := Parse(tag)
+		if strings.TrimSpace(tag) == "*" {
+			lang, ok = fallback, true
+		}
+		if ok {
+			candidates = append(candidates, candidate{lang, q})
+		}
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool { return candidates[i].q > candidates[j].q })
+	if len(candidates) > 0 {
+		return candidates[0].lang
+	}
+	return fallback
+}
+
+// FromEnv определяет язык по переменным LC_ALL, LC_MESSAGES и LANG, как это
+// делают утилиты POSIX: решает первая непустая переменная.
+func FromEnv(fallback Lang) Lang {
+	for _, name := range []string{"LC_ALL", "LC_MESSAGES", "LANG"} {
+		if v := os.Getenv(name); v != "" {
+			if lang, ok := Parse(v); ok {
+				return lang
+			}
+			return fallback
+		}
+	}
+	return fallback
+}
+
+type contextKey struct{}
+
+// FromContext возвращает язык текущего запроса или Default вне запроса.
+func FromContext(ctx context.Context) Lang {
+	if lang, ok := ctx.Value(contextKey{}).(Lang); ok {
+		return lang
+	}
+	return Default
+}
+
+// WithLang сохраняет язык в контексте.
+func WithLang(ctx context.Context, lang Lang) context.Context {
+	return context.WithValue(ctx, contextKey{}, lang)
+}
+
+// Middleware выбирает язык ответа по Accept-Language; если клиент не указал
+// поддерживаемый язык, используется fallback.
+func Middleware(fallback Lang, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		lang := Negotiate(r.Header.Get("Accept-Language"), fallback)
+		next.ServeHTTP(w, r.WithContext(WithLang(r.Context(), lang)))
+	})
+}
+
+// SetHeaders помечает ответ, текст которого зависит от Accept-Language.
+func SetHeaders(w http.ResponseWriter, lang Lang) {
+	w.Header().Set("Content-Language", string(lang))
+	w.Header().Add("Vary", "Accept-Language")
+}
@@ -0,0 +1,181 @@
+package i18n
+
+// Key - идентификатор сообщения в каталоге. Каждый ключ должен быть переведен
+// на все языки из Languages.
+type Key string
+
+// Ошибки API.
+const (
+	ErrInternal              Key = "error.internal"
+	ErrDatabase              Key = "error.database"
+	ErrLinkLookup            Key = "error.link_lookup"
+	ErrStatsLookup           Key = "error.stats_lookup"
+	ErrDomainsLookup         Key = "error.domains_lookup"
+	ErrDomainLookup          Key = "error.domain_lookup"
+	ErrUTMTemplatesLookup    Key = "error.utm_templates_lookup"
+	ErrUTMTemplateLookup     Key = "error.utm_template_lookup"
+	ErrQRGeneration          Key = "error.qr_generation"
+	ErrNotFound              Key = "error.not_found"
+	ErrMethodNotAllowed      Key = "error.method_not_allowed"
+	ErrInvalidJSON           Key = "error.invalid_json"
+	ErrURLRequired           Key = "error.url_required"
+	ErrInvalidURL            Key = "error.invalid_url"
+	ErrInvalidAlias          Key = "error.invalid_alias"
+	ErrInvalidRuleURL        Key = "error.invalid_rule_url"
+	ErrInvalidVariantURL     Key = "error.invalid_variant_url"
+	ErrInvalidGeoURL         Key = "error.invalid_geo_url"
+	ErrInvalidRedirectType   Key = "error.invalid_redirect_type"
+	ErrInvalidTTL            Key = "error.invalid_ttl"
+	ErrInvalidPageToken      Key = "error.invalid_page_token"
+	ErrInvalidPageSize       Key = "error.invalid_page_size"
+	ErrStickyWithoutVariants Key = "error.sticky_without_variants"
+	ErrInvalidUTMApply       Key = "error.invalid_utm_apply"
+	ErrUTMApplyWithoutTmpl   Key = "error.utm_apply_without_template"
+	ErrCodeGeneration        Key = "error.code_generation"
+	ErrBaseURLRequired       Key = "error.base_url_required"
+	ErrAliasTaken            Key = "error.alias_taken"
+	ErrInvalidPassthrough    Key = "error.invalid_passthrough"
+	ErrLinkNotFound          Key = "error.link_not_found"
+	ErrDomainNotFound        Key = "error.domain_not_found"
+	ErrInvalidHost           Key = "error.invalid_host"
+	ErrDomainExists          Key = "error.domain_exists"
+	ErrDomainInUse           Key = "error.domain_in_use"
+	ErrInvalidCodeLength     Key = "error.invalid_code_length"
+	ErrInvalidNotFoundURL    Key = "error.invalid_not_found_url"
+	ErrUTMTemplateNotFound   Key = "error.utm_template_not_found"
+	ErrInvalidTemplateName   Key = "error.invalid_template_name"
+	ErrUTMTemplateExists     Key = "error.utm_template_exists"
+	ErrUTMTemplateInUse      Key = "error.utm_template_in_use"
+	ErrUTMFieldsRequired     Key = "error.utm_fields_required"
+	ErrTooManyRules          Key = "error.too_many_rules"
+	ErrDuplicateRule         Key = "error.duplicate_rule"
+	ErrRuleURLRequired       Key = "error.rule_url_required"
+	ErrUnknownOS             Key = "error.unknown_os"
+	ErrUnknownDevice         Key = "error.unknown_device"
+	ErrTooManyVariants       Key = "error.too_many_variants"
+	ErrDuplicateVariant      Key = "error.duplicate_variant"
+	ErrVariantURLRequired    Key = "error.variant_url_required"
+	ErrVariantWeight         Key = "error.variant_weight"
+	ErrInvalidCountry        Key = "error.invalid_country"
+	ErrCountryURLRequired    Key = "error.country_url_required"
+	ErrDuplicateCountry      Key = "error.duplicate_country"
+	ErrInvalidQRSize         Key = "error.invalid_qr_size"
+	ErrInvalidQRMargin       Key = "error.invalid_qr_margin"
+	ErrInvalidColor          Key = "error.invalid_color"
+	ErrQRFormat              Key = "error.qr_format"
+	ErrQRSizeRange           Key = "error.qr_size_range"
+	ErrQRLevel               Key = "error.qr_level"
+	ErrQRMarginRange         Key = "error.qr_margin_range"
+	ErrQRSizeTooSmall        Key = "error.qr_size_too_small"
+	ErrWebhooksLookup        Key = "error.webhooks_lookup"
+	ErrWebhookNotFound       Key = "error.webhook_not_found"
+	ErrDeliveryNotFound      Key = "error.delivery_not_found"
+	ErrInvalidWebhookURL     Key = "error.invalid_webhook_url"
+	ErrWebhookEvents         Key = "error.webhook_events"
+	ErrUnknownEvent          Key = "error.unknown_event"
+	ErrInvalidSecret         Key = "error.invalid_secret"
+	ErrInvalidDeliveryStatus Key = "error.invalid_delivery_status"
+	ErrIdempotencyKey        Key = "error.idempotency_key"
+	ErrIdempotencyReused     Key = "error.idempotency_reused"
+	ErrIdempotencyBusy       Key = "error.idempotency_busy"
+)
+
+// Проверки /readyz.
+const (
+	ReadyShuttingDown      Key = "ready.shutting_down"
+	ReadyNotChecked        Key = "ready.not_checked"
+	ReadyCheckFailed       Key = "ready.check_failed"
+	ReadyMigrationsPending Key = "ready.migrations_pending"
+)
+
+// Страница предпросмотра.
+const (
+	PreviewTitle    Key = "preview.title"
+	PreviewHeading  Key = "preview.heading"
+	PreviewLeadsTo  Key = "preview.leads_to"
+	PreviewCreated  Key = "preview.created"
+	PreviewExpires  Key = "preview.expires"
+	PreviewHits     Key = "preview.hits"
+	PreviewNotice   Key = "preview.notice"
+	PreviewContinue Key = "preview.continue"
+)
+
+// Командная строка.
+const (
+	CLIRootShort       Key = "cli.root.short"
+	CLIServerFlag      Key = "cli.flag.server"
+	CLILangFlag        Key = "cli.flag.lang"
+	CLIShortShort      Key = "cli.short.short"
+	CLIAliasFlag       Key = "cli.short.flag.alias"
+	CLITTLFlag         Key = "cli.short.flag.ttl"
+	CLIStatsShort      Key = "cli.stats.short"
+	CLIQRShort         Key = "cli.qr.short"
+	CLIQROutputFlag    Key = "cli.qr.flag.output"
+	CLIQRSizeFlag      Key = "cli.qr.flag.size"
+	CLIQRLevelFlag     Key = "cli.qr.flag.level"
+	CLIQRFgFlag        Key = "cli.qr.flag.fg"
+	CLIQRBgFlag        Key = "cli.qr.flag.bg"
+	CLIQRMarginFlag    Key = "cli.qr.flag.margin"
+	CLIProbeShort      Key = "cli.probe.short"
+	CLIEndpointFlag    Key = "cli.probe.flag.endpoint"
+	CLITimeoutFlag     Key = "cli.probe.flag.timeout"
+	CLIInvalidLang     Key = "cli.invalid_lang"
+	CLIRequestFailed   Key = "cli.request_failed"
+	CLIShortURL        Key = "cli.short_url"
+	CLICreated         Key = "cli.stats.created"
+	CLIExpires         Key = "cli.stats.expires"
+	CLINever           Key = "cli.stats.never"
+	CLIHits            Key = "cli.stats.hits"
+	CLIQRExtension     Key = "cli.qr.extension"
+	CLIQRSaved         Key = "cli.qr.saved"
+	CLIInvalidProbe    Key = "cli.probe.invalid_endpoint"
+	CLIUnavailable     Key = "cli.probe.unavailable"
+	CLINotReady        Key = "cli.probe.not_ready"
+	CLIServerError     Key = "cli.server_error"
+	CLIProblem         Key = "cli.problem"
+	CLIProblemField    Key = "cli.problem.field"
+	CLIProblemRequest  Key = "cli.problem.request"
+	CLIListShort       Key = "cli.list.short"
+	CLIDeleteShort     Key = "cli.delete.short"
+	CLIUpdateShort     Key = "cli.update.short"
+	CLIResolveShort    Key = "cli.resolve.short"
+	CLIOpenShort       Key = "cli.open.short"
+	CLIDomainFlag      Key = "cli.flag.domain"
+	CLISearchFlag      Key = "cli.list.flag.search"
+	CLIPageSizeFlag    Key = "cli.list.flag.limit"
+	CLIPageTokenFlag   Key = "cli.list.flag.page"
+	CLIAllFlag         Key = "cli.list.flag.all"
+	CLIUpdateURLFlag   Key = "cli.update.flag.url"
+	CLIUpdateTTLFlag   Key = "cli.update.flag.ttl"
+	CLIOpenTargetFlag  Key = "cli.open.flag.target"
+	CLIListHeader      Key = "cli.list.header"
+	CLINoLinks         Key = "cli.list.empty"
+	CLINextPage        Key = "cli.list.next_page"
+	CLIDeleted         Key = "cli.delete.done"
+	CLINothingToUpdate Key = "cli.update.nothing"
+	CLIDestination     Key = "cli.resolve.destination"
+	CLIRedirectType    Key = "cli.resolve.redirect_type"
+	CLIOpening         Key = "cli.open.opening"
+	CLIBrowserFailed   Key = "cli.open.failed"
+	CLIOutputFlag      Key = "cli.flag.output"
+	CLIQuietFlag       Key = "cli.flag.quiet"
+	CLIInvalidOutput   Key = "cli.output.invalid"
+	CLIInvalidTemplate Key = "cli.output.template"
+	CLIQRLegacyFile    Key = "cli.qr.output_deprecated"
+	CLIConfigShort     Key = "cli.config.short"
+	CLIConfigSetShort  Key = "cli.config.set.short"
+	CLIConfigGetShort  Key = "cli.config.get.short"
+	CLIUseProfileShort Key = "cli.config.use.short"
+	CLIProfileFlag     Key = "cli.flag.profile"
+	CLITokenFlag       Key = "cli.flag.token"
+	CLIConfigRead      Key = "cli.config.read"
+	CLIConfigKey       Key = "cli.config.key"
+	CLIConfigServer    Key = "cli.config.server"
+	CLIConfigTTL       Key = "cli.config.ttl"
+	CLIProfileNotFound Key = "cli.config.profile_not_found"
+	CLIConfigSaved     Key = "cli.config.saved"
+	CLIProfile         Key = "cli.config.profile"
+	CLIProfileSwitched Key = "cli.config.switched"
+	CLICompletionShort Key = "cli.completion.short"
+	CLICompletionLong  Key = "cli.completion.long"
+)
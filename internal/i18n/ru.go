@@ -0,0 +1,167 @@
+package i18n
+
+var ru = map[Key]string{
+	ErrInternal:              "Внутренняя ошибка сервера",
+	ErrDatabase:              "Ошибка базы данных",
+	ErrLinkLookup:            "Ошибка при получении ссылки",
+	ErrStatsLookup:           "Ошибка при получении статистики",
+	ErrDomainsLookup:         "Ошибка при получении доменов",
+	ErrDomainLookup:          "Ошибка при получении домена",
+	ErrUTMTemplatesLookup:    "Ошибка при получении UTM-шаблонов",
+	ErrUTMTemplateLookup:     "Ошибка при получении UTM-шаблона",
+	ErrQRGeneration:          "Ошибка при создании QR-кода",
+	ErrNotFound:              "Не найдено",
+	ErrMethodNotAllowed:      "Разрешены только методы %s",
+	ErrInvalidJSON:           "Некорректный JSON",
+	ErrURLRequired:           "URL обязателен",
+	ErrInvalidURL:            "Некорректный URL",
+	ErrInvalidAlias:          "Алиас не может содержать пробелы и символы / ? # % \\, заканчиваться на + или быть равным . или ..",
+	ErrInvalidRuleURL:        "Некорректный URL в правиле",
+	ErrInvalidVariantURL:     "Некорректный URL в варианте",
+	ErrInvalidGeoURL:         "Некорректный URL в гео-правиле",
+	ErrInvalidRedirectType:   "redirect_type должен быть одним из 301, 302, 307, 308",
+	ErrInvalidTTL:            "ttl_days не может быть отрицательным",
+	ErrInvalidPageToken:      "Некорректный page_token",
+	ErrInvalidPageSize:       "page_size должен быть неотрицательным целым числом",
+	ErrStickyWithoutVariants: "sticky требует variants",
+	ErrInvalidUTMApply:       "utm_apply должен быть create или redirect",
+	ErrUTMApplyWithoutTmpl:   "utm_apply требует utm_template",
+	ErrCodeGeneration:        "Не удалось создать уникальный код, попробуйте снова",
+	ErrBaseURLRequired:       "Не задан TINYURL_BASE_URL, короткую ссылку не построить",
+	ErrAliasTaken:            "Этот алиас уже занят",
+	ErrInvalidPassthrough:    "Некорректный путь или параметры запроса",
+	ErrLinkNotFound:          "Ссылка не найдена",
+	ErrDomainNotFound:        "Домен не найден",
+	ErrInvalidHost:           "Некорректное имя хоста",
+	ErrDomainExists:          "Домен уже существует",
+	ErrDomainInUse:           "К домену привязаны ссылки",
+	ErrInvalidCodeLength:     "code_length должен быть от %d до %d",
+	ErrInvalidNotFoundURL:    "not_found_url должен быть абсолютным http(s) URL",
+	ErrUTMTemplateNotFound:   "UTM-шаблон не найден",
+	ErrInvalidTemplateName:   "Имя шаблона может содержать только латинские буквы, цифры, _ и -",
+	ErrUTMTemplateExists:     "Шаблон с таким именем уже существует",
+	ErrUTMTemplateInUse:      "Шаблон используется ссылками",
+	ErrUTMFieldsRequired:     "utm_source, utm_medium и utm_campaign обязательны",
+	ErrTooManyRules:          "допускается не более %d правил",
+	ErrDuplicateRule:         "имя правила %q уже используется",
+	ErrRuleURLRequired:       "правило %q: url обязателен",
+	ErrUnknownOS:             "правило %q: неизвестная ОС %q",
+	ErrUnknownDevice:         "правило %q: неизвестный тип устройства %q",
+	ErrTooManyVariants:       "допускается не более %d вариантов",
+	ErrDuplicateVariant:      "имя варианта %q уже используется",
+	ErrVariantURLRequired:    "вариант %q: url обязателен",
+	ErrVariantWeight:         "вариант %q: weight должен быть положительным",
+	ErrInvalidCountry:        "некорректный код страны %q",
+	ErrCountryURLRequired:    "страна %s: url обязателен",
+	ErrDuplicateCountry:      "страна %s указана несколько раз",
+	ErrInvalidQRSize:         "некорректный размер %q",
+	ErrInvalidQRMargin:       "некорректный отступ %q",
+	ErrInvalidColor:          "некорректный цвет %q",
+	ErrQRFormat:              "формат должен быть png или svg",
+	ErrQRSizeRange:           "размер должен быть от %d до %d",
+	ErrQRLevel:               "уровень коррекции должен быть L, M, Q или H",
+	ErrQRMarginRange:         "отступ должен быть от 0 до %d",
+	ErrQRSizeTooSmall:        "QR-код с отступом не помещается в размер, нужно не меньше %d",
+	ErrWebhooksLookup:        "Ошибка при получении вебхуков",
+	ErrWebhookNotFound:       "Вебхук не найден",
+	ErrDeliveryNotFound:      "Доставка не найдена",
+	ErrInvalidWebhookURL:     "url должен быть абсолютным http(s) URL",
+	ErrWebhookEvents:         "events должен содержать хотя бы один тип события",
+	ErrUnknownEvent:          "неизвестный тип события %q",
+	ErrInvalidSecret:         "secret должен быть не короче %d символов",
+	ErrInvalidDeliveryStatus: "status должен быть pending, delivered или dead",
+	ErrIdempotencyKey:        "Заголовок Idempotency-Key должен содержать от 1 до %d печатных символов ASCII",
+	ErrIdempotencyReused:     "Ключ идемпотентности уже использован для другого запроса",
+	ErrIdempotencyBusy:       "Запрос с этим ключом идемпотентности еще выполняется",
+
+	ReadyShuttingDown:      "сервер останавливается",
+	ReadyNotChecked:        "не проверено",
+	ReadyCheckFailed:       "проверка не пройдена",
+	ReadyMigrationsPending: "не применены: %s",
+
+	PreviewTitle:    "Переход по ссылке %s",
+	PreviewHeading:  "Короткая ссылка %s",
+	PreviewLeadsTo:  "Эта ссылка ведет на:",
+	PreviewCreated:  "Создана",
+	PreviewExpires:  "Истекает",
+	PreviewHits:     "Переходов",
+	PreviewNotice:   "Короткие ссылки скрывают настоящий адрес. Убедитесь, что вы доверяете сайту назначения, прежде чем переходить по ссылке и вводить на нем личные данные.",
+	PreviewContinue: "Перейти",
+
+	CLIRootShort:       "TinyURL CLI - сокращайте ссылки из командной строки",
+	CLIServerFlag:      "Адрес сервера TinyURL",
+	CLILangFlag:        "Язык сообщений: en или ru (по умолчанию из LC_ALL, LC_MESSAGES или LANG)",
+	CLIShortShort:      "Сократить URL",
+	CLIAliasFlag:       "Пользовательский алиас для ссылки",
+	CLITTLFlag:         "Срок жизни ссылки в днях (0 = бессрочно)",
+	CLIStatsShort:      "Получить статистику по коду",
+	CLIQRShort:         "Сохранить QR-код короткой ссылки в PNG или SVG",
+	CLIQROutputFlag:    "Файл для сохранения (.png или .svg, по умолчанию <code>.png)",
+	CLIQRSizeFlag:      "Размер изображения в пикселях",
+	CLIQRLevelFlag:     "Уровень коррекции ошибок: L, M, Q, H",
+	CLIQRFgFlag:        "Цвет модулей (RRGGBB или RRGGBBAA)",
+	CLIQRBgFlag:        "Цвет фона (RRGGBB или RRGGBBAA)",
+	CLIQRMarginFlag:    "Отступ в модулях",
+	CLIProbeShort:      "Проверить доступность сервера (для HEALTHCHECK); код выхода 1 при ошибке",
+	CLIEndpointFlag:    "Проверка: readyz или healthz",
+	CLITimeoutFlag:     "Таймаут запроса",
+	CLIInvalidLang:     "неподдерживаемый язык %q, доступны en и ru",
+	CLIRequestFailed:   "ошибка при отправке запроса: %v",
+	CLIShortURL:        "Короткая ссылка:",
+	CLICreated:         "Создано:",
+	CLIExpires:         "Истекает:",
+	CLINever:           "никогда",
+	CLIHits:            "Количество переходов:",
+	CLIQRExtension:     "расширение файла должно быть .png или .svg",
+	CLIQRSaved:         "QR-код сохранен:",
+	CLIInvalidProbe:    "endpoint должен быть readyz или healthz",
+	CLIUnavailable:     "сервер недоступен: %v",
+	CLINotReady:        "сервер не готов (%d): %s",
+	CLIServerError:     "сервер вернул ошибку %d: %s",
+	CLIProblem:         "ошибка [%s]: %s",
+	CLIProblemField:    "поле: %s",
+	CLIProblemRequest:  "запрос: %s",
+	CLIListShort:       "Показать ссылки домена",
+	CLIDeleteShort:     "Удалить ссылку вместе со статистикой",
+	CLIUpdateShort:     "Изменить URL или срок жизни ссылки",
+	CLIResolveShort:    "Показать, куда ведет ссылка, не засчитывая переход",
+	CLIOpenShort:       "Открыть короткую ссылку в браузере",
+	CLIDomainFlag:      "Домен ссылки (по умолчанию домен сервера)",
+	CLISearchFlag:      "Подстрока кода или URL",
+	CLIPageSizeFlag:    "Число ссылок на странице (не больше 1000)",
+	CLIPageTokenFlag:   "Токен страницы из предыдущего вывода",
+	CLIAllFlag:         "Загрузить все страницы",
+	CLIUpdateURLFlag:   "Новый целевой URL",
+	CLIUpdateTTLFlag:   "Новый срок жизни в днях от текущего момента (0 = бессрочно)",
+	CLIOpenTargetFlag:  "Открыть целевой URL напрямую, без учета перехода",
+	CLIListHeader:      "КОД\tПЕРЕХОДЫ\tИСТЕКАЕТ\tURL",
+	CLINoLinks:         "Ссылок не найдено",
+	CLINextPage:        "Следующая страница: --page %s",
+	CLIDeleted:         "Ссылка удалена:",
+	CLINothingToUpdate: "укажите --url или --ttl",
+	CLIDestination:     "Цель:",
+	CLIRedirectType:    "Тип перенаправления:",
+	CLIOpening:         "Открывается:",
+	CLIBrowserFailed:   "не удалось открыть браузер: %v",
+	CLIOutputFlag:      "Формат вывода: table, json, yaml или template=<шаблон Go>",
+	CLIQuietFlag:       "Выводить только главное значение, например короткую ссылку",
+	CLIInvalidOutput:   "неподдерживаемый формат вывода %q, доступны: table, json, yaml, template=<шаблон Go>",
+	CLIInvalidTemplate: "ошибка в шаблоне вывода: %v",
+	CLIQRLegacyFile:    "предупреждение: -o/--output с именем файла для qr устарел, используйте -f/--file",
+	CLIConfigShort:     "Настройки и профили CLI",
+	CLIConfigSetShort:  "Задать ключ профиля: server, token, ttl или output (пустое значение удаляет ключ)",
+	CLIConfigGetShort:  "Показать ключ профиля или весь профиль",
+	CLIUseProfileShort: "Сделать профиль профилем по умолчанию",
+	CLIProfileFlag:     "Профиль настроек (по умолчанию - текущий профиль из файла настроек)",
+	CLITokenFlag:       "Токен для заголовка Authorization: Bearer (перекрывает TINYURL_TOKEN и профиль)",
+	CLIConfigRead:      "не удалось прочитать настройки из %s: %v",
+	CLIConfigKey:       "неизвестный ключ %q, доступны: server, token, ttl, output",
+	CLIConfigServer:    "некорректный адрес сервера %q: нужен URL http или https",
+	CLIConfigTTL:       "некорректный срок жизни %q: нужно неотрицательное число дней",
+	CLIProfileNotFound: "профиль %q не найден",
+	CLIConfigSaved:     "Сохранено: %s.%s",
+	CLIProfile:         "Профиль:",
+	CLIProfileSwitched: "Текущий профиль:",
+	CLICompletionShort: "Сгенерировать скрипт автодополнения для оболочки",
+	CLICompletionLong:  "Генерирует скрипт автодополнения для bash, zsh, fish или PowerShell.\n\nКоды ссылок для stats, qr, delete, update, resolve и open подсказываются по данным сервера и ненадолго кэшируются.\n\n  bash:       source <(tinyurl completion bash)\n  zsh:        tinyurl completion zsh > \"${fpath[1]}/_tinyurl\"\n  fish:       tinyurl completion fish > ~/.config/fish/completions/tinyurl.fish\n  PowerShell: tinyurl completion powershell | Out-String | Invoke-Expression",
+}
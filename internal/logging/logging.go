@@ -0,0 +1,157 @@
+package logging
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel/trace"
+
+	"tinyurl/internal/utils"
+)
+
+const RequestIDHeader = "X-Request-ID"
+
+var requestIDPattern = regexp.MustCompile(`^[A-Za-z0-9._:-]{1,128}$`)
+
+type contextKey struct{}
+
+type requestState struct {
+	logger *slog.Logger
+	id     string
+
+	mu    sync.Mutex
+	attrs []any
+}
+
+// New создает логгер в формате text или json с уровнем debug, info, warn или error.
+func New(w io.Writer, format, level string) (*slog.Logger, error) {
+	var lvl slog.Level
+	if err := lvl.UnmarshalText([]byte(level)); err != nil {
+		return nil, fmt.Errorf("некорректный уровень логирования: %s", level)
+	}
+
+	opts := &slog.HandlerOptions{Level: lvl}
+	switch strings.ToLower(format) {
+	case "text":
+		return slog.New(slog.NewTextHandler(w, opts)), nil
+	case "json":
+		return slog.New(slog.NewJSONHandler(w, opts)), nil
+	default:
+		return nil, fmt.Errorf("некорректный формат логов: %s", format)
+	}
+}
+
+// FromContext возвращает логгер запроса с полем request_id или slog.Default вне запроса.
+func FromContext(ctx context.Context) *slog.Logger {
+	if state, ok := ctx.Value(contextKey{}).(*requestState); ok {
+		return state.logger
+	}
+	return slog.Default()
+}
+
+// RequestID возвращает идентификатор текущего запроса.
+func RequestID(ctx context.Context) string {
+	if state, ok := ctx.Value(contextKey{}).(*requestState); ok {
+		return state.id
+	}
+	return ""
+}
+
+// AddAttrs добавляет поля в access-лог текущего запроса, например код ссылки.
+func AddAttrs(ctx context.Context, args ...any) {
+	if state, ok := ctx.Value(contextKey{}).(*requestState); ok {
+		state.mu.Lock()
+		state.attrs = append(state.attrs, args...)
+		state.mu.Unlock()
+	}
+}
+
+// Middleware присваивает запросу идентификатор и пишет access-лог. Корректный
+// X-Request-ID от клиента сохраняется, иначе генерируется новый; идентификатор
+// возвращается в ответе. Маршрут берется из шаблона mux, как и в метриках.
+func Middleware(logger *slog.Logger, mux *http.ServeMux, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := r.Header.Get(RequestIDHeader)
+		if !requestIDPattern.MatchString(id) {
+			id = newRequestID()
+		}
+		w.Header().Set(RequestIDHeader, id)
+
+		reqLogger := logger.With("request_id", id)
+		if sc := trace.SpanContextFromContext(r.Context()); sc.IsValid() {
+			reqLogger = reqLogger.With("trace_id", sc.TraceID().String())
+		}
+		state := &requestState{logger: reqLogger, id: id}
+		r = r.WithContext(context.WithValue(r.Context(), contextKey{}, state))
+
+		route := utils.Route(mux, r)
+		if route == "" {
+			route = "unmatched"
+		}
+
+		start := time.Now()
+		rec := &responseRecorder{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(rec, r)
+
+		args := []any{
+			"method", r.Method,
+			"route", route,
+			"path", r.URL.Path,
+			"status", rec.status,
+			"latency", time.Since(start),
+			"bytes", rec.bytes,
+		}
+		state.mu.Lock()
+		args = append(args, state.attrs...)
+		state.mu.Unlock()
+
+		level := slog.LevelInfo
+		if rec.status >= http.StatusInternalServerError {
+			level = slog.LevelError
+		}
+		state.logger.Log(r.Context(), level, "http request", args...)
+	})
+}
+
+func newRequestID() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		panic(err)
+	}
+	return hex.EncodeToString(b)
+}
+
+type responseRecorder struct {
+	http.ResponseWriter
+	status      int
+	bytes       int
+	wroteHeader bool
+}
+
+func (r *responseRecorder) WriteHeader(status int) {
+	if !r.wroteHeader {
+		r.status = status
+		r.wroteHeader = true
+	}
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *responseRecorder) Write(b []byte) (int, error) {
+	r.wroteHeader = true
+	n, err := r.ResponseWriter.Write(b)
+	r.bytes += n
+	return n, err
+}
+
+func (r *responseRecorder) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
@@ -0,0 +1,164 @@
+package metrics
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+
+	"tinyurl/internal/utils"
+)
+
+const namespace = "tinyurl"
+
+// Registry содержит все метрики сервиса, включая стандартные метрики Go-рантайма
+// (число горутин, память, GC) и процесса.
+var Registry = prometheus.NewRegistry()
+
+var (
+	requestDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "http_request_duration_seconds",
+		Help:      "Длительность обработки HTTP-запросов по маршруту, методу и статусу.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"route", "method", "status"})
+
+	requestsInFlight = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "http_requests_in_flight",
+		Help:      "Число обрабатываемых HTTP-запросов.",
+	})
+
+	shortenTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "shorten_total",
+		Help:      "Запросы на создание коротких ссылок по результату.",
+	}, []string{"result"})
+
+	redirectTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "redirect_total",
+		Help:      "Переходы по коротким ссылкам по результату.",
+	}, []string{"result"})
+
+	codeCollisions = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "code_collisions_total",
+		Help:      "Повторные попытки генерации кода из-за совпадения с существующим.",
+	})
+
+	dbQueryDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "db_query_duration_seconds",
+		Help:      "Длительность запросов к базе данных по операции.",
+		Buckets:   []float64{.0005, .001, .0025, .005, .01, .025, .05, .1, .25, .5, 1},
+	}, []string{"operation"})
+
+	dbErrors = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "db_errors_total",
+		Help:      "Ошибки запросов к базе данных по операции.",
+	}, []string{"operation"})
+
+	hitsPending = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "hits_pending",
+		Help:      "Переходы, которые еще не записаны в статистику.",
+	})
+)
+
+func init() {
+	Registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		requestDuration,
+		requestsInFlight,
+		shortenTotal,
+		redirectTotal,
+		codeCollisions,
+		dbQueryDuration,
+		dbErrors,
+		hitsPending,
+	)
+}
+
+// Handler отдает метрики в формате Prometheus.
+func Handler() http.Handler {
+	return promhttp.HandlerFor(Registry, promhttp.HandlerOpts{Registry: Registry})
+}
+
+// Middleware измеряет все запросы к mux. Маршрут берется из зарегистрированного
+// шаблона, а не из пути, чтобы коды ссылок не попадали в метки.
+func Middleware(mux *http.ServeMux) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		route := utils.Route(mux, r)
+		if route == "" {
+			route = "unmatched"
+		}
+
+		requestsInFlight.Inc()
+		defer requestsInFlight.Dec()
+
+		start := time.Now()
+		rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		mux.ServeHTTP(rec, r)
+
+		requestDuration.WithLabelValues(route, r.Method, strconv.Itoa(rec.status)).
+			Observe(time.Since(start).Seconds())
+	})
+}
+
+type statusRecorder struct {
+	http.ResponseWriter
+	status      int
+	wroteHeader bool
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	if !r.wroteHeader {
+		r.status = status
+		r.wroteHeader = true
+	}
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *statusRecorder) Write(b []byte) (int, error) {
+	r.wroteHeader = true
+	return r.ResponseWriter.Write(b)
+}
+
+func (r *statusRecorder) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
+
+func ShortenResult(result string) {
+	shortenTotal.WithLabelValues(result).Inc()
+}
+
+func RedirectResult(result string) {
+	redirectTotal.WithLabelValues(result).Inc()
+}
+
+func CodeCollision() {
+	codeCollisions.Inc()
+}
+
+// ObserveQuery записывает длительность и ошибку запроса к базе. Вызывается через defer
+// с указателем на возвращаемую ошибку.
+func ObserveQuery(operation string, start time.Time, err *error) {
+	dbQueryDuration.WithLabelValues(operation).Observe(time.Since(start).Seconds())
+	if err != nil && *err != nil {
+		dbErrors.WithLabelValues(operation).Inc()
+	}
+}
+
+func HitQueued() {
+	hitsPending.Inc()
+}
+
+func HitRecorded() {
+	hitsPending.Dec()
+}
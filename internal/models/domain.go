@@ -0,0 +1,14 @@
+package models
+
+import "time"
+
+// Domain - собственный домен коротких ссылок со своими настройками по умолчанию.
+// Ссылки без домена принадлежат домену по умолчанию с ID 0.
+type Domain struct {
+	ID           int64     `json:"-"`
+	Host         string    `json:"host"`
+	RedirectType int       `json:"redirect_type,omitempty"`
+	NotFoundURL  string    `json:"not_found_url,omitempty"`
+	CodeLength   int       `json:"code_length,omitempty"`
+	CreatedAt    time.Time `json:"created_at" openapi:"readonly"`
+}
@@ -0,0 +1,15 @@
+package models
+
+import "time"
+
+// IdempotentRequest - запрос с заголовком Idempotency-Key и сохраненный ответ на
+// него. Status 0 означает, что запрос еще выполняется.
+type IdempotentRequest struct {
+	Scope       string
+	Key         string
+	RequestHash string
+	Status      int
+	ContentType string
+	Body        []byte
+	ExpiresAt   time.Time
+}
@@ -3,18 +3,67 @@ package models
 import "time"
 
 type Link struct {
-	ID        int64
-	Code      string
-	URL       string
-	CreatedAt time.Time
-	ExpiresAt *time.Time
-	HitCount  int64
+	ID            int64
+	DomainID      int64
+	Code          string
+	URL           string
+	CreatedAt     time.Time
+	ExpiresAt     *time.Time
+	HitCount      int64
+	RedirectType  int
+	Passthrough   bool
+	UTMTemplate   string
+	UTMAtRedirect bool
+	Rules         []TargetRule
+	Variants      []Variant
+	StickyVariant bool
+	GeoTargets    map[string]string
+	Interstitial  bool
+}
+
+type LinkOptions struct {
+	DomainID      int64
+	RedirectType  int
+	Passthrough   bool
+	UTMTemplate   string
+	UTMAtRedirect bool
+	Rules         []TargetRule
+	Variants      []Variant
+	StickyVariant bool
+	GeoTargets    map[string]string
+	Interstitial  bool
+}
+
+// TargetRule перенаправляет на URL посетителей, чей User-Agent совпал со всеми
+// заданными условиями. Пустое условие совпадает с любым значением.
+type TargetRule struct {
+	Name   string `json:"name"`
+	OS     string `json:"os,omitempty"`
+	Device string `json:"device,omitempty"`
+	Bot    *bool  `json:"bot,omitempty"`
+	URL    string `json:"url"`
+}
+
+type Variant struct {
+	Name   string `json:"name"`
+	URL    string `json:"url"`
+	Weight int    `json:"weight"`
 }
 
 type ShortenRequest struct {
-	URL     string `json:"url"`
-	Alias   string `json:"alias,omitempty"`
-	TTLDays int    `json:"ttl_days,omitempty"`
+	URL          string            `json:"url"`
+	Alias        string            `json:"alias,omitempty"`
+	TTLDays      int               `json:"ttl_days,omitempty"`
+	RedirectType int               `json:"redirect_type,omitempty"`
+	Passthrough  bool              `json:"passthrough,omitempty"`
+	UTMTemplate  string            `json:"utm_template,omitempty"`
+	UTMApply     string            `json:"utm_apply,omitempty"`
+	Rules        []TargetRule      `json:"rules,omitempty"`
+	Variants     []Variant         `json:"variants,omitempty"`
+	Sticky       bool              `json:"sticky,omitempty"`
+	Geo          map[string]string `json:"geo,omitempty"`
+	Interstitial bool              `json:"interstitial,omitempty"`
+	Domain       string            `json:"domain,omitempty"`
 }
 
 type ShortenResponse struct {
@@ -23,8 +72,71 @@ type ShortenResponse struct {
 }
 
 type StatsResponse struct {
+	URL          string            `json:"url"`
+	CreatedAt    time.Time         `json:"created_at"`
+	ExpiresAt    *time.Time        `json:"expires_at,omitempty"`
+	HitCount     int64             `json:"hit_count"`
+	RedirectType int               `json:"redirect_type"`
+	Passthrough  bool              `json:"passthrough"`
+	UTMTemplate  string            `json:"utm_template,omitempty"`
+	Breakdown    Breakdown         `json:"breakdown,omitempty"`
+	Rules        []TargetRule      `json:"rules,omitempty"`
+	Variants     []Variant         `json:"variants,omitempty"`
+	Sticky       bool              `json:"sticky,omitempty"`
+	Geo          map[string]string `json:"geo,omitempty"`
+	Interstitial bool              `json:"interstitial"`
+	Domain       string            `json:"domain,omitempty"`
+}
+
+// Breakdown группирует переходы по измерению (например, campaign) и его значению.
+type Breakdown map[string]map[string]int64
+
+type CampaignStats struct {
+	Campaign string `json:"campaign"`
+	HitCount int64  `json:"hit_count"`
+	Links    int64  `json:"links"`
+}
+
+// LinkUpdate - изменение ссылки. Поля со значением nil не меняются.
+type LinkUpdate struct {
+	URL *string `json:"url,omitempty"`
+	// TTLDays отсчитывается от момента изменения; 0 снимает срок действия.
+	TTLDays *int `json:"ttl_days,omitempty"`
+	// RedirectType 0 возвращает тип перенаправления по умолчанию для домена.
+	RedirectType *int  `json:"redirect_type,omitempty"`
+	Passthrough  *bool `json:"passthrough,omitempty"`
+	Interstitial *bool `json:"interstitial,omitempty"`
+}
+
+// LinkFilter выбирает страницу ссылок домена. Ссылки упорядочены по ID, AfterID -
+// ID последней ссылки предыдущей страницы. Search отбирает ссылки, у которых код
+// или URL содержит подстроку.
+type LinkFilter struct {
+	DomainID int64
+	AfterID  int64
+	Search   string
+	Limit    int
+}
+
+// LinkSummary - ссылка в списке, без правил, вариантов и гео.
+type LinkSummary struct {
+	Code      string     `json:"code"`
+	Domain    string     `json:"domain,omitempty"`
+	ShortURL  string     `json:"short_url"`
 	URL       string     `json:"url"`
 	CreatedAt time.Time  `json:"created_at"`
 	ExpiresAt *time.Time `json:"expires_at,omitempty"`
 	HitCount  int64      `json:"hit_count"`
 }
+
+// LinkList - страница списка ссылок. Пустой NextPageToken означает последнюю страницу.
+type LinkList struct {
+	Links         []LinkSummary `json:"links"`
+	NextPageToken string        `json:"next_page_token,omitempty"`
+}
+
+type ResolveResponse struct {
+	URL          string `json:"url"`
+	ShortURL     string `json:"short_url"`
+	RedirectType int    `json:"redirect_type"`
+}
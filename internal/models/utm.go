@@ -0,0 +1,34 @@
+package models
+
+import "time"
+
+const (
+	UTMApplyCreate   = "create"
+	UTMApplyRedirect = "redirect"
+)
+
+type UTMTemplate struct {
+	ID        int64     `json:"-"`
+	Name      string    `json:"name"`
+	Source    string    `json:"utm_source"`
+	Medium    string    `json:"utm_medium"`
+	Campaign  string    `json:"utm_campaign"`
+	Term      string    `json:"utm_term,omitempty"`
+	Content   string    `json:"utm_content,omitempty"`
+	CreatedAt time.Time `json:"created_at" openapi:"readonly"`
+}
+
+func (t *UTMTemplate) Params() map[string]string {
+	params := map[string]string{
+		"utm_source":   t.Source,
+		"utm_medium":   t.Medium,
+		"utm_campaign": t.Campaign,
+	}
+	if t.Term != "" {
+		params["utm_term"] = t.Term
+	}
+	if t.Content != "" {
+		params["utm_content"] = t.Content
+	}
+	return params
+}
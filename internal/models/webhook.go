@@ -0,0 +1,45 @@
+package models
+
+import "time"
+
+// Статусы доставки вебхука. Доставка, исчерпавшая попытки, переходит в dead и
+// повторяется только вручную.
+const (
+	DeliveryPending   = "pending"
+	DeliveryDelivered = "delivered"
+	DeliveryDead      = "dead"
+)
+
+// Webhook - подписка на события ссылок. Secret возвращается только при создании.
+type Webhook struct {
+	ID        int64     `json:"id" openapi:"readonly"`
+	URL       string    `json:"url"`
+	Events    []string  `json:"events"`
+	Secret    string    `json:"secret,omitempty"`
+	CreatedAt time.Time `json:"created_at" openapi:"readonly"`
+}
+
+type WebhookDelivery struct {
+	ID             int64            `json:"id"`
+	WebhookID      int64            `json:"webhook_id"`
+	EventID        string           `json:"event_id"`
+	Event          string           `json:"event"`
+	Status         string           `json:"status"`
+	Attempts       int              `json:"attempts"`
+	NextAttemptAt  *time.Time       `json:"next_attempt_at,omitempty"`
+	LastStatusCode int              `json:"last_status_code,omitempty"`
+	LastError      string           `json:"last_error,omitempty"`
+	CreatedAt      time.Time        `json:"created_at"`
+	UpdatedAt      time.Time        `json:"updated_at"`
+	Log            []WebhookAttempt `json:"log"`
+	Payload        string           `json:"-"`
+}
+
+// WebhookAttempt - запись журнала об одной попытке доставки.
+type WebhookAttempt struct {
+	Attempt    int       `json:"attempt"`
+	StatusCode int       `json:"status_code,omitempty"`
+	Error      string    `json:"error,omitempty"`
+	DurationMS int64     `json:"duration_ms"`
+	CreatedAt  time.Time `json:"created_at"`
+}
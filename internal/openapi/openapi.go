@@ -0,0 +1,244 @@
+package openapi
+
+import (
+	"net/http"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"tinyurl/internal/apierror"
+)
+
+// Version - версия спецификации OpenAPI, в которой описывается API.
+const Version = "3.0.3"
+
+type Document struct {
+	OpenAPI    string              `json:"openapi"`
+	Info       Info                `json:"info"`
+	Servers    []Server            `json:"servers,omitempty"`
+	Paths      map[string]PathItem `json:"paths"`
+	Components Components          `json:"components"`
+}
+
+type Info struct {
+	Title       string `json:"title"`
+	Description string `json:"description,omitempty"`
+	Version     string `json:"version"`
+}
+
+type Server struct {
+	URL string `json:"url"`
+}
+
+// PathItem сопоставляет метод в нижнем регистре с описанием операции.
+type PathItem map[string]*OperationObject
+
+type OperationObject struct {
+	OperationID string               `json:"operationId"`
+	Summary     string               `json:"summary,omitempty"`
+	Tags        []string             `json:"tags,omitempty"`
+	Parameters  []Parameter          `json:"parameters,omitempty"`
+	RequestBody *RequestBody         `json:"requestBody,omitempty"`
+	Responses   map[string]*Response `json:"responses"`
+}
+
+type Parameter struct {
+	Name        string  `json:"name"`
+	In          string  `json:"in"`
+	Description string  `json:"description,omitempty"`
+	Required    bool    `json:"required,omitempty"`
+	Schema      *Schema `json:"schema"`
+}
+
+type RequestBody struct {
+	Required bool                 `json:"required"`
+	Content  map[string]MediaType `json:"content"`
+}
+
+type Response struct {
+	Description string               `json:"description"`
+	Content     map[string]MediaType `json:"content,omitempty"`
+}
+
+type MediaType struct {
+	Schema *Schema `json:"schema"`
+}
+
+type Components struct {
+	Schemas map[string]*Schema `json:"schemas"`
+}
+
+type Schema struct {
+	Ref                  string             `json:"$ref,omitempty"`
+	Type                 string             `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Nullable             bool               `json:"nullable,omitempty"`
+	ReadOnly             bool               `json:"readOnly,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+}
+
+// Operation описывает маршрут API. Схемы тел запроса и ответа строятся по типам
+// значений Request и Response, поэтому спецификация следует за пакетом models.
+type Operation struct {
+	Method  string
+	Path    string
+	ID      string
+	Summary string
+	Tag     string
+	Query   []Parameter
+
+	Request  any
+	Status   int
+	Response any
+	// ContentTypes - типы успешного ответа, если он не JSON.
+	ContentTypes []string
+	// Errors - коды ошибок, которые операция возвращает в формате problem+json.
+	Errors []int
+}
+
+var pathParamPattern = regexp.MustCompile(`\{([A-Za-z_]+)\}`)
+
+// Build собирает документ OpenAPI по операциям API, доступного по адресу serverURL.
+func Build(info Info, serverURL string, ops []Operation) *Document {
+	doc := &Document{
+		OpenAPI:    Version,
+		Info:       info,
+		Servers:    []Server{{URL: serverURL}},
+		Paths:      make(map[string]PathItem),
+		Components: Components{Schemas: make(map[string]*Schema)},
+	}
+	problem := doc.schemaFor(reflect.TypeOf(apierror.Problem{}))
+
+	for _, op := range ops {
+		item, ok := doc.Paths[op.Path]
+		if !ok {
+			item = make(PathItem)
+			doc.Paths[op.Path] = item
+		}
+
+		o := &OperationObject{
+			OperationID: op.ID,
+			Summary:     op.Summary,
+			Responses:   make(map[string]*Response),
+		}
+		if op.Tag != "" {
+			o.Tags = []string{op.Tag}
+		}
+		for _, m := range pathParamPattern.FindAllStringSubmatch(op.Path, -1) {
+			o.Parameters = append(o.Parameters, Parameter{Name: m[1], In: "path", Required: true, Schema: &Schema{Type: "string"}})
+		}
+		o.Parameters = append(o.Parameters, op.Query...)
+
+		if op.Request != nil {
+			o.RequestBody = &RequestBody{Required: true, Content: map[string]MediaType{
+				"application/json": {Schema: doc.schemaFor(reflect.TypeOf(op.Request))},
+			}}
+		}
+
+		success := &Response{Description: http.StatusText(op.Status)}
+		switch {
+		case len(op.ContentTypes) > 0:
+			success.Content = make(map[string]MediaType)
+			for _, ct := range op.ContentTypes {
+				success.Content[ct] = MediaType{Schema: &Schema{Type: "string", Format: "binary"}}
+			}
+		case op.Response != nil:
+			success.Content = map[string]MediaType{
+				"application/json": {Schema: doc.schemaFor(reflect.TypeOf(op.Response))},
+			}
+		}
+		o.Responses[strconv.Itoa(op.Status)] = success
+
+		for _, status := range append(op.Errors, http.StatusInternalServerError) {
+			o.Responses[strconv.Itoa(status)] = &Response{
+				Description: http.StatusText(status),
+				Content:     map[string]MediaType{apierror.ContentType: {Schema: problem}},
+			}
+		}
+
+		item[strings.ToLower(op.Method)] = o
+	}
+
+	return doc
+}
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// schemaFor строит схему типа Go по правилам encoding/json. Структуры выносятся
+// в components/schemas под своим именем. Поля с тегом openapi:"readonly" помечаются
+// readOnly: они обязательны только в ответах.
+func (d *Document) schemaFor(t reflect.Type) *Schema {
+	switch {
+	case t == timeType:
+		return &Schema{Type: "string", Format: "date-time"}
+	case t.Kind() == reflect.Pointer:
+		s := d.schemaFor(t.Elem())
+		if s.Ref != "" {
+			return s
+		}
+		s.Nullable = true
+		return s
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32:
+		return &Schema{Type: "integer"}
+	case reflect.Int64, reflect.Uint64:
+		return &Schema{Type: "integer", Format: "int64"}
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: "number"}
+	case reflect.Slice, reflect.Array:
+		return &Schema{Type: "array", Items: d.schemaFor(t.Elem())}
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: d.schemaFor(t.Elem())}
+	case reflect.Struct:
+		name := t.Name()
+		if _, ok := d.Components.Schemas[name]; !ok {
+			s := &Schema{Type: "object", Properties: make(map[string]*Schema)}
+			d.Components.Schemas[name] = s
+			d.addFields(s, t)
+		}
+		return &Schema{Ref: "#/components/schemas/" + name}
+	}
+	return &Schema{}
+}
+
+func (d *Document) addFields(s *Schema, t reflect.Type) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, opts, _ := strings.Cut(tag, ",")
+		if field.Anonymous && name == "" && field.Type.Kind() == reflect.Struct {
+			d.addFields(s, field.Type)
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+
+		prop := d.schemaFor(field.Type)
+		if field.Tag.Get("openapi") == "readonly" {
+			prop.ReadOnly = true
+		}
+		s.Properties[name] = prop
+		if !strings.Contains(opts, "omitempty") && field.Type.Kind() != reflect.Pointer {
+			s.Required = append(s.Required, name)
+		}
+	}
+}
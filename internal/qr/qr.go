@@ -0,0 +1,203 @@
+package qr
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"strconv"
+	"strings"
+
+	qrcode "github.com/skip2/go-qrcode"
+)
+
+const (
+	FormatPNG = "png"
+	FormatSVG = "svg"
+
+	DefaultSize   = 256
+	MinSize       = 64
+	MaxSize       = 2048
+	DefaultMargin = 4
+	MaxMargin     = 16
+)
+
+type Options struct {
+	Format     string
+	Size       int
+	Level      string
+	Foreground color.NRGBA
+	Background color.NRGBA
+	Margin     int
+}
+
+func DefaultOptions() Options {
+	return Options{
+		Format:     FormatPNG,
+		Size:       DefaultSize,
+		Level:      "M",
+		Foreground: color.NRGBA{A: 0xff},
+		Background: color.NRGBA{R: 0xff, G: 0xff, B: 0xff, A: 0xff},
+		Margin:     DefaultMargin,
+	}
+}
+
+func (o Options) Validate() error {
+	if o.Format != FormatPNG && o.Format != FormatSVG {
+		return fmt.Errorf("формат должен быть png или svg")
+	}
+	if o.Size < MinSize || o.Size > MaxSize {
+		return fmt.Errorf("размер должен быть от %d до %d", MinSize, MaxSize)
+	}
+	if _, err := recoveryLevel(o.Level); err != nil {
+		return err
+	}
+	if o.Margin < 0 || o.Margin > MaxMargin {
+		return fmt.Errorf("отступ должен быть от 0 до %d", MaxMargin)
+	}
+	return nil
+}
+
+func (o Options) ContentType() string {
+	if o.Format == FormatSVG {
+		return "image/svg+xml"
+	}
+	return "image/png"
+}
+
+// SizeError означает, что модули QR-кода вместе с отступом не помещаются в Size.
+type SizeError struct {
+	// Min - наименьший размер, в который помещается код.
+	Min int
+}
+
+func (e *SizeError) Error() string {
+	return fmt.Sprintf("QR-код не помещается в заданный размер, нужно не меньше %d", e.Min)
+}
+
+// Render кодирует content в QR-код. Размер модуля целый, поэтому итоговая
+// сторона изображения может быть меньше Size, но не больше его: если код с
+// отступом не помещается в Size, возвращается *SizeError.
+func Render(content string, o Options) ([]byte, error) {
+	if err := o.Validate(); err != nil {
+		return nil, err
+	}
+
+	level, _ := recoveryLevel(o.Level)
+	code, err := qrcode.New(content, level)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при создании QR-кода: %w", err)
+	}
+	code.DisableBorder = true
+	bitmap := code.Bitmap()
+
+	total := len(bitmap) + 2*o.Margin
+	if total > o.Size {
+		return nil, &SizeError{Min: total}
+	}
+	scale := o.Size / total
+
+	if o.Format == FormatSVG {
+		return renderSVG(bitmap, o, total, scale), nil
+	}
+	return renderPNG(bitmap, o, total, scale)
+}
+
+func renderPNG(bitmap [][]bool, o Options, total, scale int) ([]byte, error) {
+	side := total * scale
+	img := image.NewNRGBA(image.Rect(0, 0, side, side))
+	for y := 0; y < side; y++ {
+		for x := 0; x < side; x++ {
+			img.SetNRGBA(x, y, o.Background)
+		}
+	}
+
+	for row, modules := range bitmap {
+		for col, dark := range modules {
+			if !dark {
+				continue
+			}
+			x0, y0 := (col+o.Margin)*scale, (row+o.Margin)*scale
+			for y := y0; y < y0+scale; y++ {
+				for x := x0; x < x0+scale; x++ {
+					img.SetNRGBA(x, y, o.Foreground)
+				}
+			}
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, fmt.Errorf("ошибка при кодировании PNG: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+func renderSVG(bitmap [][]bool, o Options, total, scale int) []byte {
+	var path strings.Builder
+	for row, modules := range bitmap {
+		for col, dark := range modules {
+			if dark {
+				fmt.Fprintf(&path, "M%d %dh1v1h-1z", col+o.Margin, row+o.Margin)
+			}
+		}
+	}
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, `<?xml version="1.0" encoding="UTF-8"?>`+"\n")
+	fmt.Fprintf(&buf, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d" shape-rendering="crispEdges">`,
+		total*scale, total*scale, total, total)
+	fmt.Fprintf(&buf, `<rect width="%d" height="%d" fill="%s"%s/>`, total, total, hexColor(o.Background), opacity(o.Background))
+	fmt.Fprintf(&buf, `<path d="%s" fill="%s"%s/>`, path.String(), hexColor(o.Foreground), opacity(o.Foreground))
+	buf.WriteString("</svg>\n")
+	return buf.Bytes()
+}
+
+// IsValidLevel сообщает, поддерживается ли уровень коррекции ошибок.
+func IsValidLevel(level string) bool {
+	_, err := recoveryLevel(level)
+	return err == nil
+}
+
+func recoveryLevel(level string) (qrcode.RecoveryLevel, error) {
+	switch strings.ToUpper(level) {
+	case "L":
+		return qrcode.Low, nil
+	case "M":
+		return qrcode.Medium, nil
+	case "Q":
+		return qrcode.High, nil
+	case "H":
+		return qrcode.Highest, nil
+	}
+	return 0, fmt.Errorf("уровень коррекции должен быть L, M, Q или H")
+}
+
+// ParseColor разбирает цвет в формате RRGGBB или RRGGBBAA, с необязательным #.
+func ParseColor(s string) (color.NRGBA, error) {
+	hex := strings.TrimPrefix(s, "#")
+	if len(hex) != 6 && len(hex) != 8 {
+		return color.NRGBA{}, fmt.Errorf("некорректный цвет %q", s)
+	}
+	if len(hex) == 6 {
+		hex += "ff"
+	}
+
+	value, err := strconv.ParseUint(hex, 16, 32)
+	if err != nil {
+		return color.NRGBA{}, fmt.Errorf("некорректный цвет %q", s)
+	}
+	return color.NRGBA{R: uint8(value >> 24), G: uint8(value >> 16), B: uint8(value >> 8), A: uint8(value)}, nil
+}
+
+func hexColor(c color.NRGBA) string {
+	return fmt.Sprintf("#%02x%02x%02x", c.R, c.G, c.B)
+}
+
+func opacity(c color.NRGBA) string {
+	if c.A == 0xff {
+		return ""
+	}
+	return fmt.Sprintf(` fill-opacity="%.3f"`, float64(c.A)/0xff)
+}
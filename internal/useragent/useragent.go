@@ -0,0 +1,80 @@
+package useragent
+
+import "strings"
+
+const (
+	OSIOS     = "ios"
+	OSAndroid = "android"
+	OSWindows = "windows"
+	OSMacOS   = "macos"
+	OSLinux   = "linux"
+	OSOther   = "other"
+
+	DeviceMobile  = "mobile"
+	DeviceTablet  = "tablet"
+	DeviceDesktop = "desktop"
+)
+
+var botMarkers = []string{
+	"bot", "crawler", "spider", "slurp", "facebookexternalhit", "embedly",
+	"preview", "curl/", "wget/", "python-requests", "go-http-client", "headless",
+}
+
+type Info struct {
+	OS     string
+	Device string
+	Bot    bool
+}
+
+func Parse(ua string) Info {
+	s := strings.ToLower(ua)
+	info := Info{OS: OSOther, Device: DeviceDesktop}
+
+	switch {
+	case strings.Contains(s, "iphone") || strings.Contains(s, "ipod"):
+		info.OS, info.Device = OSIOS, DeviceMobile
+	case strings.Contains(s, "ipad"):
+		info.OS, info.Device = OSIOS, DeviceTablet
+	case strings.Contains(s, "android"):
+		info.OS = OSAndroid
+		if strings.Contains(s, "mobile") {
+			info.Device = DeviceMobile
+		} else {
+			info.Device = DeviceTablet
+		}
+	case strings.Contains(s, "windows"):
+		info.OS = OSWindows
+	case strings.Contains(s, "macintosh") || strings.Contains(s, "mac os x"):
+		info.OS = OSMacOS
+	case strings.Contains(s, "linux") || strings.Contains(s, "x11"):
+		info.OS = OSLinux
+	}
+
+	if s == "" {
+		info.Bot = true
+	}
+	for _, marker := range botMarkers {
+		if strings.Contains(s, marker) {
+			info.Bot = true
+			break
+		}
+	}
+
+	return info
+}
+
+func IsValidOS(os string) bool {
+	switch os {
+	case OSIOS, OSAndroid, OSWindows, OSMacOS, OSLinux, OSOther:
+		return true
+	}
+	return false
+}
+
+func IsValidDevice(device string) bool {
+	switch device {
+	case DeviceMobile, DeviceTablet, DeviceDesktop:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,219 @@
+package utils
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// TrustedProxies - адреса обратных прокси, чьим заголовкам Forwarded и X-Forwarded-*
+// можно доверять. Заголовки от остальных клиентов игнорируются.
+type TrustedProxies []*net.IPNet
+
+// ParseTrustedProxies разбирает список IP-адресов и подсетей CIDR через запятую.
+func ParseTrustedProxies(s string) (TrustedProxies, error) {
+	var proxies TrustedProxies
+	for _, item := range strings.Split(s, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		if !strings.Contains(item, "/") {
+			ip := net.ParseIP(item)
+			if ip == nil {
+				return nil, fmt.Errorf("некорректный адрес прокси: %s", item)
+			}
+			bits := 8 * net.IPv6len
+			if ip.To4() != nil {
+				ip, bits = ip.To4(), 8*net.IPv4len
+			}
+			proxies = append(proxies, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+			continue
+		}
+		_, network, err := net.ParseCIDR(item)
+		if err != nil {
+			return nil, fmt.Errorf("некорректная подсеть прокси: %s", item)
+		}
+		proxies = append(proxies, network)
+	}
+	return proxies, nil
+}
+
+func (t TrustedProxies) Contains(ip net.IP) bool {
+	if ip == nil {
+		return false
+	}
+	for _, network := range t {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// PublicOrigin возвращает схему и хост, с которыми клиент обратился к сервису.
+// Заголовки прокси учитываются, только если запрос пришел от доверенного прокси;
+// Forwarded имеет приоритет над X-Forwarded-Proto и X-Forwarded-Host.
+func PublicOrigin(r *http.Request, trusted TrustedProxies) (scheme, host string) {
+	scheme, host = "http", r.Host
+	if r.TLS != nil {
+		scheme = "https"
+	}
+
+	if !trusted.Contains(RemoteIP(r)) {
+		return scheme, host
+	}
+
+	if proto, fwdHost, ok := parseForwarded(r.Header.Values("Forwarded"), trusted); ok {
+		if proto != "" {
+			scheme = proto
+		}
+		if fwdHost != "" {
+			host = fwdHost
+		}
+		return scheme, host
+	}
+
+	if proto := lastListValue(r.Header.Values("X-Forwarded-Proto")); validForwardedProto(proto) {
+		scheme = strings.ToLower(proto)
+	}
+	if fwdHost := lastListValue(r.Header.Values("X-Forwarded-Host")); validForwardedHost(fwdHost) {
+		host = fwdHost
+	}
+	return scheme, host
+}
+
+// ClientIP возвращает адрес посетителя. Если соединение пришло от доверенного
+// прокси, адрес берется из Forwarded или, если его нет, из X-Forwarded-For:
+// цепочка просматривается справа налево и возвращается первый адрес не из
+// trusted. Левее него значения мог подставить сам клиент. Если все адреса
+// цепочки доверенные, возвращается самый левый; если адрес скрыт (for=unknown),
+// возвращается nil.
+func ClientIP(r *http.Request, trusted TrustedProxies) net.IP {
+	ip := RemoteIP(r)
+	if !trusted.Contains(ip) {
+		return ip
+	}
+
+	var hops []string
+	if values := r.Header.Values("Forwarded"); len(values) > 0 {
+		for _, value := range values {
+			for _, element := range splitQuoted(value, ',') {
+				node := ""
+				for _, pair := range splitQuoted(element, ';') {
+					if key, val, found := strings.Cut(strings.TrimSpace(pair), "="); found && strings.EqualFold(key, "for") {
+						node = strings.Trim(val, `"`)
+					}
+				}
+				hops = append(hops, node)
+			}
+		}
+	} else {
+		for _, value := range r.Header.Values("X-Forwarded-For") {
+			for _, hop := range strings.Split(value, ",") {
+				hops = append(hops, strings.TrimSpace(hop))
+			}
+		}
+	}
+
+	for i := len(hops) - 1; i >= 0; i-- {
+		if ip = forwardedNodeIP(hops[i]); !trusted.Contains(ip) {
+			return ip
+		}
+	}
+	return ip
+}
+
+// parseForwarded выбирает элемент заголовка Forwarded (RFC 7239), добавленный
+// ближайшим к клиенту доверенным прокси: элементы просматриваются справа налево,
+// пока адрес в for= принадлежит доверенному прокси. Левее этой точки значения
+// мог подставить сам клиент.
+func parseForwarded(values []string, trusted TrustedProxies) (proto, host string, ok bool) {
+	var elements []map[string]string
+	for _, value := range values {
+		for _, element := range splitQuoted(value, ',') {
+			params := map[string]string{}
+			for _, pair := range splitQuoted(element, ';') {
+				key, val, found := strings.Cut(strings.TrimSpace(pair), "=")
+				if !found {
+					continue
+				}
+				params[strings.ToLower(key)] = strings.Trim(val, `"`)
+			}
+			elements = append(elements, params)
+		}
+	}
+	if len(elements) == 0 {
+		return "", "", false
+	}
+
+	i := len(elements) - 1
+	for i > 0 && trusted.Contains(forwardedNodeIP(elements[i]["for"])) {
+		i--
+	}
+
+	if p := elements[i]["proto"]; validForwardedProto(p) {
+		proto = strings.ToLower(p)
+	}
+	if h := elements[i]["host"]; validForwardedHost(h) {
+		host = h
+	}
+	return proto, host, true
+}
+
+func forwardedNodeIP(node string) net.IP {
+	if strings.HasPrefix(node, "[") {
+		if end := strings.Index(node, "]"); end > 0 {
+			return net.ParseIP(node[1:end])
+		}
+		return nil
+	}
+	if host, _, err := net.SplitHostPort(node); err == nil {
+		node = host
+	}
+	return net.ParseIP(node)
+}
+
+func splitQuoted(s string, sep rune) []string {
+	var parts []string
+	start, quoted := 0, false
+	for i, c := range s {
+		switch {
+		case c == '"':
+			quoted = !quoted
+		case c == sep && !quoted:
+			parts = append(parts, s[start:i])
+			start = i + 1
+		}
+	}
+	return append(parts, s[start:])
+}
+
+func lastListValue(values []string) string {
+	if len(values) == 0 {
+		return ""
+	}
+	items := strings.Split(values[len(values)-1], ",")
+	return strings.TrimSpace(items[len(items)-1])
+}
+
+func validForwardedProto(proto string) bool {
+	proto = strings.ToLower(proto)
+	return proto == "http" || proto == "https"
+}
+
+func validForwardedHost(host string) bool {
+	if host == "" || strings.ContainsAny(host, "/\\@?# \t") {
+		return false
+	}
+	u, err := url.Parse("http://" + host)
+	return err == nil && u.Host == host
+}
+
+// PublicBaseURL возвращает адрес сервиса вида scheme://host для ответа клиенту.
+func PublicBaseURL(r *http.Request, trusted TrustedProxies) string {
+	scheme, host := PublicOrigin(r, trusted)
+	return fmt.Sprintf("%s://%s", scheme, host)
+}
@@ -2,8 +2,10 @@ package utils
 
 import (
 	"crypto/rand"
-	"fmt"
+	"net"
 	"net/http"
+	"net/url"
+	"strings"
 )
 
 func GenerateRandomCode(length int) string {
@@ -24,9 +26,124 @@ func GenerateRandomCode(length int) string {
 }
 
 func GetHost(r *http.Request) string {
-	scheme := "http"
-	if r.TLS != nil {
-		scheme = "https"
+	return PublicBaseURL(r, nil)
+}
+
+func IsValidRedirectType(code int) bool {
+	switch code {
+	case http.StatusMovedPermanently, http.StatusFound, http.StatusTemporaryRedirect, http.StatusPermanentRedirect:
+		return true
+	}
+	return false
+}
+
+func IsPermanentRedirect(code int) bool {
+	return code == http.StatusMovedPermanently || code == http.StatusPermanentRedirect
+}
+
+// IsHTTPURL сообщает, что s - абсолютный URL со схемой http или https и хостом.
+func IsHTTPURL(s string) bool {
+	u, err := url.Parse(s)
+	return err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != ""
+}
+
+// AppendPassthrough дописывает к destination хвост пути и параметры запроса,
+// пришедшие на короткую ссылку. Параметры, уже заданные в destination,
+// имеют приоритет и не перезаписываются.
+func AppendPassthrough(destination, escapedPath, rawQuery string) (string, error) {
+	u, err := url.Parse(destination)
+	if err != nil {
+		return "", err
+	}
+
+	if tail := strings.Trim(escapedPath, "/"); tail != "" {
+		joined := strings.TrimSuffix(u.EscapedPath(), "/") + "/" + tail
+		if strings.HasSuffix(escapedPath, "/") {
+			joined += "/"
+		}
+		path, err := url.PathUnescape(joined)
+		if err != nil {
+			return "", err
+		}
+		u.Path = path
+		u.RawPath = joined
+	}
+
+	if rawQuery != "" {
+		incoming, err := url.ParseQuery(rawQuery)
+		if err != nil {
+			return "", err
+		}
+		existing := u.Query()
+		extra := url.Values{}
+		for key, values := range incoming {
+			if _, ok := existing[key]; ok {
+				continue
+			}
+			extra[key] = values
+		}
+		if encoded := extra.Encode(); encoded != "" {
+			if u.RawQuery != "" {
+				u.RawQuery += "&" + encoded
+			} else {
+				u.RawQuery = encoded
+			}
+		}
+	}
+
+	return u.String(), nil
+}
+
+// ApplyQueryParams выставляет params в destination, перезаписывая совпадающие параметры.
+func ApplyQueryParams(destination string, params map[string]string) (string, error) {
+	u, err := url.Parse(destination)
+	if err != nil {
+		return "", err
+	}
+
+	query := u.Query()
+	for key, value := range params {
+		query.Set(key, value)
+	}
+	u.RawQuery = query.Encode()
+
+	return u.String(), nil
+}
+
+func QueryParam(rawURL, key string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return ""
+	}
+	return u.Query().Get(key)
+}
+
+// WeightedIndex возвращает индекс веса, на который попадает roll из [0, sum(weights)).
+func WeightedIndex(weights []int, roll int) int {
+	for i, weight := range weights {
+		if roll < weight {
+			return i
+		}
+		roll -= weight
+	}
+	return len(weights) - 1
+}
+
+// RemoteIP возвращает адрес, с которого пришло соединение: клиента или прокси.
+func RemoteIP(r *http.Request) net.IP {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	return net.ParseIP(host)
+}
+
+// Route возвращает шаблон пути, которым mux обработает запрос, без метода и хоста
+// (например, /api/v1/links/{code}), или пустую строку, если маршрут не найден.
+func Route(mux *http.ServeMux, r *http.Request) string {
+	_, pattern := mux.Handler(r)
+	if i := strings.Index(pattern, "/"); i > 0 {
+		return pattern[i:]
 	}
-	return fmt.Sprintf("%s://%s", scheme, r.Host)
+	return pattern
 }
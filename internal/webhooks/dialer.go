@@ -0,0 +1,54 @@
+package webhooks
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"syscall"
+	"time"
+)
+
+// ErrForbiddenDestination - адрес получателя во внутренней сети. Такие адреса
+// проверяются при каждом соединении, уже после разрешения имени, поэтому их не
+// обойти DNS-записью или перенаправлением.
+var ErrForbiddenDestination = errors.New("доставка вебхуков во внутреннюю сеть запрещена")
+
+// sharedAddressSpace - диапазон RFC 6598, который облачные провайдеры используют
+// для внутренних адресов.
+var sharedAddressSpace = netip.MustParsePrefix("100.64.0.0/10")
+
+// newHTTPClient создает клиент доставки. Соединения с внутренними адресами
+// отклоняются, если allowPrivate не разрешает их.
+func newHTTPClient(allowPrivate func() bool) *http.Client {
+	dialer := &net.Dialer{
+		Timeout:   10 * time.Second,
+		KeepAlive: 30 * time.Second,
+		Control: func(network, address string, _ syscall.RawConn) error {
+			if allowPrivate() {
+				return nil
+			}
+			return checkDestination(address)
+		},
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	// Через прокси соединение идет с ним, а не с получателем, и проверка адреса
+	// теряет смысл.
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+	return &http.Client{Timeout: 10 * time.Second, Transport: transport}
+}
+
+func checkDestination(address string) error {
+	addrPort, err := netip.ParseAddrPort(address)
+	if err != nil {
+		return err
+	}
+	ip := addrPort.Addr().Unmap()
+	if ip.IsLoopback() || ip.IsPrivate() || ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() ||
+		ip.IsMulticast() || ip.IsUnspecified() || sharedAddressSpace.Contains(ip) {
+		return fmt.Errorf("%w: %s", ErrForbiddenDestination, ip)
+	}
+	return nil
+}
@@ -0,0 +1,451 @@
+// Package webhooks рассылает события жизненного цикла ссылок подписчикам. События
+// сохраняются в очереди в базе и доставляются фоновым обработчиком с повторами.
+package webhooks
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"tinyurl/internal/db"
+	"tinyurl/internal/logging"
+	"tinyurl/internal/models"
+)
+
+// Типы событий.
+const (
+	LinkCreated = "link.created"
+	LinkUpdated = "link.updated"
+	LinkDeleted = "link.deleted"
+	LinkExpired = "link.expired"
+	LinkClicked = "link.clicked"
+)
+
+// Заголовки запроса доставки.
+const (
+	HeaderEvent     = "X-TinyURL-Event"
+	HeaderDelivery  = "X-TinyURL-Delivery"
+	HeaderTimestamp = "X-TinyURL-Timestamp"
+	HeaderSignature = "X-TinyURL-Signature"
+)
+
+// EventTypes возвращает все типы событий, на которые можно подписаться.
+func EventTypes() []string {
+	return []string{LinkCreated, LinkUpdated, LinkDeleted, LinkExpired, LinkClicked}
+}
+
+func IsValidEventType(event string) bool {
+	return slices.Contains(EventTypes(), event)
+}
+
+// Event - тело запроса доставки.
+type Event struct {
+	ID        string    `json:"id"`
+	Type      string    `json:"type"`
+	CreatedAt time.Time `json:"created_at"`
+	Data      EventData `json:"data"`
+}
+
+// EventData описывает ссылку, к которой относится событие. Dimensions заполняется
+// для link.clicked теми же измерениями, что и статистика переходов.
+type EventData struct {
+	Code       string            `json:"code"`
+	Domain     string            `json:"domain,omitempty"`
+	URL        string            `json:"url"`
+	ExpiresAt  *time.Time        `json:"expires_at,omitempty"`
+	HitCount   int64             `json:"hit_count"`
+	Dimensions map[string]string `json:"dimensions,omitempty"`
+}
+
+// LinkData собирает данные события по ссылке домена host.
+func LinkData(host string, link *models.Link) EventData {
+	return EventData{Code: link.Code, Domain: host, URL: link.URL, ExpiresAt: link.ExpiresAt, HitCount: link.HitCount}
+}
+
+// Sign возвращает подпись тела запроса: HMAC-SHA256 секрета подписки от строки
+// "<timestamp>.<body>" в виде sha256=<hex>. Метка времени входит в подпись, чтобы
+// получатель мог отклонять повторно отправленные старые запросы.
+func Sign(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	fmt.Fprintf(mac, "%d.", timestamp)
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify проверяет подпись запроса доставки.
+func Verify(secret string, timestamp int64, body []byte, signature string) bool {
+	return hmac.Equal([]byte(Sign(secret, timestamp, body)), []byte(signature))
+}
+
+// NewSecret создает случайный секрет подписки.
+func NewSecret() string {
+	return "whsec_" + randomHex(24)
+}
+
+func randomHex(n int) string {
+	b := make([]byte, n)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+const (
+	defaultMaxAttempts = 8
+	defaultBaseDelay   = 30 * time.Second
+	defaultMaxDelay    = 6 * time.Hour
+	defaultInterval    = time.Second
+	defaultSweepEvery  = time.Minute
+	defaultWorkers     = 4
+	defaultPerWebhook  = 10
+	defaultRetention   = 7 * 24 * time.Hour
+	batchSize          = 50
+	sweepBatchSize     = 500
+	pruneEvery         = time.Hour
+	maxErrorLength     = 512
+	// webhooksCacheTTL ограничивает, как долго подписки берутся из кэша, если их
+	// изменили в обход InvalidateWebhooks, например другим процессом.
+	webhooksCacheTTL = 10 * time.Second
+)
+
+type Dispatcher struct {
+	DB     *sql.DB
+	Client *http.Client
+	// MaxAttempts - число попыток, после которого доставка переходит в dead.
+	MaxAttempts int
+	// BaseDelay - пауза перед второй попыткой; каждая следующая пауза вдвое
+	// длиннее, но не больше MaxDelay.
+	BaseDelay time.Duration
+	MaxDelay  time.Duration
+	// Interval - период опроса очереди, SweepEvery - период поиска истекших ссылок.
+	Interval   time.Duration
+	SweepEvery time.Duration
+	// Workers - сколько вебхуков обслуживается одновременно. Доставки одного
+	// вебхука отправляются по очереди, поэтому медленный получатель занимает
+	// только один обработчик.
+	Workers int
+	// PerWebhook - сколько доставок одного вебхука отправляется за проход.
+	PerWebhook int
+	// Retention - сколько хранятся доставленные и dead доставки с журналом попыток.
+	Retention time.Duration
+	// AllowPrivate разрешает доставку на адреса loopback, link-local и частных
+	// сетей. По умолчанию они отклоняются, чтобы подписка не открывала доступ к
+	// внутренним сервисам.
+	AllowPrivate bool
+
+	// cacheMu защищает кэш подписок по типу события.
+	cacheMu  sync.Mutex
+	webhooks map[string]cachedWebhooks
+	// recordMu упорядочивает запись попыток: SQLite не допускает параллельных
+	// транзакций записи.
+	recordMu sync.Mutex
+}
+
+type cachedWebhooks struct {
+	webhooks []models.Webhook
+	loadedAt time.Time
+}
+
+func NewDispatcher(database *sql.DB) *Dispatcher {
+	d := &Dispatcher{
+		DB:          database,
+		MaxAttempts: defaultMaxAttempts,
+		BaseDelay:   defaultBaseDelay,
+		MaxDelay:    defaultMaxDelay,
+		Interval:    defaultInterval,
+		SweepEvery:  defaultSweepEvery,
+		Workers:     defaultWorkers,
+		PerWebhook:  defaultPerWebhook,
+		Retention:   defaultRetention,
+	}
+	d.Client = newHTTPClient(func() bool { return d.AllowPrivate })
+	return d
+}
+
+// Publish ставит событие в очередь доставки для каждой подписки на его тип.
+func (d *Dispatcher) Publish(ctx context.Context, eventType string, data EventData) error {
+	webhooks, err := d.subscribers(ctx, eventType)
+	if err != nil || len(webhooks) == 0 {
+		return err
+	}
+
+	now := time.Now()
+	event := Event{ID: "evt_" + randomHex(12), Type: eventType, CreatedAt: now.UTC(), Data: data}
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	deliveries := make([]models.WebhookDelivery, len(webhooks))
+	for i, w := range webhooks {
+		deliveries[i] = models.WebhookDelivery{WebhookID: w.ID, EventID: event.ID, Event: eventType, Payload: string(payload)}
+	}
+	return db.InsertDeliveries(ctx, d.DB, deliveries, now)
+}
+
+// InvalidateWebhooks сбрасывает кэш подписок. Вызывается после создания и
+// удаления вебхука, чтобы следующее событие учло изменение.
+func (d *Dispatcher) InvalidateWebhooks() {
+	d.cacheMu.Lock()
+	defer d.cacheMu.Unlock()
+	d.webhooks = nil
+}
+
+// subscribers возвращает подписки на тип события из кэша. Подписки читаются под
+// блокировкой, поэтому InvalidateWebhooks не может потеряться во время чтения.
+func (d *Dispatcher) subscribers(ctx context.Context, eventType string) ([]models.Webhook, error) {
+	d.cacheMu.Lock()
+	defer d.cacheMu.Unlock()
+
+	if cached, ok := d.webhooks[eventType]; ok && time.Since(cached.loadedAt) < webhooksCacheTTL {
+		return cached.webhooks, nil
+	}
+	webhooks, err := db.WebhooksForEvent(ctx, d.DB, eventType)
+	if err != nil {
+		return nil, err
+	}
+	if d.webhooks == nil {
+		d.webhooks = make(map[string]cachedWebhooks)
+	}
+	d.webhooks[eventType] = cachedWebhooks{webhooks: webhooks, loadedAt: time.Now()}
+	return webhooks, nil
+}
+
+// Backoff возвращает паузу после неудачной попытки с номером attempt (с 1).
+func (d *Dispatcher) Backoff(attempt int) time.Duration {
+	delay := d.BaseDelay
+	for i := 1; i < attempt && delay < d.MaxDelay; i++ {
+		delay *= 2
+	}
+	return min(delay, d.MaxDelay)
+}
+
+// Run обрабатывает очередь, ищет истекшие ссылки и удаляет старые доставки до
+// отмены ctx.
+func (d *Dispatcher) Run(ctx context.Context) {
+	logger := logging.FromContext(ctx)
+	ticker := time.NewTicker(d.Interval)
+	defer ticker.Stop()
+
+	var lastSweep, lastPrune time.Time
+	for {
+		if time.Since(lastSweep) >= d.SweepEvery {
+			if _, err := d.SweepExpired(ctx); err != nil {
+				logger.Error("Ошибка при поиске истекших ссылок", "error", err)
+			}
+			lastSweep = time.Now()
+		}
+		if time.Since(lastPrune) >= pruneEvery {
+			if _, err := d.Prune(ctx); err != nil {
+				logger.Error("Ошибка при очистке доставок вебхуков", "error", err)
+			}
+			lastPrune = time.Now()
+		}
+		if _, err := d.DeliverDue(ctx); err != nil {
+			logger.Error("Ошибка при доставке вебхуков", "error", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Prune удаляет доставленные и dead доставки старше Retention и возвращает их число.
+func (d *Dispatcher) Prune(ctx context.Context) (int64, error) {
+	return db.PruneDeliveries(ctx, d.DB, time.Now().Add(-d.Retention))
+}
+
+// DeliverDue отправляет до batchSize доставок, время попытки которых наступило,
+// и возвращает число отправленных запросов. Вебхуки обслуживаются параллельно
+// Workers обработчиками, каждому - не больше PerWebhook доставок за проход.
+func (d *Dispatcher) DeliverDue(ctx context.Context) (int, error) {
+	deliveries, err := db.DueDeliveries(ctx, d.DB, time.Now(), batchSize, max(d.PerWebhook, 1))
+	if err != nil {
+		return 0, err
+	}
+
+	var order []int64
+	queues := make(map[int64][]models.WebhookDelivery)
+	for _, delivery := range deliveries {
+		if _, ok := queues[delivery.WebhookID]; !ok {
+			order = append(order, delivery.WebhookID)
+		}
+		queues[delivery.WebhookID] = append(queues[delivery.WebhookID], delivery)
+	}
+
+	jobs := make(chan []models.WebhookDelivery)
+	var sent atomic.Int64
+	var errMu sync.Mutex
+	var errs []error
+	var wg sync.WaitGroup
+	for range min(max(d.Workers, 1), len(order)) {
+		wg.Go(func() {
+			for queue := range jobs {
+				n, err := d.deliverQueue(ctx, queue)
+				sent.Add(int64(n))
+				if err != nil {
+					errMu.Lock()
+					errs = append(errs, err)
+					errMu.Unlock()
+				}
+			}
+		})
+	}
+	for _, id := range order {
+		jobs <- queues[id]
+	}
+	close(jobs)
+	wg.Wait()
+
+	return int(sent.Load()), errors.Join(errs...)
+}
+
+// deliverQueue по очереди отправляет доставки одного вебхука.
+func (d *Dispatcher) deliverQueue(ctx context.Context, deliveries []models.WebhookDelivery) (int, error) {
+	w, err := db.GetWebhook(ctx, d.DB, deliveries[0].WebhookID)
+	if err != nil || w == nil {
+		return 0, err
+	}
+
+	sent := 0
+	for i := range deliveries {
+		if ctx.Err() != nil {
+			break
+		}
+		if err := d.attempt(ctx, w, &deliveries[i]); err != nil {
+			return sent, err
+		}
+		sent++
+	}
+	return sent, nil
+}
+
+// attempt отправляет доставку и сохраняет результат: delivered при ответе 2xx,
+// иначе новую попытку по Backoff или dead после MaxAttempts попыток.
+func (d *Dispatcher) attempt(ctx context.Context, w *models.Webhook, delivery *models.WebhookDelivery) error {
+	delivery.Attempts++
+	record := models.WebhookAttempt{Attempt: delivery.Attempts}
+
+	start := time.Now()
+	statusCode, err := d.send(ctx, w, delivery)
+	record.DurationMS = time.Since(start).Milliseconds()
+	record.StatusCode = statusCode
+
+	delivery.NextAttemptAt = nil
+	switch {
+	case err == nil && statusCode >= 200 && statusCode < 300:
+		delivery.Status = models.DeliveryDelivered
+	case err != nil:
+		record.Error = truncate(err.Error(), maxErrorLength)
+	default:
+		record.Error = http.StatusText(statusCode)
+	}
+	if delivery.Status != models.DeliveryDelivered {
+		if delivery.Attempts >= d.MaxAttempts {
+			delivery.Status = models.DeliveryDead
+		} else {
+			next := time.Now().Add(d.Backoff(delivery.Attempts))
+			delivery.NextAttemptAt = &next
+		}
+	}
+	delivery.LastStatusCode = record.StatusCode
+	delivery.LastError = record.Error
+
+	d.recordMu.Lock()
+	defer d.recordMu.Unlock()
+	return db.RecordAttempt(ctx, d.DB, delivery, record)
+}
+
+func (d *Dispatcher) send(ctx context.Context, w *models.Webhook, delivery *models.WebhookDelivery) (int, error) {
+	body := []byte(delivery.Payload)
+	timestamp := time.Now().Unix()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "TinyURL-Webhooks/1")
+	req.Header.Set(HeaderEvent, delivery.Event)
+	req.Header.Set(HeaderDelivery, strconv.FormatInt(delivery.ID, 10))
+	req.Header.Set(HeaderTimestamp, strconv.FormatInt(timestamp, 10))
+	req.Header.Set(HeaderSignature, Sign(w.Secret, timestamp, body))
+
+	resp, err := d.Client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	return resp.StatusCode, nil
+}
+
+// SweepExpired публикует link.expired для ссылок, истекших с момента создания самой
+// старой подписки на это событие, и возвращает число событий. Ссылки, истекшие
+// раньше, только отмечаются, чтобы новая подписка не получила старые события.
+func (d *Dispatcher) SweepExpired(ctx context.Context) (int, error) {
+	webhooks, err := d.subscribers(ctx, LinkExpired)
+	if err != nil || len(webhooks) == 0 {
+		return 0, err
+	}
+	since := webhooks[0].CreatedAt
+	for _, w := range webhooks[1:] {
+		if w.CreatedAt.Before(since) {
+			since = w.CreatedAt
+		}
+	}
+
+	links, err := db.ExpiredLinks(ctx, d.DB, time.Now(), sweepBatchSize)
+	if err != nil || len(links) == 0 {
+		return 0, err
+	}
+
+	domains, err := db.ListDomains(ctx, d.DB)
+	if err != nil {
+		return 0, err
+	}
+	hosts := make(map[int64]string, len(domains))
+	for _, domain := range domains {
+		hosts[domain.ID] = domain.Host
+	}
+
+	published := 0
+	for i := range links {
+		link := &links[i]
+		// created_at подписки хранится с точностью до секунды.
+		if !link.ExpiresAt.Before(since.Truncate(time.Second)) {
+			if err := d.Publish(ctx, LinkExpired, LinkData(hosts[link.DomainID], link)); err != nil {
+				return published, err
+			}
+			published++
+		}
+		if err := db.SetExpiryNotified(ctx, d.DB, link.ID, true); err != nil {
+			return published, err
+		}
+	}
+
+	return published, nil
+}
+
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	return s[:n]
+}
@@ -0,0 +1,268 @@
+// Package client - клиент HTTP API TinyURL (/api/v1) для Go-программ. Им же
+// пользуется CLI.
+//
+//	c := client.New("https://sho.rt")
+//	link, err := c.CreateLink(ctx, client.ShortenRequest{URL: "https://example.com"})
+//
+// Ошибки API возвращаются как *Error с кодом из ответа application/problem+json.
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// APIPrefix - префикс версии API, с которой работает клиент.
+const APIPrefix = "/api/v1"
+
+const (
+	defaultTimeout    = 10 * time.Second
+	defaultMaxRetries = 2
+	defaultBaseDelay  = 200 * time.Millisecond
+	defaultMaxDelay   = 5 * time.Second
+	problemType       = "application/problem+json"
+)
+
+// Client - клиент API. Поля можно менять после New, но не во время запросов.
+type Client struct {
+	// BaseURL - адрес сервера без /api/v1, например https://sho.rt.
+	BaseURL string
+	// Token отправляется в заголовке Authorization: Bearer, если задан.
+	Token string
+	// Language - значение Accept-Language: язык сообщений в ошибках API.
+	Language  string
+	UserAgent string
+	// HTTPClient выполняет запросы; его Timeout ограничивает каждую попытку.
+	HTTPClient *http.Client
+	// MaxRetries - сколько раз повторить идемпотентный запрос после ошибки сети,
+	// ответа 429, 502, 503 или 504. Запросы создания отправляются с
+	// Idempotency-Key и тоже повторяются; остальные POST и PATCH - нет.
+	MaxRetries int
+	// BaseDelay - пауза перед первым повтором; каждая следующая пауза вдвое
+	// длиннее, но не больше MaxDelay. Retry-After сервера имеет приоритет, а
+	// если он больше MaxDelay, запрос не повторяется.
+	BaseDelay time.Duration
+	MaxDelay  time.Duration
+}
+
+func New(baseURL string) *Client {
+	return &Client{
+		BaseURL:    strings.TrimRight(baseURL, "/"),
+		UserAgent:  "tinyurl-go-client",
+		HTTPClient: &http.Client{Timeout: defaultTimeout},
+		MaxRetries: defaultMaxRetries,
+		BaseDelay:  defaultBaseDelay,
+		MaxDelay:   defaultMaxDelay,
+	}
+}
+
+// Error - ответ сервера с неожиданным статусом. Для ответов application/problem+json
+// заполнен Problem, для остальных - Body.
+type Error struct {
+	StatusCode int
+	Problem    Problem
+	Body       string
+}
+
+func (e *Error) Error() string {
+	if e.Problem.Code != "" {
+		return fmt.Sprintf("tinyurl: %d %s", e.StatusCode, e.Problem.Error())
+	}
+	if e.Body != "" {
+		return fmt.Sprintf("tinyurl: %d %s", e.StatusCode, e.Body)
+	}
+	return fmt.Sprintf("tinyurl: %d %s", e.StatusCode, http.StatusText(e.StatusCode))
+}
+
+// StatusCode возвращает HTTP-статус ошибки API или 0, если err - не ошибка API.
+func StatusCode(err error) int {
+	var e *Error
+	if errors.As(err, &e) {
+		return e.StatusCode
+	}
+	return 0
+}
+
+// IsNotFound сообщает, что ссылка, домен, шаблон или вебхук не найдены.
+func IsNotFound(err error) bool {
+	return StatusCode(err) == http.StatusNotFound
+}
+
+// IsConflict сообщает, что алиас, домен или имя шаблона уже заняты либо
+// объект еще используется.
+func IsConflict(err error) bool {
+	return StatusCode(err) == http.StatusConflict
+}
+
+// Backoff возвращает паузу перед повтором с номером attempt (с 1).
+func (c *Client) Backoff(attempt int) time.Duration {
+	delay := c.BaseDelay
+	for i := 1; i < attempt && delay < c.MaxDelay; i++ {
+		delay *= 2
+	}
+	return min(delay, c.MaxDelay)
+}
+
+// call выполняет запрос к /api/v1 и декодирует JSON-ответ в out, если out не nil.
+func (c *Client) call(ctx context.Context, method, path string, query url.Values, in, out any, want int) error {
+	resp, err := c.send(ctx, method, APIPrefix+path, query, in, want)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if out == nil {
+		io.Copy(io.Discard, resp.Body)
+		return nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("tinyurl: decode %s %s: %w", method, path, err)
+	}
+	return nil
+}
+
+// send выполняет запрос с повторами и возвращает ответ со статусом want. Тело
+// ответа закрывает вызывающий.
+func (c *Client) send(ctx context.Context, method, path string, query url.Values, in any, want int) (*http.Response, error) {
+	var body []byte
+	if in != nil {
+		var err error
+		if body, err = json.Marshal(in); err != nil {
+			return nil, err
+		}
+	}
+	target := c.BaseURL + path
+	if len(query) > 0 {
+		target += "?" + query.Encode()
+	}
+	key := sendKey(ctx)
+
+	for attempt := 0; ; attempt++ {
+		resp, err := c.do(ctx, method, target, body)
+		if err == nil && resp.StatusCode == want {
+			return resp, nil
+		}
+
+		var delay time.Duration
+		if err == nil {
+			apiErr := readError(resp)
+			err, delay = apiErr, retryAfter(resp)
+			if !retryableStatus(resp.StatusCode) && !inProgress(apiErr, key) {
+				return nil, err
+			}
+		} else if ctx.Err() != nil {
+			return nil, err
+		}
+		if attempt >= c.MaxRetries || (!idempotent(method) && key == "") {
+			return nil, err
+		}
+
+		// Сервер просит подождать дольше, чем клиент готов: повтор бесполезен.
+		if delay > c.MaxDelay {
+			return nil, err
+		}
+		if delay <= 0 {
+			delay = c.Backoff(attempt + 1)
+		}
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, err
+		case <-timer.C:
+		}
+	}
+}
+
+func (c *Client) do(ctx context.Context, method, target string, body []byte) (*http.Response, error) {
+	var reader io.Reader
+	if body != nil {
+		reader = bytes.NewReader(body)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, target, reader)
+	if err != nil {
+		return nil, err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if c.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.Token)
+	}
+	if c.Language != "" {
+		req.Header.Set("Accept-Language", c.Language)
+	}
+	if c.UserAgent != "" {
+		req.Header.Set("User-Agent", c.UserAgent)
+	}
+	if key := sendKey(ctx); key != "" {
+		req.Header.Set(IdempotencyKeyHeader, key)
+	}
+
+	httpClient := c.HTTPClient
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	return httpClient.Do(req)
+}
+
+// readError читает ответ с ошибкой и закрывает его тело.
+func readError(resp *http.Response) *Error {
+	defer resp.Body.Close()
+	data, _ := io.ReadAll(io.LimitReader(resp.Body, 64<<10))
+
+	e := &Error{StatusCode: resp.StatusCode}
+	mediaType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	if mediaType == problemType && json.Unmarshal(data, &e.Problem) == nil && e.Problem.Code != "" {
+		return e
+	}
+	e.Body = strings.TrimSpace(string(data))
+	return e
+}
+
+func idempotent(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodPut, http.MethodDelete:
+		return true
+	}
+	return false
+}
+
+func retryableStatus(status int) bool {
+	switch status {
+	case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+// inProgress сообщает, что запрос с тем же ключом идемпотентности еще
+// выполняется: его стоит повторить позже, чтобы получить результат.
+func inProgress(e *Error, key string) bool {
+	return key != "" && e.Problem.Code == IdempotencyInProgress
+}
+
+// retryAfter разбирает Retry-After в секундах или в формате HTTP-даты.
+func retryAfter(resp *http.Response) time.Duration {
+	value := resp.Header.Get("Retry-After")
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		return time.Duration(seconds) * time.Second
+	}
+	if at, err := http.ParseTime(value); err == nil {
+		return time.Until(at)
+	}
+	return 0
+}
@@ -0,0 +1,49 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+)
+
+func domainPath(host string) string {
+	return "/domains/" + url.PathEscape(host)
+}
+
+func (c *Client) ListDomains(ctx context.Context) ([]Domain, error) {
+	var domains []Domain
+	if err := c.call(ctx, http.MethodGet, "/domains", nil, nil, &domains, http.StatusOK); err != nil {
+		return nil, err
+	}
+	return domains, nil
+}
+
+func (c *Client) CreateDomain(ctx context.Context, domain Domain) (*Domain, error) {
+	var created Domain
+	if err := c.create(ctx, "/domains", domain, &created, http.StatusCreated); err != nil {
+		return nil, err
+	}
+	return &created, nil
+}
+
+func (c *Client) GetDomain(ctx context.Context, host string) (*Domain, error) {
+	var domain Domain
+	if err := c.call(ctx, http.MethodGet, domainPath(host), nil, nil, &domain, http.StatusOK); err != nil {
+		return nil, err
+	}
+	return &domain, nil
+}
+
+// UpdateDomain заменяет настройки домена; Host в теле не меняется.
+func (c *Client) UpdateDomain(ctx context.Context, host string, domain Domain) (*Domain, error) {
+	var updated Domain
+	if err := c.call(ctx, http.MethodPut, domainPath(host), nil, domain, &updated, http.StatusOK); err != nil {
+		return nil, err
+	}
+	return &updated, nil
+}
+
+// DeleteDomain удаляет домен. Домен со ссылками не удаляется (409).
+func (c *Client) DeleteDomain(ctx context.Context, host string) error {
+	return c.call(ctx, http.MethodDelete, domainPath(host), nil, nil, nil, http.StatusNoContent)
+}
@@ -0,0 +1,69 @@
+package client
+
+import "fmt"
+
+// Problem - тело ответа с ошибкой по RFC 7807 с кодом ошибки API.
+type Problem struct {
+	Type      string    `json:"type"`
+	Title     string    `json:"title"`
+	Status    int       `json:"status"`
+	Code      ErrorCode `json:"code"`
+	Message   string    `json:"message"`
+	Field     string    `json:"field,omitempty"`
+	RequestID string    `json:"request_id,omitempty"`
+	Instance  string    `json:"instance,omitempty"`
+}
+
+func (p *Problem) Error() string {
+	if p.Field != "" {
+		return fmt.Sprintf("%s: %s (%s)", p.Code, p.Message, p.Field)
+	}
+	return fmt.Sprintf("%s: %s", p.Code, p.Message)
+}
+
+// ErrorCode - стабильный код ошибки API, например "link_not_found". Коды не
+// меняются между версиями, в отличие от текста сообщений.
+type ErrorCode string
+
+const (
+	Internal             ErrorCode = "internal_error"
+	NotFound             ErrorCode = "not_found"
+	MethodNotAllowed     ErrorCode = "method_not_allowed"
+	InvalidJSON          ErrorCode = "invalid_json"
+	URLRequired          ErrorCode = "url_required"
+	InvalidURL           ErrorCode = "invalid_url"
+	InvalidAlias         ErrorCode = "invalid_alias"
+	InvalidRedirectType  ErrorCode = "invalid_redirect_type"
+	InvalidRules         ErrorCode = "invalid_rules"
+	InvalidVariants      ErrorCode = "invalid_variants"
+	InvalidGeo           ErrorCode = "invalid_geo"
+	InvalidUTMApply      ErrorCode = "invalid_utm_apply"
+	InvalidUTMTemplate   ErrorCode = "invalid_utm_template"
+	InvalidPassthrough   ErrorCode = "invalid_passthrough"
+	InvalidTTL           ErrorCode = "invalid_ttl"
+	InvalidPageToken     ErrorCode = "invalid_page_token"
+	InvalidPageSize      ErrorCode = "invalid_page_size"
+	InvalidWebhook       ErrorCode = "invalid_webhook"
+	InvalidQROptions     ErrorCode = "invalid_qr_options"
+	InvalidHost          ErrorCode = "invalid_host"
+	InvalidCodeLength    ErrorCode = "invalid_code_length"
+	InvalidNotFoundURL   ErrorCode = "invalid_not_found_url"
+	StickyWithoutVariant ErrorCode = "sticky_without_variants"
+	LinkNotFound         ErrorCode = "link_not_found"
+	DomainNotFound       ErrorCode = "domain_not_found"
+	UTMTemplateNotFound  ErrorCode = "utm_template_not_found"
+	WebhookNotFound      ErrorCode = "webhook_not_found"
+	DeliveryNotFound     ErrorCode = "delivery_not_found"
+	AliasTaken           ErrorCode = "alias_taken"
+	DomainExists         ErrorCode = "domain_exists"
+	DomainInUse          ErrorCode = "domain_in_use"
+	UTMTemplateExists    ErrorCode = "utm_template_exists"
+	UTMTemplateInUse     ErrorCode = "utm_template_in_use"
+	CodeGenerationFailed ErrorCode = "code_generation_failed"
+	BaseURLRequired      ErrorCode = "base_url_required"
+
+	// Ошибки заголовка Idempotency-Key.
+	InvalidIdempotencyKey ErrorCode = "invalid_idempotency_key"
+	IdempotencyKeyReused  ErrorCode = "idempotency_key_reused"
+	IdempotencyInProgress ErrorCode = "idempotency_key_in_progress"
+)
@@ -0,0 +1,46 @@
+package client
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+)
+
+// IdempotencyKeyHeader - заголовок, по которому сервер узнает повтор запроса
+// создания и возвращает исходный ответ вместо создания дубликата.
+const IdempotencyKeyHeader = "Idempotency-Key"
+
+type idempotencyKeyContext struct{}
+
+type sendKeyContext struct{}
+
+// WithIdempotencyKey задает ключ идемпотентности для методов Create*. Без него
+// клиент генерирует случайный ключ на каждый вызов, и повторы внутри вызова
+// безопасны. Свой ключ нужен, чтобы безопасно повторять вызов целиком, например
+// после перезапуска задачи: повтор с тем же ключом и телом вернет ту же ссылку.
+func WithIdempotencyKey(ctx context.Context, key string) context.Context {
+	return context.WithValue(ctx, idempotencyKeyContext{}, key)
+}
+
+// create выполняет POST создания объекта с заголовком Idempotency-Key. Такой
+// запрос повторяется так же, как идемпотентные.
+func (c *Client) create(ctx context.Context, path string, in, out any, want int) error {
+	key, _ := ctx.Value(idempotencyKeyContext{}).(string)
+	if key == "" {
+		key = newIdempotencyKey()
+	}
+	return c.call(context.WithValue(ctx, sendKeyContext{}, key), http.MethodPost, path, nil, in, out, want)
+}
+
+// sendKey возвращает ключ идемпотентности текущего запроса или пустую строку.
+func sendKey(ctx context.Context) string {
+	key, _ := ctx.Value(sendKeyContext{}).(string)
+	return key
+}
+
+func newIdempotencyKey() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,151 @@
+package client
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+)
+
+// ListOptions выбирает страницу ссылок. Пустой Domain - домен по умолчанию,
+// PageSize 0 - размер страницы сервера (50).
+type ListOptions struct {
+	Domain    string
+	Search    string
+	PageSize  int
+	PageToken string
+}
+
+// QROptions - параметры QR-кода. Пустые поля заменяются значениями сервера по
+// умолчанию: PNG 256x256, уровень M, черное на белом, отступ 4 модуля.
+type QROptions struct {
+	Format     string
+	Size       int
+	Level      string
+	Foreground string
+	Background string
+	// Margin - отступ в модулях; nil - отступ по умолчанию.
+	Margin *int
+}
+
+// domainQuery возвращает параметр domain для операций со ссылкой.
+func domainQuery(domain string) url.Values {
+	query := url.Values{}
+	if domain != "" {
+		query.Set("domain", domain)
+	}
+	return query
+}
+
+func linkPath(code string) string {
+	return "/links/" + url.PathEscape(code)
+}
+
+func (c *Client) CreateLink(ctx context.Context, req ShortenRequest) (*ShortenResponse, error) {
+	var resp ShortenResponse
+	if err := c.create(ctx, "/links", req, &resp, http.StatusOK); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// GetLink возвращает ссылку со статистикой переходов.
+func (c *Client) GetLink(ctx context.Context, domain, code string) (*StatsResponse, error) {
+	var stats StatsResponse
+	if err := c.call(ctx, http.MethodGet, linkPath(code), domainQuery(domain), nil, &stats, http.StatusOK); err != nil {
+		return nil, err
+	}
+	return &stats, nil
+}
+
+func (c *Client) ListLinks(ctx context.Context, opts ListOptions) (*LinkList, error) {
+	query := domainQuery(opts.Domain)
+	if opts.Search != "" {
+		query.Set("search", opts.Search)
+	}
+	if opts.PageSize > 0 {
+		query.Set("page_size", strconv.Itoa(opts.PageSize))
+	}
+	if opts.PageToken != "" {
+		query.Set("page_token", opts.PageToken)
+	}
+
+	var list LinkList
+	if err := c.call(ctx, http.MethodGet, "/links", query, nil, &list, http.StatusOK); err != nil {
+		return nil, err
+	}
+	return &list, nil
+}
+
+// AllLinks обходит все страницы списка, начиная с opts.PageToken.
+func (c *Client) AllLinks(ctx context.Context, opts ListOptions) ([]LinkSummary, error) {
+	links := []LinkSummary{}
+	for {
+		page, err := c.ListLinks(ctx, opts)
+		if err != nil {
+			return nil, err
+		}
+		links = append(links, page.Links...)
+		if page.NextPageToken == "" {
+			return links, nil
+		}
+		opts.PageToken = page.NextPageToken
+	}
+}
+
+func (c *Client) UpdateLink(ctx context.Context, domain, code string, update LinkUpdate) (*StatsResponse, error) {
+	var stats StatsResponse
+	if err := c.call(ctx, http.MethodPatch, linkPath(code), domainQuery(domain), update, &stats, http.StatusOK); err != nil {
+		return nil, err
+	}
+	return &stats, nil
+}
+
+// DeleteLink удаляет ссылку вместе со статистикой.
+func (c *Client) DeleteLink(ctx context.Context, domain, code string) error {
+	return c.call(ctx, http.MethodDelete, linkPath(code), domainQuery(domain), nil, nil, http.StatusNoContent)
+}
+
+// ResolveLink возвращает цель ссылки, не засчитывая переход.
+func (c *Client) ResolveLink(ctx context.Context, domain, code string) (*ResolveResponse, error) {
+	var resolved ResolveResponse
+	if err := c.call(ctx, http.MethodGet, linkPath(code)+"/resolve", domainQuery(domain), nil, &resolved, http.StatusOK); err != nil {
+		return nil, err
+	}
+	return &resolved, nil
+}
+
+// QRCode возвращает изображение QR-кода короткой ссылки в PNG или SVG.
+func (c *Client) QRCode(ctx context.Context, domain, code string, opts QROptions) ([]byte, error) {
+	query := domainQuery(domain)
+	for name, value := range map[string]string{
+		"format": opts.Format, "level": opts.Level, "fg": opts.Foreground, "bg": opts.Background,
+	} {
+		if value != "" {
+			query.Set(name, value)
+		}
+	}
+	if opts.Size > 0 {
+		query.Set("size", strconv.Itoa(opts.Size))
+	}
+	if opts.Margin != nil {
+		query.Set("margin", strconv.Itoa(*opts.Margin))
+	}
+
+	resp, err := c.send(ctx, http.MethodGet, APIPrefix+linkPath(code)+"/qr", query, nil, http.StatusOK)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	return io.ReadAll(resp.Body)
+}
+
+// Campaigns возвращает переходы по ссылкам, сгруппированные по utm_campaign.
+func (c *Client) Campaigns(ctx context.Context) ([]CampaignStats, error) {
+	var stats []CampaignStats
+	if err := c.call(ctx, http.MethodGet, "/campaigns", nil, nil, &stats, http.StatusOK); err != nil {
+		return nil, err
+	}
+	return stats, nil
+}
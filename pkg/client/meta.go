@@ -0,0 +1,28 @@
+package client
+
+import (
+	"context"
+	"io"
+	"net/http"
+)
+
+// OpenAPI возвращает спецификацию OpenAPI сервера в JSON.
+func (c *Client) OpenAPI(ctx context.Context) ([]byte, error) {
+	return c.raw(ctx, APIPrefix+"/openapi.json")
+}
+
+// Probe запрашивает проверку состояния healthz или readyz и возвращает тело
+// ответа. Неготовый сервер отвечает 503, которые клиент повторяет по MaxRetries;
+// для HEALTHCHECK повторы обычно стоит отключить.
+func (c *Client) Probe(ctx context.Context, endpoint string) ([]byte, error) {
+	return c.raw(ctx, "/"+endpoint)
+}
+
+func (c *Client) raw(ctx context.Context, path string) ([]byte, error) {
+	resp, err := c.send(ctx, http.MethodGet, path, nil, nil, http.StatusOK)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	return io.ReadAll(resp.Body)
+}
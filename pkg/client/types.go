@@ -0,0 +1,162 @@
+package client
+
+import "time"
+
+// Типы запросов и ответов повторяют JSON-контракт API /api/v1. Клиент не зависит
+// от внутренних пакетов сервера, поэтому его можно подключать отдельно.
+
+type ShortenRequest struct {
+	URL          string            `json:"url"`
+	Alias        string            `json:"alias,omitempty"`
+	TTLDays      int               `json:"ttl_days,omitempty"`
+	RedirectType int               `json:"redirect_type,omitempty"`
+	Passthrough  bool              `json:"passthrough,omitempty"`
+	UTMTemplate  string            `json:"utm_template,omitempty"`
+	UTMApply     string            `json:"utm_apply,omitempty"`
+	Rules        []TargetRule      `json:"rules,omitempty"`
+	Variants     []Variant         `json:"variants,omitempty"`
+	Sticky       bool              `json:"sticky,omitempty"`
+	Geo          map[string]string `json:"geo,omitempty"`
+	Interstitial bool              `json:"interstitial,omitempty"`
+	Domain       string            `json:"domain,omitempty"`
+}
+
+type ShortenResponse struct {
+	Code     string `json:"code"`
+	ShortURL string `json:"short_url"`
+}
+
+// TargetRule перенаправляет на URL посетителей, чей User-Agent совпал со всеми
+// заданными условиями. Пустое условие совпадает с любым значением.
+type TargetRule struct {
+	Name   string `json:"name"`
+	OS     string `json:"os,omitempty"`
+	Device string `json:"device,omitempty"`
+	Bot    *bool  `json:"bot,omitempty"`
+	URL    string `json:"url"`
+}
+
+type Variant struct {
+	Name   string `json:"name"`
+	URL    string `json:"url"`
+	Weight int    `json:"weight"`
+}
+
+type StatsResponse struct {
+	URL          string            `json:"url"`
+	CreatedAt    time.Time         `json:"created_at"`
+	ExpiresAt    *time.Time        `json:"expires_at,omitempty"`
+	HitCount     int64             `json:"hit_count"`
+	RedirectType int               `json:"redirect_type"`
+	Passthrough  bool              `json:"passthrough"`
+	UTMTemplate  string            `json:"utm_template,omitempty"`
+	Breakdown    Breakdown         `json:"breakdown,omitempty"`
+	Rules        []TargetRule      `json:"rules,omitempty"`
+	Variants     []Variant         `json:"variants,omitempty"`
+	Sticky       bool              `json:"sticky,omitempty"`
+	Geo          map[string]string `json:"geo,omitempty"`
+	Interstitial bool              `json:"interstitial"`
+	Domain       string            `json:"domain,omitempty"`
+}
+
+// Breakdown группирует переходы по измерению (например, campaign) и его значению.
+type Breakdown map[string]map[string]int64
+
+type CampaignStats struct {
+	Campaign string `json:"campaign"`
+	HitCount int64  `json:"hit_count"`
+	Links    int64  `json:"links"`
+}
+
+// LinkUpdate - изменение ссылки. Поля со значением nil не меняются.
+type LinkUpdate struct {
+	URL *string `json:"url,omitempty"`
+	// TTLDays отсчитывается от момента изменения; 0 снимает срок действия.
+	TTLDays *int `json:"ttl_days,omitempty"`
+	// RedirectType 0 возвращает тип перенаправления по умолчанию для домена.
+	RedirectType *int  `json:"redirect_type,omitempty"`
+	Passthrough  *bool `json:"passthrough,omitempty"`
+	Interstitial *bool `json:"interstitial,omitempty"`
+}
+
+// LinkSummary - ссылка в списке, без правил, вариантов и гео.
+type LinkSummary struct {
+	Code      string     `json:"code"`
+	Domain    string     `json:"domain,omitempty"`
+	ShortURL  string     `json:"short_url"`
+	URL       string     `json:"url"`
+	CreatedAt time.Time  `json:"created_at"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+	HitCount  int64      `json:"hit_count"`
+}
+
+// LinkList - страница списка ссылок. Пустой NextPageToken означает последнюю страницу.
+type LinkList struct {
+	Links         []LinkSummary `json:"links"`
+	NextPageToken string        `json:"next_page_token,omitempty"`
+}
+
+type ResolveResponse struct {
+	URL          string `json:"url"`
+	ShortURL     string `json:"short_url"`
+	RedirectType int    `json:"redirect_type"`
+}
+
+// Domain - собственный домен коротких ссылок со своими настройками по умолчанию.
+type Domain struct {
+	Host         string    `json:"host"`
+	RedirectType int       `json:"redirect_type,omitempty"`
+	NotFoundURL  string    `json:"not_found_url,omitempty"`
+	CodeLength   int       `json:"code_length,omitempty"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+type UTMTemplate struct {
+	Name      string    `json:"name"`
+	Source    string    `json:"utm_source"`
+	Medium    string    `json:"utm_medium"`
+	Campaign  string    `json:"utm_campaign"`
+	Term      string    `json:"utm_term,omitempty"`
+	Content   string    `json:"utm_content,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Webhook - подписка на события ссылок. Secret возвращается только при создании.
+type Webhook struct {
+	ID        int64     `json:"id"`
+	URL       string    `json:"url"`
+	Events    []string  `json:"events"`
+	Secret    string    `json:"secret,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type WebhookDelivery struct {
+	ID             int64            `json:"id"`
+	WebhookID      int64            `json:"webhook_id"`
+	EventID        string           `json:"event_id"`
+	Event          string           `json:"event"`
+	Status         string           `json:"status"`
+	Attempts       int              `json:"attempts"`
+	NextAttemptAt  *time.Time       `json:"next_attempt_at,omitempty"`
+	LastStatusCode int              `json:"last_status_code,omitempty"`
+	LastError      string           `json:"last_error,omitempty"`
+	CreatedAt      time.Time        `json:"created_at"`
+	UpdatedAt      time.Time        `json:"updated_at"`
+	Log            []WebhookAttempt `json:"log"`
+}
+
+// WebhookAttempt - запись журнала об одной попытке доставки.
+type WebhookAttempt struct {
+	Attempt    int       `json:"attempt"`
+	StatusCode int       `json:"status_code,omitempty"`
+	Error      string    `json:"error,omitempty"`
+	DurationMS int64     `json:"duration_ms"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// Статусы доставки вебхука для ListDeliveries.
+const (
+	DeliveryPending   = "pending"
+	DeliveryDelivered = "delivered"
+	DeliveryDead      = "dead"
+)
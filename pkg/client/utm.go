@@ -0,0 +1,48 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+)
+
+func utmTemplatePath(name string) string {
+	return "/utm-templates/" + url.PathEscape(name)
+}
+
+func (c *Client) ListUTMTemplates(ctx context.Context) ([]UTMTemplate, error) {
+	var templates []UTMTemplate
+	if err := c.call(ctx, http.MethodGet, "/utm-templates", nil, nil, &templates, http.StatusOK); err != nil {
+		return nil, err
+	}
+	return templates, nil
+}
+
+func (c *Client) CreateUTMTemplate(ctx context.Context, t UTMTemplate) (*UTMTemplate, error) {
+	var created UTMTemplate
+	if err := c.create(ctx, "/utm-templates", t, &created, http.StatusCreated); err != nil {
+		return nil, err
+	}
+	return &created, nil
+}
+
+func (c *Client) GetUTMTemplate(ctx context.Context, name string) (*UTMTemplate, error) {
+	var t UTMTemplate
+	if err := c.call(ctx, http.MethodGet, utmTemplatePath(name), nil, nil, &t, http.StatusOK); err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+func (c *Client) UpdateUTMTemplate(ctx context.Context, name string, t UTMTemplate) (*UTMTemplate, error) {
+	var updated UTMTemplate
+	if err := c.call(ctx, http.MethodPut, utmTemplatePath(name), nil, t, &updated, http.StatusOK); err != nil {
+		return nil, err
+	}
+	return &updated, nil
+}
+
+// DeleteUTMTemplate удаляет шаблон. Шаблон, на который ссылаются ссылки, не удаляется (409).
+func (c *Client) DeleteUTMTemplate(ctx context.Context, name string) error {
+	return c.call(ctx, http.MethodDelete, utmTemplatePath(name), nil, nil, nil, http.StatusNoContent)
+}
@@ -0,0 +1,68 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strconv"
+)
+
+func webhookPath(id int64) string {
+	return "/webhooks/" + strconv.FormatInt(id, 10)
+}
+
+// ListWebhooks возвращает подписки без секретов.
+func (c *Client) ListWebhooks(ctx context.Context) ([]Webhook, error) {
+	var webhooks []Webhook
+	if err := c.call(ctx, http.MethodGet, "/webhooks", nil, nil, &webhooks, http.StatusOK); err != nil {
+		return nil, err
+	}
+	return webhooks, nil
+}
+
+// CreateWebhook подписывается на события. Секрет для проверки подписи
+// возвращается только здесь; если он не задан, сервер создает его сам.
+func (c *Client) CreateWebhook(ctx context.Context, webhook Webhook) (*Webhook, error) {
+	var created Webhook
+	if err := c.create(ctx, "/webhooks", webhook, &created, http.StatusCreated); err != nil {
+		return nil, err
+	}
+	return &created, nil
+}
+
+func (c *Client) GetWebhook(ctx context.Context, id int64) (*Webhook, error) {
+	var webhook Webhook
+	if err := c.call(ctx, http.MethodGet, webhookPath(id), nil, nil, &webhook, http.StatusOK); err != nil {
+		return nil, err
+	}
+	return &webhook, nil
+}
+
+// DeleteWebhook удаляет подписку вместе с ее доставками.
+func (c *Client) DeleteWebhook(ctx context.Context, id int64) error {
+	return c.call(ctx, http.MethodDelete, webhookPath(id), nil, nil, nil, http.StatusNoContent)
+}
+
+// ListDeliveries возвращает доставки вебхука с журналом попыток. Пустой status -
+// доставки в любом статусе.
+func (c *Client) ListDeliveries(ctx context.Context, webhookID int64, status string) ([]WebhookDelivery, error) {
+	query := url.Values{}
+	if status != "" {
+		query.Set("status", status)
+	}
+	var deliveries []WebhookDelivery
+	if err := c.call(ctx, http.MethodGet, webhookPath(webhookID)+"/deliveries", query, nil, &deliveries, http.StatusOK); err != nil {
+		return nil, err
+	}
+	return deliveries, nil
+}
+
+// RetryDelivery ставит доставку в очередь заново, в том числе после dead.
+func (c *Client) RetryDelivery(ctx context.Context, webhookID, deliveryID int64) (*WebhookDelivery, error) {
+	path := webhookPath(webhookID) + "/deliveries/" + strconv.FormatInt(deliveryID, 10) + "/retry"
+	var delivery WebhookDelivery
+	if err := c.call(ctx, http.MethodPost, path, nil, nil, &delivery, http.StatusOK); err != nil {
+		return nil, err
+	}
+	return &delivery, nil
+}
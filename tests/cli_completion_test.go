@@ -0,0 +1,219 @@
+package tests
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"tinyurl/internal/handlers"
+	"tinyurl/pkg/client"
+)
+
+// complete запрашивает у CLI подсказки для кода ссылки так же, как это делает
+// скрипт автодополнения оболочки, и возвращает подсказанные коды.
+func (h *cliHome) complete(t *testing.T, server, prefix string) []string {
+	t.Helper()
+
+	res := h.run(t, "-s", server, "__complete", "stats", prefix)
+	if res.code != 0 {
+		t.Fatalf("complete exit code = %d, stderr = %q", res.code, res.stderr)
+	}
+	var codes []string
+	for _, line := range strings.Split(res.stdout, "\n") {
+		if line == "" || strings.HasPrefix(line, ":") {
+			continue
+		}
+		code, _, _ := strings.Cut(line, "\t")
+		codes = append(codes, code)
+	}
+	return codes
+}
+
+// codesCachePath повторяет расположение кэша подсказок в CLI.
+func (h *cliHome) codesCachePath(server string) string {
+	sum := sha256.Sum256([]byte(server + "\n"))
+	return filepath.Join(h.dir, "cache", "tinyurl", "codes-"+hex.EncodeToString(sum[:8])+".json")
+}
+
+func (h *cliHome) writeCodesCache(t *testing.T, server string, fetchedAt time.Time, codes ...string) {
+	t.Helper()
+
+	type link struct {
+		Code string `json:"code"`
+		URL  string `json:"url"`
+	}
+	cache := struct {
+		FetchedAt time.Time `json:"fetched_at"`
+		Complete  bool      `json:"complete"`
+		Links     []link    `json:"links"`
+	}{FetchedAt: fetchedAt, Complete: true}
+	for _, code := range codes {
+		cache.Links = append(cache.Links, link{Code: code, URL: "https://example.com/" + code})
+	}
+	data, _ := json.Marshal(cache)
+	path := h.codesCachePath(server)
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func containsCode(codes []string, code string) bool {
+	for _, c := range codes {
+		if c == code {
+			return true
+		}
+	}
+	return false
+}
+
+func TestCLICompletionCache(t *testing.T) {
+	var lists atomic.Int32
+	api := newTestClient(t, func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Method == http.MethodGet && r.URL.Path == handlers.APIPrefix+"/links" {
+				lists.Add(1)
+			}
+			next.ServeHTTP(w, r)
+		})
+	})
+	for _, code := range []string{"cmpl-one", "cmpl-two"} {
+		if _, err := api.CreateLink(context.Background(), client.ShortenRequest{URL: "https://example.com/" + code, Alias: code}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	server := api.BaseURL
+
+	tests := []struct {
+		name      string
+		prepare   func(t *testing.T, home *cliHome)
+		prefix    string
+		want      []string
+		wantFetch bool
+	}{
+		{
+			name: "Empty cache is filled from the API", prefix: "cmpl-",
+			want: []string{"cmpl-one", "cmpl-two"}, wantFetch: true,
+		},
+		{
+			name: "Fresh cache is used without the API", prefix: "cmpl-",
+			prepare: func(t *testing.T, home *cliHome) {
+				home.writeCodesCache(t, server, time.Now(), "cmpl-cached")
+			},
+			want: []string{"cmpl-cached"},
+		},
+		{
+			name: "Expired cache falls back to the API", prefix: "cmpl-",
+			prepare: func(t *testing.T, home *cliHome) {
+				home.writeCodesCache(t, server, time.Now().Add(-time.Minute), "cmpl-cached")
+			},
+			want: []string{"cmpl-one", "cmpl-two"}, wantFetch: true,
+		},
+		{
+			name: "Corrupt cache falls back to the API", prefix: "cmpl-",
+			prepare: func(t *testing.T, home *cliHome) {
+				path := home.codesCachePath(server)
+				os.MkdirAll(filepath.Dir(path), 0o700)
+				if err := os.WriteFile(path, []byte("{not json"), 0o600); err != nil {
+					t.Fatal(err)
+				}
+			},
+			want: []string{"cmpl-one", "cmpl-two"}, wantFetch: true,
+		},
+		{
+			name: "Unreadable cache falls back to the API", prefix: "cmpl-",
+			prepare: func(t *testing.T, home *cliHome) {
+				// Каталог на месте файла не читается и не перезаписывается.
+				if err := os.MkdirAll(home.codesCachePath(server), 0o700); err != nil {
+					t.Fatal(err)
+				}
+			},
+			want: []string{"cmpl-one", "cmpl-two"}, wantFetch: true,
+		},
+		{
+			name: "Prefix filters cached codes", prefix: "cmpl-t",
+			prepare: func(t *testing.T, home *cliHome) {
+				home.writeCodesCache(t, server, time.Now(), "cmpl-one", "cmpl-two")
+			},
+			want: []string{"cmpl-two"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			home := newCLIHome(t)
+			if tt.prepare != nil {
+				tt.prepare(t, home)
+			}
+
+			before := lists.Load()
+			codes := home.complete(t, server, tt.prefix)
+			if fetched := lists.Load() > before; fetched != tt.wantFetch {
+				t.Errorf("fetched from API = %v, want %v", fetched, tt.wantFetch)
+			}
+			for _, code := range tt.want {
+				if !containsCode(codes, code) {
+					t.Errorf("suggestions %v miss %q", codes, code)
+				}
+			}
+			if !tt.wantFetch && len(codes) != len(tt.want) {
+				t.Errorf("suggestions = %v, want %v", codes, tt.want)
+			}
+
+			// Свежий ответ API сохраняется, и следующая подсказка его использует.
+			if tt.wantFetch {
+				if info, err := os.Stat(home.codesCachePath(server)); err != nil || !info.Mode().IsRegular() {
+					return
+				}
+				before = lists.Load()
+				home.complete(t, server, tt.prefix)
+				if lists.Load() != before {
+					t.Error("cache written after fetch was not used")
+				}
+			}
+		})
+	}
+}
+
+func TestCLICompletionInvalidation(t *testing.T) {
+	api := newTestClient(t, nil)
+	if _, err := api.CreateLink(context.Background(), client.ShortenRequest{URL: "https://example.com/cmpl-del", Alias: "cmpl-del"}); err != nil {
+		t.Fatal(err)
+	}
+	server := api.BaseURL
+	home := newCLIHome(t)
+
+	if codes := home.complete(t, server, "cmpl-"); !containsCode(codes, "cmpl-del") {
+		t.Fatalf("suggestions = %v, want cmpl-del", codes)
+	}
+
+	if res := home.run(t, "-s", server, "short", "https://example.com/cmpl-new", "-a", "cmpl-new"); res.code != 0 {
+		t.Fatalf("short: exit code = %d, stderr = %q", res.code, res.stderr)
+	}
+	if _, err := os.Stat(home.codesCachePath(server)); !os.IsNotExist(err) {
+		t.Errorf("cache after short: %v, want removed", err)
+	}
+	if codes := home.complete(t, server, "cmpl-"); !containsCode(codes, "cmpl-new") {
+		t.Errorf("suggestions after short = %v, want cmpl-new", codes)
+	}
+
+	if res := home.run(t, "-s", server, "delete", "cmpl-del"); res.code != 0 {
+		t.Fatalf("delete: exit code = %d, stderr = %q", res.code, res.stderr)
+	}
+	if _, err := os.Stat(home.codesCachePath(server)); !os.IsNotExist(err) {
+		t.Errorf("cache after delete: %v, want removed", err)
+	}
+	if codes := home.complete(t, server, "cmpl-"); containsCode(codes, "cmpl-del") {
+		t.Errorf("suggestions after delete = %v, still contain cmpl-del", codes)
+	}
+}
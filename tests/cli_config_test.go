@@ -0,0 +1,256 @@
+package tests
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"tinyurl/internal/models"
+)
+
+// recordingServer запоминает последний запрос на создание ссылки и отвечает
+// успехом, чтобы по нему было видно, какие адрес, токен и срок выбрал CLI.
+type recordingServer struct {
+	url string
+
+	mu    sync.Mutex
+	hits  int
+	token string
+	req   models.ShortenRequest
+}
+
+func newRecordingServer(t *testing.T) *recordingServer {
+	t.Helper()
+
+	rs := &recordingServer{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		rs.mu.Lock()
+		defer rs.mu.Unlock()
+		rs.hits++
+		rs.token = strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+		rs.req = models.ShortenRequest{}
+		json.NewDecoder(r.Body).Decode(&rs.req)
+		writeJSONResponse(w, models.ShortenResponse{Code: "rec", ShortURL: "http://rec.example/r/rec"})
+	}))
+	t.Cleanup(srv.Close)
+	rs.url = srv.URL
+	return rs
+}
+
+func (rs *recordingServer) last() (hits int, token string, req models.ShortenRequest) {
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+	return rs.hits, rs.token, rs.req
+}
+
+func writeJSONResponse(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(v)
+}
+
+func (h *cliHome) writeConfig(t *testing.T, content string) {
+	t.Helper()
+
+	path := filepath.Join(h.dir, "config", "tinyurl", "config.yaml")
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// outputKind определяет формат вывода short: json, yaml или table.
+func outputKind(stdout string) string {
+	switch {
+	case json.Valid([]byte(stdout)):
+		return "json"
+	case strings.HasPrefix(stdout, "code: "):
+		return "yaml"
+	default:
+		return "table"
+	}
+}
+
+// Флаги перекрывают переменные окружения, те - профиль, а профиль - значения
+// по умолчанию.
+func TestCLIConfigPrecedence(t *testing.T) {
+	servers := map[string]*recordingServer{}
+	for _, name := range []string{"default", "work", "other", "env", "flag"} {
+		servers[name] = newRecordingServer(t)
+	}
+	config := `current_profile: work
+profiles:
+  default:
+    server: ` + servers["default"].url + `
+    token: default-token
+  work:
+    server: ` + servers["work"].url + `
+    token: work-token
+    ttl: 3
+    output: json
+  other:
+    server: ` + servers["other"].url + `
+    token: other-token
+`
+	overrides := []string{"TINYURL_SERVER=" + servers["env"].url, "TINYURL_TOKEN=env-token", "TINYURL_TTL=5", "TINYURL_OUTPUT=yaml"}
+
+	tests := []struct {
+		name       string
+		noConfig   bool
+		env        []string
+		args       []string
+		wantServer string
+		wantToken  string
+		wantTTL    int
+		wantOutput string
+	}{
+		{
+			name: "Current profile", wantServer: "work", wantToken: "work-token", wantTTL: 3, wantOutput: "json",
+		},
+		{
+			name: "TINYURL_PROFILE over current profile", env: []string{"TINYURL_PROFILE=other"},
+			wantServer: "other", wantToken: "other-token", wantOutput: "table",
+		},
+		{
+			name: "--profile over TINYURL_PROFILE", env: []string{"TINYURL_PROFILE=other"}, args: []string{"-p", "work"},
+			wantServer: "work", wantToken: "work-token", wantTTL: 3, wantOutput: "json",
+		},
+		{
+			name: "Environment over profile", env: overrides,
+			wantServer: "env", wantToken: "env-token", wantTTL: 5, wantOutput: "yaml",
+		},
+		{
+			name: "Flags over environment", env: overrides,
+			args:       []string{"--token", "flag-token", "-o", "table", "short", "https://example.com", "-t", "7"},
+			wantServer: "flag", wantToken: "flag-token", wantTTL: 7, wantOutput: "table",
+		},
+		{
+			name: "Defaults without config", noConfig: true,
+			wantServer: "flag", wantToken: "", wantTTL: 0, wantOutput: "table",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			home := newCLIHome(t, tt.env...)
+			if !tt.noConfig {
+				home.writeConfig(t, config)
+			}
+			args := tt.args
+			if tt.wantServer == "flag" {
+				args = append([]string{"-s", servers["flag"].url}, args...)
+			}
+			if !containsArg(args, "short") {
+				args = append(args, "short", "https://example.com")
+			}
+
+			before := map[string]int{}
+			for name, rs := range servers {
+				before[name], _, _ = rs.last()
+			}
+			res := home.run(t, args...)
+			if res.code != 0 {
+				t.Fatalf("exit code = %d, stderr = %q", res.code, res.stderr)
+			}
+
+			for name, rs := range servers {
+				hits, _, _ := rs.last()
+				if hit := hits > before[name]; hit != (name == tt.wantServer) {
+					t.Errorf("server %s hit = %v, want %v", name, hit, name == tt.wantServer)
+				}
+			}
+			_, token, req := servers[tt.wantServer].last()
+			if token != tt.wantToken {
+				t.Errorf("token = %q, want %q", token, tt.wantToken)
+			}
+			if req.TTLDays != tt.wantTTL {
+				t.Errorf("ttl_days = %d, want %d", req.TTLDays, tt.wantTTL)
+			}
+			if kind := outputKind(res.stdout); kind != tt.wantOutput {
+				t.Errorf("output = %s, want %s: %q", kind, tt.wantOutput, res.stdout)
+			}
+		})
+	}
+}
+
+func containsArg(args []string, arg string) bool {
+	for _, a := range args {
+		if a == arg {
+			return true
+		}
+	}
+	return false
+}
+
+func TestCLIMissingProfile(t *testing.T) {
+	tests := []struct {
+		name string
+		env  []string
+		args []string
+		want int
+	}{
+		{name: "--profile", args: []string{"-p", "missing", "list"}, want: 2},
+		{name: "TINYURL_PROFILE", env: []string{"TINYURL_PROFILE=missing"}, args: []string{"list"}, want: 2},
+		{name: "Config command creates it", args: []string{"-p", "missing", "config", "get"}, want: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			home := newCLIHome(t, tt.env...)
+			home.writeConfig(t, "profiles:\n  default:\n    server: http://127.0.0.1:1\n")
+			if res := home.run(t, tt.args...); res.code != tt.want {
+				t.Errorf("exit code = %d, want %d, stderr = %q", res.code, tt.want, res.stderr)
+			}
+		})
+	}
+}
+
+func TestCLIConfigCommands(t *testing.T) {
+	home := newCLIHome(t)
+
+	steps := []struct {
+		args       []string
+		wantCode   int
+		wantStdout string
+	}{
+		{args: []string{"config", "set", "server", "https://sho.rt"}},
+		{args: []string{"config", "set", "token", "s3cr3t"}},
+		{args: []string{"config", "get", "server"}, wantStdout: "https://sho.rt\n"},
+		{args: []string{"config", "get", "token"}, wantStdout: "s3cr3t\n"},
+		{args: []string{"-o", "json", "config", "get"}, wantStdout: "{\n  \"server\": \"https://sho.rt\",\n  \"token\": \"********\"\n}\n"},
+		{args: []string{"-p", "staging", "config", "set", "ttl", "7"}},
+		{args: []string{"config", "use-profile", "staging"}},
+		{args: []string{"config", "get", "ttl"}, wantStdout: "7\n"},
+		{args: []string{"config", "set", "ttl", ""}},
+		{args: []string{"config", "get", "ttl"}, wantStdout: ""},
+		{args: []string{"config", "use-profile", "missing"}, wantCode: 3},
+		{args: []string{"config", "set", "server", "ftp://sho.rt"}, wantCode: 2},
+		{args: []string{"config", "set", "ttl", "-1"}, wantCode: 2},
+		{args: []string{"config", "set", "output", "xml"}, wantCode: 2},
+		{args: []string{"config", "set", "color", "red"}, wantCode: 2},
+	}
+
+	for _, step := range steps {
+		res := home.run(t, step.args...)
+		if res.code != step.wantCode {
+			t.Fatalf("%v: exit code = %d, want %d, stderr = %q", step.args, res.code, step.wantCode, res.stderr)
+		}
+		if step.args[1] == "get" && res.stdout != step.wantStdout {
+			t.Errorf("%v: stdout = %q, want %q", step.args, res.stdout, step.wantStdout)
+		}
+	}
+
+	info, err := os.Stat(filepath.Join(home.dir, "config", "tinyurl", "config.yaml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if mode := info.Mode().Perm(); mode != 0o600 {
+		t.Errorf("config mode = %o, want 600", mode)
+	}
+}
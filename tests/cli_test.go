@@ -0,0 +1,263 @@
+package tests
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+
+	"tinyurl/pkg/client"
+)
+
+var (
+	cliOnce sync.Once
+	cliDir  string
+	cliPath string
+	cliErr  error
+)
+
+// buildCLI собирает CLI один раз за прогон: коды выхода проверяются у настоящего
+// процесса, как их видят скрипты.
+func buildCLI(t *testing.T) string {
+	t.Helper()
+
+	cliOnce.Do(func() {
+		if cliDir, cliErr = os.MkdirTemp("", "tinyurl-cli-test"); cliErr != nil {
+			return
+		}
+		cliPath = filepath.Join(cliDir, "tinyurl")
+		out, err := exec.Command("go", "build", "-o", cliPath, "tinyurl/cmd/cli").CombinedOutput()
+		if err != nil {
+			cliErr = errors.New(string(out))
+		}
+	})
+	if cliErr != nil {
+		t.Fatalf("build cli: %v", cliErr)
+	}
+	return cliPath
+}
+
+func removeCLI() {
+	if cliDir != "" {
+		os.RemoveAll(cliDir)
+	}
+}
+
+// cliHome - изолированное окружение CLI: свои каталоги настроек и кэша и никаких
+// переменных TINYURL_* из окружения теста.
+type cliHome struct {
+	dir string
+	env []string
+}
+
+func newCLIHome(t *testing.T, env ...string) *cliHome {
+	t.Helper()
+
+	dir := t.TempDir()
+	return &cliHome{dir: dir, env: append([]string{
+		"PATH=" + os.Getenv("PATH"),
+		"HOME=" + dir,
+		"XDG_CONFIG_HOME=" + filepath.Join(dir, "config"),
+		"XDG_CACHE_HOME=" + filepath.Join(dir, "cache"),
+		"LANG=C",
+	}, env...)}
+}
+
+type cliResult struct {
+	stdout string
+	stderr string
+	code   int
+}
+
+func (h *cliHome) run(t *testing.T, args ...string) cliResult {
+	t.Helper()
+
+	cmd := exec.Command(buildCLI(t), args...)
+	cmd.Env = h.env
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout, cmd.Stderr = &stdout, &stderr
+	err := cmd.Run()
+
+	var exitErr *exec.ExitError
+	if err != nil && !errors.As(err, &exitErr) {
+		t.Fatalf("run cli: %v", err)
+	}
+	return cliResult{stdout: stdout.String(), stderr: stderr.String(), code: cmd.ProcessState.ExitCode()}
+}
+
+// problemServer отвечает на любой запрос ошибкой в формате problem+json.
+func problemServer(t *testing.T, status int, code string) string {
+	t.Helper()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/problem+json")
+		w.WriteHeader(status)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"type": "urn:tinyurl:error:" + code, "status": status, "code": code, "message": "stub " + code,
+		})
+	}))
+	t.Cleanup(srv.Close)
+	return srv.URL
+}
+
+func TestCLIOutput(t *testing.T) {
+	api := newTestClient(t, nil)
+	if _, err := api.CreateLink(context.Background(), client.ShortenRequest{URL: "https://example.com/cli-output", Alias: "cli-output"}); err != nil {
+		t.Fatal(err)
+	}
+	home := newCLIHome(t)
+
+	tests := []struct {
+		name  string
+		args  []string
+		check func(t *testing.T, stdout string)
+	}{
+		{
+			name: "Table", args: []string{"stats", "cli-output"},
+			check: func(t *testing.T, stdout string) {
+				if !strings.Contains(stdout, "URL: https://example.com/cli-output\n") {
+					t.Errorf("stdout = %q", stdout)
+				}
+			},
+		},
+		{
+			name: "JSON", args: []string{"-o", "json", "stats", "cli-output"},
+			check: func(t *testing.T, stdout string) {
+				var stats client.StatsResponse
+				if err := json.Unmarshal([]byte(stdout), &stats); err != nil || stats.URL != "https://example.com/cli-output" {
+					t.Errorf("stdout = %q (%v)", stdout, err)
+				}
+			},
+		},
+		{
+			name: "YAML", args: []string{"-o", "yaml", "stats", "cli-output"},
+			check: func(t *testing.T, stdout string) {
+				var stats map[string]interface{}
+				if err := yaml.Unmarshal([]byte(stdout), &stats); err != nil || stats["url"] != "https://example.com/cli-output" || stats["hit_count"] != 0 {
+					t.Errorf("stdout = %q (%v)", stdout, err)
+				}
+			},
+		},
+		{
+			name: "Template", args: []string{"-o", "template={{.url}} {{.hit_count}}", "stats", "cli-output"},
+			check: func(t *testing.T, stdout string) {
+				if stdout != "https://example.com/cli-output 0\n" {
+					t.Errorf("stdout = %q", stdout)
+				}
+			},
+		},
+		{
+			name: "Quiet", args: []string{"-q", "short", "https://example.com/cli-quiet", "-a", "cli-quiet"},
+			check: func(t *testing.T, stdout string) {
+				if stdout != api.BaseURL+"/r/cli-quiet\n" {
+					t.Errorf("stdout = %q", stdout)
+				}
+			},
+		},
+		{
+			name: "Quiet list prints codes", args: []string{"-q", "list", "--search", "cli-output"},
+			check: func(t *testing.T, stdout string) {
+				if stdout != "cli-output\n" {
+					t.Errorf("stdout = %q", stdout)
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res := home.run(t, append([]string{"-s", api.BaseURL}, tt.args...)...)
+			if res.code != 0 {
+				t.Fatalf("exit code = %d, stderr = %q", res.code, res.stderr)
+			}
+			tt.check(t, res.stdout)
+		})
+	}
+}
+
+func TestCLIExitCodes(t *testing.T) {
+	api := newTestClient(t, nil)
+	if _, err := api.CreateLink(context.Background(), client.ShortenRequest{URL: "https://example.com/cli-exit", Alias: "cli-exit"}); err != nil {
+		t.Fatal(err)
+	}
+	closed := httptest.NewServer(http.NotFoundHandler())
+	closed.Close()
+
+	tests := []struct {
+		name       string
+		server     string
+		args       []string
+		wantCode   int
+		wantStderr string
+	}{
+		{name: "OK", server: api.BaseURL, args: []string{"stats", "cli-exit"}, wantCode: 0},
+		{name: "Not found", server: api.BaseURL, args: []string{"stats", "cli-exit-missing"}, wantCode: 3, wantStderr: "[link_not_found]"},
+		{name: "Alias taken", server: api.BaseURL, args: []string{"short", "https://example.com", "-a", "cli-exit"}, wantCode: 4, wantStderr: "[alias_taken]"},
+		{name: "Bad request", server: api.BaseURL, args: []string{"short", ""}, wantCode: 5, wantStderr: "[url_required]"},
+		{name: "Unprocessable", server: problemServer(t, http.StatusUnprocessableEntity, "idempotency_key_reused"), args: []string{"short", "https://example.com"}, wantCode: 5, wantStderr: "[idempotency_key_reused]"},
+		{name: "Server error", server: problemServer(t, http.StatusInternalServerError, "internal_error"), args: []string{"stats", "cli-exit"}, wantCode: 6, wantStderr: "[internal_error]"},
+		{name: "Network error", server: closed.URL, args: []string{"stats", "cli-exit"}, wantCode: 7},
+		{name: "Unknown flag", server: api.BaseURL, args: []string{"stats", "--bogus", "cli-exit"}, wantCode: 2},
+		{name: "Missing argument", server: api.BaseURL, args: []string{"stats"}, wantCode: 2},
+		{name: "Invalid output", server: api.BaseURL, args: []string{"-o", "xml", "stats", "cli-exit"}, wantCode: 2},
+		{name: "Invalid template", server: api.BaseURL, args: []string{"-o", "template={{.url", "stats", "cli-exit"}, wantCode: 2},
+	}
+
+	home := newCLIHome(t)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res := home.run(t, append([]string{"-s", tt.server}, tt.args...)...)
+			if res.code != tt.wantCode {
+				t.Fatalf("exit code = %d, want %d, stderr = %q", res.code, tt.wantCode, res.stderr)
+			}
+			if !strings.Contains(res.stderr, tt.wantStderr) {
+				t.Errorf("stderr = %q, want %q", res.stderr, tt.wantStderr)
+			}
+			if tt.wantCode != 0 && res.stdout != "" {
+				t.Errorf("stdout = %q, want nothing on error", res.stdout)
+			}
+		})
+	}
+}
+
+func TestCLIDomain(t *testing.T) {
+	api := newTestClient(t, nil)
+	createDomain(t, map[string]interface{}{"host": "cli.example.com"})
+	home := newCLIHome(t)
+	run := func(args ...string) cliResult {
+		t.Helper()
+		return home.run(t, append([]string{"-s", api.BaseURL}, args...)...)
+	}
+
+	res := run("-q", "short", "https://example.com/cli-domain", "-a", "cli-domain", "-d", "cli.example.com")
+	if res.code != 0 || res.stdout != "http://cli.example.com/r/cli-domain\n" {
+		t.Fatalf("short: code = %d, stdout = %q, stderr = %q", res.code, res.stdout, res.stderr)
+	}
+
+	res = run("-o", "template={{.url}} {{.domain}}", "stats", "cli-domain", "--domain", "cli.example.com")
+	if res.code != 0 || res.stdout != "https://example.com/cli-domain cli.example.com\n" {
+		t.Errorf("stats: code = %d, stdout = %q, stderr = %q", res.code, res.stdout, res.stderr)
+	}
+	if res = run("stats", "cli-domain"); res.code != 3 {
+		t.Errorf("stats in the default domain: code = %d, want 3", res.code)
+	}
+
+	file := filepath.Join(home.dir, "cli-domain.svg")
+	res = run("qr", "cli-domain", "-d", "cli.example.com", "-f", file)
+	if res.code != 0 {
+		t.Fatalf("qr: code = %d, stderr = %q", res.code, res.stderr)
+	}
+	if data, err := os.ReadFile(file); err != nil || !strings.Contains(string(data), "<svg") {
+		t.Errorf("qr file: %v", err)
+	}
+}
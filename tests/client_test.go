@@ -0,0 +1,470 @@
+package tests
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os/exec"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"tinyurl/internal/apierror"
+	"tinyurl/internal/i18n"
+	"tinyurl/internal/models"
+	"tinyurl/pkg/client"
+)
+
+// newTestClient запускает HTTP-сервер с настоящими обработчиками API. Обертка
+// wrap, если задана, стоит перед ними.
+func newTestClient(t *testing.T, wrap func(http.Handler) http.Handler) *client.Client {
+	t.Helper()
+
+	mux := newAPIMux()
+	mux.HandleFunc("/healthz", testServer.HealthHandler)
+	mux.HandleFunc("/readyz", testServer.ReadyHandler)
+	handler := i18n.Middleware(i18n.Russian, mux)
+	if wrap != nil {
+		handler = wrap(handler)
+	}
+	srv := httptest.NewServer(handler)
+	t.Cleanup(srv.Close)
+
+	c := client.New(srv.URL + "/")
+	c.BaseDelay = time.Millisecond
+	c.MaxDelay = 10 * time.Millisecond
+	return c
+}
+
+func TestClientLinks(t *testing.T) {
+	ctx := context.Background()
+	c := newTestClient(t, nil)
+
+	if _, err := c.CreateDomain(ctx, client.Domain{Host: "sdk.example"}); err != nil {
+		t.Fatal(err)
+	}
+	for _, alias := range []string{"sdk-one", "sdk-two", "sdk-three"} {
+		created, err := c.CreateLink(ctx, client.ShortenRequest{URL: "https://example.com/" + alias, Alias: alias, Domain: "sdk.example"})
+		if err != nil {
+			t.Fatalf("create %s: %v", alias, err)
+		}
+		if created.Code != alias || created.ShortURL != "http://sdk.example/r/"+alias {
+			t.Errorf("created = %+v", created)
+		}
+	}
+
+	_, err := c.CreateLink(ctx, client.ShortenRequest{URL: "https://example.com", Alias: "sdk-one", Domain: "sdk.example"})
+	if !client.IsConflict(err) {
+		t.Errorf("duplicate alias error = %v", err)
+	}
+
+	page, err := c.ListLinks(ctx, client.ListOptions{Domain: "sdk.example", PageSize: 2})
+	if err != nil || len(page.Links) != 2 || page.NextPageToken == "" {
+		t.Fatalf("first page = %+v, %v", page, err)
+	}
+	all, err := c.AllLinks(ctx, client.ListOptions{Domain: "sdk.example", PageSize: 1})
+	if err != nil || len(all) != 3 {
+		t.Fatalf("all links = %+v, %v", all, err)
+	}
+	found, err := c.ListLinks(ctx, client.ListOptions{Domain: "sdk.example", Search: "two"})
+	if err != nil || len(found.Links) != 1 || found.Links[0].Code != "sdk-two" {
+		t.Errorf("search = %+v, %v", found, err)
+	}
+
+	target := "https://example.org/updated"
+	ttl := 5
+	updated, err := c.UpdateLink(ctx, "sdk.example", "sdk-one", client.LinkUpdate{URL: &target, TTLDays: &ttl})
+	if err != nil || updated.URL != target || updated.ExpiresAt == nil {
+		t.Errorf("updated = %+v, %v", updated, err)
+	}
+	stats, err := c.GetLink(ctx, "sdk.example", "sdk-one")
+	if err != nil || stats.URL != target || stats.Domain != "sdk.example" {
+		t.Errorf("stats = %+v, %v", stats, err)
+	}
+	resolved, err := c.ResolveLink(ctx, "sdk.example", "sdk-one")
+	if err != nil || resolved.URL != target || resolved.RedirectType != http.StatusFound {
+		t.Errorf("resolved = %+v, %v", resolved, err)
+	}
+
+	margin := 0
+	png, err := c.QRCode(ctx, "sdk.example", "sdk-one", client.QROptions{Size: 128, Margin: &margin})
+	if err != nil || !bytes.HasPrefix(png, []byte("\x89PNG")) {
+		t.Errorf("png = %d bytes, %v", len(png), err)
+	}
+	svg, err := c.QRCode(ctx, "sdk.example", "sdk-one", client.QROptions{Format: "svg"})
+	if err != nil || !bytes.Contains(svg, []byte("<svg")) {
+		t.Errorf("svg = %d bytes, %v", len(svg), err)
+	}
+	_, err = c.QRCode(ctx, "sdk.example", "sdk-one", client.QROptions{Size: 10})
+	if client.StatusCode(err) != http.StatusBadRequest {
+		t.Errorf("invalid qr size error = %v", err)
+	}
+
+	for _, code := range []string{"sdk-one", "sdk-two", "sdk-three"} {
+		if err := c.DeleteLink(ctx, "sdk.example", code); err != nil {
+			t.Fatalf("delete %s: %v", code, err)
+		}
+	}
+	_, err = c.GetLink(ctx, "sdk.example", "sdk-one")
+	var apiErr *client.Error
+	if !errors.As(err, &apiErr) || !client.IsNotFound(err) || apiErr.Problem.Code != client.LinkNotFound {
+		t.Errorf("deleted link error = %#v", err)
+	}
+	if err := c.DeleteDomain(ctx, "sdk.example"); err != nil {
+		t.Errorf("delete domain: %v", err)
+	}
+}
+
+func TestClientResources(t *testing.T) {
+	ctx := context.Background()
+	c := newTestClient(t, nil)
+
+	domain, err := c.CreateDomain(ctx, client.Domain{Host: "sdk-res.example", RedirectType: http.StatusMovedPermanently})
+	if err != nil || domain.Host != "sdk-res.example" {
+		t.Fatalf("create domain = %+v, %v", domain, err)
+	}
+	if domain, err = c.UpdateDomain(ctx, "sdk-res.example", client.Domain{CodeLength: 9}); err != nil || domain.CodeLength != 9 {
+		t.Errorf("update domain = %+v, %v", domain, err)
+	}
+	if domain, err = c.GetDomain(ctx, "sdk-res.example"); err != nil || domain.CodeLength != 9 {
+		t.Errorf("get domain = %+v, %v", domain, err)
+	}
+	domains, err := c.ListDomains(ctx)
+	if err != nil || len(domains) == 0 {
+		t.Errorf("domains = %+v, %v", domains, err)
+	}
+	if err := c.DeleteDomain(ctx, "sdk-res.example"); err != nil {
+		t.Errorf("delete domain: %v", err)
+	}
+	if _, err := c.GetDomain(ctx, "sdk-res.example"); !client.IsNotFound(err) {
+		t.Errorf("deleted domain error = %v", err)
+	}
+
+	tmpl, err := c.CreateUTMTemplate(ctx, client.UTMTemplate{Name: "sdk-utm", Source: "sdk", Medium: "go", Campaign: "spring"})
+	if err != nil || tmpl.Name != "sdk-utm" {
+		t.Fatalf("create template = %+v, %v", tmpl, err)
+	}
+	if tmpl, err = c.UpdateUTMTemplate(ctx, "sdk-utm", client.UTMTemplate{Source: "sdk", Medium: "go", Campaign: "autumn"}); err != nil || tmpl.Campaign != "autumn" {
+		t.Errorf("update template = %+v, %v", tmpl, err)
+	}
+	if tmpl, err = c.GetUTMTemplate(ctx, "sdk-utm"); err != nil || tmpl.Campaign != "autumn" {
+		t.Errorf("get template = %+v, %v", tmpl, err)
+	}
+	if templates, err := c.ListUTMTemplates(ctx); err != nil || len(templates) == 0 {
+		t.Errorf("templates = %+v, %v", templates, err)
+	}
+	if err := c.DeleteUTMTemplate(ctx, "sdk-utm"); err != nil {
+		t.Errorf("delete template: %v", err)
+	}
+	if _, err := c.Campaigns(ctx); err != nil {
+		t.Errorf("campaigns: %v", err)
+	}
+
+	webhook, err := c.CreateWebhook(ctx, client.Webhook{URL: "https://hooks.example/sdk", Events: []string{"link.created"}})
+	if err != nil || webhook.ID == 0 || webhook.Secret == "" {
+		t.Fatalf("create webhook = %+v, %v", webhook, err)
+	}
+	if got, err := c.GetWebhook(ctx, webhook.ID); err != nil || got.URL != webhook.URL || got.Secret != "" {
+		t.Errorf("get webhook = %+v, %v", got, err)
+	}
+	if webhooks, err := c.ListWebhooks(ctx); err != nil || len(webhooks) == 0 {
+		t.Errorf("webhooks = %+v, %v", webhooks, err)
+	}
+	if _, err := c.ListDeliveries(ctx, webhook.ID, client.DeliveryDead); err != nil {
+		t.Errorf("deliveries: %v", err)
+	}
+	if _, err := c.ListDeliveries(ctx, webhook.ID, "lost"); client.StatusCode(err) != http.StatusBadRequest {
+		t.Errorf("invalid status error = %v", err)
+	}
+	if _, err := c.RetryDelivery(ctx, webhook.ID, 999999); !client.IsNotFound(err) {
+		t.Errorf("retry unknown delivery error = %v", err)
+	}
+	if err := c.DeleteWebhook(ctx, webhook.ID); err != nil {
+		t.Errorf("delete webhook: %v", err)
+	}
+
+	spec, err := c.OpenAPI(ctx)
+	if err != nil || !bytes.Contains(spec, []byte(`"openapi"`)) {
+		t.Errorf("openapi = %d bytes, %v", len(spec), err)
+	}
+	for _, endpoint := range []string{"healthz", "readyz"} {
+		if body, err := c.Probe(ctx, endpoint); err != nil || !bytes.Contains(body, []byte(`"ok"`)) {
+			t.Errorf("%s = %s, %v", endpoint, body, err)
+		}
+	}
+}
+
+func TestClientHeaders(t *testing.T) {
+	var got http.Header
+	c := newTestClient(t, func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			got = r.Header.Clone()
+			next.ServeHTTP(w, r)
+		})
+	})
+	c.Token = "sdk-token"
+	c.Language = "en"
+	c.UserAgent = "sdk-test"
+
+	_, err := c.GetLink(context.Background(), "", "sdk-missing")
+	var apiErr *client.Error
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("error = %v", err)
+	}
+	if apiErr.Problem.Message != "Link not found" {
+		t.Errorf("message = %q, want English", apiErr.Problem.Message)
+	}
+	for header, want := range map[string]string{
+		"Authorization": "Bearer sdk-token", "Accept-Language": "en", "User-Agent": "sdk-test",
+	} {
+		if got.Get(header) != want {
+			t.Errorf("%s = %q, want %q", header, got.Get(header), want)
+		}
+	}
+}
+
+func TestClientRetries(t *testing.T) {
+	tests := []struct {
+		name         string
+		failures     int32
+		status       int
+		retryAfter   string
+		post         bool
+		create       bool
+		wantAttempts int32
+		wantStatus   int
+	}{
+		{name: "Recovers after 503", failures: 2, status: http.StatusServiceUnavailable, wantAttempts: 3},
+		{name: "Recovers after 429", failures: 1, status: http.StatusTooManyRequests, retryAfter: "0", wantAttempts: 2},
+		{name: "Gives up after MaxRetries", failures: 5, status: http.StatusBadGateway, wantAttempts: 3, wantStatus: http.StatusBadGateway},
+		{name: "500 is not retried", failures: 1, status: http.StatusInternalServerError, wantAttempts: 1, wantStatus: http.StatusInternalServerError},
+		{name: "POST is not retried", failures: 1, status: http.StatusServiceUnavailable, post: true, wantAttempts: 1, wantStatus: http.StatusServiceUnavailable},
+		{name: "Create with Idempotency-Key is retried", failures: 1, status: http.StatusServiceUnavailable, create: true, wantAttempts: 2},
+		{name: "Long Retry-After is not waited", failures: 1, status: http.StatusServiceUnavailable, retryAfter: "60", wantAttempts: 1, wantStatus: http.StatusServiceUnavailable},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var attempts atomic.Int32
+			var mu sync.Mutex
+			keys := make(map[string]bool)
+			c := newTestClient(t, func(next http.Handler) http.Handler {
+				return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					mu.Lock()
+					keys[r.Header.Get(client.IdempotencyKeyHeader)] = true
+					mu.Unlock()
+					if attempts.Add(1) <= tt.failures {
+						if tt.retryAfter != "" {
+							w.Header().Set("Retry-After", tt.retryAfter)
+						}
+						http.Error(w, "try later", tt.status)
+						return
+					}
+					next.ServeHTTP(w, r)
+				})
+			})
+
+			var err error
+			switch {
+			case tt.post:
+				_, err = c.RetryDelivery(context.Background(), 1, 1)
+			case tt.create:
+				_, err = c.CreateLink(context.Background(), client.ShortenRequest{URL: "https://example.com/retry"})
+				mu.Lock()
+				if len(keys) != 1 || keys[""] {
+					t.Errorf("Idempotency-Key values = %v, want one key for all attempts", keys)
+				}
+				mu.Unlock()
+			default:
+				_, err = c.ListDomains(context.Background())
+			}
+
+			if got := attempts.Load(); got != tt.wantAttempts {
+				t.Errorf("attempts = %d, want %d", got, tt.wantAttempts)
+			}
+			if tt.wantStatus == 0 {
+				if err != nil {
+					t.Errorf("error = %v", err)
+				}
+				return
+			}
+			var apiErr *client.Error
+			if !errors.As(err, &apiErr) || apiErr.StatusCode != tt.wantStatus || apiErr.Body != "try later" {
+				t.Errorf("error = %#v", err)
+			}
+		})
+	}
+}
+
+func TestClientContext(t *testing.T) {
+	c := newTestClient(t, func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		})
+	})
+	c.BaseDelay = time.Hour
+	c.MaxDelay = time.Hour
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	_, err := c.ListDomains(ctx)
+	if client.StatusCode(err) != http.StatusServiceUnavailable {
+		t.Errorf("error = %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("canceled request took %v", elapsed)
+	}
+
+	slow := newTestClient(t, func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			select {
+			case <-r.Context().Done():
+			case <-time.After(time.Second):
+			}
+		})
+	})
+	slow.HTTPClient.Timeout = 20 * time.Millisecond
+	slow.MaxRetries = 0
+	if _, err := slow.ListDomains(context.Background()); err == nil || client.StatusCode(err) != 0 {
+		t.Errorf("timeout error = %v", err)
+	}
+}
+
+// Клиент подключают отдельно от сервера, поэтому он не должен тянуть
+// внутренние пакеты.
+func TestClientDependencies(t *testing.T) {
+	out, err := exec.Command("go", "list", "-deps", "tinyurl/pkg/client").CombinedOutput()
+	if err != nil {
+		t.Fatalf("go list: %v\n%s", err, out)
+	}
+	for _, pkg := range strings.Fields(string(out)) {
+		if strings.HasPrefix(pkg, "tinyurl/internal/") {
+			t.Errorf("pkg/client depends on %s", pkg)
+		}
+	}
+}
+
+func TestClientErrorCodes(t *testing.T) {
+	codes := map[apierror.Code]client.ErrorCode{
+		apierror.Internal:              client.Internal,
+		apierror.NotFound:              client.NotFound,
+		apierror.MethodNotAllowed:      client.MethodNotAllowed,
+		apierror.InvalidJSON:           client.InvalidJSON,
+		apierror.URLRequired:           client.URLRequired,
+		apierror.InvalidURL:            client.InvalidURL,
+		apierror.InvalidAlias:          client.InvalidAlias,
+		apierror.InvalidRedirectType:   client.InvalidRedirectType,
+		apierror.InvalidRules:          client.InvalidRules,
+		apierror.InvalidVariants:       client.InvalidVariants,
+		apierror.InvalidGeo:            client.InvalidGeo,
+		apierror.InvalidUTMApply:       client.InvalidUTMApply,
+		apierror.InvalidUTMTemplate:    client.InvalidUTMTemplate,
+		apierror.InvalidPassthrough:    client.InvalidPassthrough,
+		apierror.InvalidTTL:            client.InvalidTTL,
+		apierror.InvalidPageToken:      client.InvalidPageToken,
+		apierror.InvalidPageSize:       client.InvalidPageSize,
+		apierror.InvalidWebhook:        client.InvalidWebhook,
+		apierror.InvalidQROptions:      client.InvalidQROptions,
+		apierror.InvalidHost:           client.InvalidHost,
+		apierror.InvalidCodeLength:     client.InvalidCodeLength,
+		apierror.InvalidNotFoundURL:    client.InvalidNotFoundURL,
+		apierror.StickyWithoutVariant:  client.StickyWithoutVariant,
+		apierror.LinkNotFound:          client.LinkNotFound,
+		apierror.DomainNotFound:        client.DomainNotFound,
+		apierror.UTMTemplateNotFound:   client.UTMTemplateNotFound,
+		apierror.WebhookNotFound:       client.WebhookNotFound,
+		apierror.DeliveryNotFound:      client.DeliveryNotFound,
+		apierror.AliasTaken:            client.AliasTaken,
+		apierror.DomainExists:          client.DomainExists,
+		apierror.DomainInUse:           client.DomainInUse,
+		apierror.UTMTemplateExists:     client.UTMTemplateExists,
+		apierror.UTMTemplateInUse:      client.UTMTemplateInUse,
+		apierror.CodeGenerationFailed:  client.CodeGenerationFailed,
+		apierror.BaseURLRequired:       client.BaseURLRequired,
+		apierror.InvalidIdempotencyKey: client.InvalidIdempotencyKey,
+		apierror.IdempotencyKeyReused:  client.IdempotencyKeyReused,
+		apierror.IdempotencyInProgress: client.IdempotencyInProgress,
+	}
+	for server, sdk := range codes {
+		if string(server) != string(sdk) {
+			t.Errorf("client code %q, server code %q", sdk, server)
+		}
+	}
+}
+
+// Типы клиента повторяют модели сервера: ответ сервера, прочитанный клиентом и
+// записанный снова, не теряет полей.
+func TestClientTypesMatchServer(t *testing.T) {
+	now := time.Now().UTC().Truncate(time.Second)
+	bot := true
+	tests := []struct {
+		name   string
+		server interface{}
+		sdk    interface{}
+	}{
+		{
+			name: "ShortenRequest",
+			server: models.ShortenRequest{
+				URL: "https://example.com", Alias: "a", TTLDays: 1, RedirectType: 301, Passthrough: true,
+				UTMTemplate: "t", UTMApply: "redirect", Rules: []models.TargetRule{{Name: "r", OS: "ios", Device: "mobile", Bot: &bot, URL: "https://r.example"}},
+				Variants: []models.Variant{{Name: "v", URL: "https://v.example", Weight: 1}}, Sticky: true,
+				Geo: map[string]string{"DE": "https://de.example"}, Interstitial: true, Domain: "sho.rt",
+			},
+			sdk: &client.ShortenRequest{},
+		},
+		{
+			name: "StatsResponse",
+			server: models.StatsResponse{
+				URL: "https://example.com", CreatedAt: now, ExpiresAt: &now, HitCount: 2, RedirectType: 302, Passthrough: true,
+				UTMTemplate: "t", Breakdown: models.Breakdown{"campaign": {"spring": 2}}, Sticky: true,
+				Geo: map[string]string{"DE": "https://de.example"}, Interstitial: true, Domain: "sho.rt",
+			},
+			sdk: &client.StatsResponse{},
+		},
+		{
+			name: "LinkList",
+			server: models.LinkList{Links: []models.LinkSummary{{
+				Code: "c", Domain: "sho.rt", ShortURL: "https://sho.rt/r/c", URL: "https://example.com", CreatedAt: now, ExpiresAt: &now, HitCount: 1,
+			}}, NextPageToken: "next"},
+			sdk: &client.LinkList{},
+		},
+		{
+			name:   "Domain",
+			server: models.Domain{ID: 1, Host: "sho.rt", RedirectType: 301, NotFoundURL: "https://example.com", CodeLength: 8, CreatedAt: now},
+			sdk:    &client.Domain{},
+		},
+		{
+			name:   "UTMTemplate",
+			server: models.UTMTemplate{ID: 1, Name: "t", Source: "s", Medium: "m", Campaign: "c", Term: "term", Content: "content", CreatedAt: now},
+			sdk:    &client.UTMTemplate{},
+		},
+		{
+			name: "WebhookDelivery",
+			server: models.WebhookDelivery{
+				ID: 1, WebhookID: 2, EventID: "e", Event: "link.created", Status: models.DeliveryDead, Attempts: 3, NextAttemptAt: &now,
+				LastStatusCode: 500, LastError: "boom", CreatedAt: now, UpdatedAt: now,
+				Log: []models.WebhookAttempt{{Attempt: 1, StatusCode: 500, Error: "boom", DurationMS: 10, CreatedAt: now}},
+			},
+			sdk: &client.WebhookDelivery{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			want, _ := json.Marshal(tt.server)
+			if err := json.Unmarshal(want, tt.sdk); err != nil {
+				t.Fatal(err)
+			}
+			got, _ := json.Marshal(tt.sdk)
+			if !bytes.Equal(got, want) {
+				t.Errorf("client JSON:\n%s\nserver JSON:\n%s", got, want)
+			}
+		})
+	}
+}
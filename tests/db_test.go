@@ -1,8 +1,11 @@
 package tests
 
 import (
+	"context"
 	"database/sql"
+	"slices"
 	"testing"
+	"time"
 
 	"tinyurl/internal/db"
 
@@ -16,19 +19,8 @@ func TestInsertAndGetLink(t *testing.T) {
 	}
 	defer database.Close()
 
-	_, err = database.Exec(`
-	CREATE TABLE IF NOT EXISTS links (
-	  id          INTEGER PRIMARY KEY AUTOINCREMENT,
-	  code        TEXT    NOT NULL UNIQUE,
-	  url         TEXT    NOT NULL,
-	  created_at  TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
-	  expires_at  TIMESTAMP NULL,
-	  hit_count   INTEGER NOT NULL DEFAULT 0
-	);
-	CREATE INDEX IF NOT EXISTS idx_links_expires_at ON links (expires_at);
-	`)
-	if err != nil {
-		t.Fatalf("Failed to create schema: %v", err)
+	if err = db.Migrate(database); err != nil {
+		t.Fatalf("Failed to migrate schema: %v", err)
 	}
 
 	testCases := []struct {
@@ -82,18 +74,8 @@ func TestIncrementHitCount(t *testing.T) {
 	}
 	defer database.Close()
 
-	_, err = database.Exec(`
-	CREATE TABLE IF NOT EXISTS links (
-	  id          INTEGER PRIMARY KEY AUTOINCREMENT,
-	  code        TEXT    NOT NULL UNIQUE,
-	  url         TEXT    NOT NULL,
-	  created_at  TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
-	  expires_at  TIMESTAMP NULL,
-	  hit_count   INTEGER NOT NULL DEFAULT 0
-	);
-	`)
-	if err != nil {
-		t.Fatalf("Failed to create schema: %v", err)
+	if err = db.Migrate(database); err != nil {
+		t.Fatalf("Failed to migrate schema: %v", err)
 	}
 
 	code := "increment_test"
@@ -125,3 +107,70 @@ func TestIncrementHitCount(t *testing.T) {
 		t.Errorf("Hit count should be 1 after increment, got %d", link.HitCount)
 	}
 }
+
+func TestExpiredLinks(t *testing.T) {
+	database, err := sql.Open("sqlite", "file:test_db_expired.db?mode=memory&cache=shared")
+	if err != nil {
+		t.Fatalf("Failed to open database: %v", err)
+	}
+	defer database.Close()
+
+	if err = db.Migrate(database); err != nil {
+		t.Fatalf("Failed to migrate schema: %v", err)
+	}
+
+	ctx := context.Background()
+	now := time.Now()
+	msk := time.FixedZone("MSK", 3*60*60)
+	expire := func(code string, at time.Time) {
+		t.Helper()
+		if err := db.InsertLink(database, code, "https://example.com/"+code, 0); err != nil {
+			t.Fatalf("InsertLink failed: %v", err)
+		}
+		link, err := db.GetLink(database, code)
+		if err != nil || link == nil {
+			t.Fatalf("GetLink failed: %v", err)
+		}
+		link.ExpiresAt = &at
+		if err := db.UpdateLink(ctx, database, link); err != nil {
+			t.Fatalf("UpdateLink failed: %v", err)
+		}
+	}
+	expire("expired-day", now.Add(-24*time.Hour))
+	expire("expired-hour", now.Add(-time.Hour).In(msk))
+	expire("active", now.Add(time.Hour))
+	if err := db.InsertLink(database, "no-ttl", "https://example.com", 0); err != nil {
+		t.Fatalf("InsertLink failed: %v", err)
+	}
+
+	// Срок, записанный старой версией не в UTC, приводится к UTC миграцией.
+	if err := db.InsertLink(database, "legacy", "https://example.com/legacy", 0); err != nil {
+		t.Fatalf("InsertLink failed: %v", err)
+	}
+	if _, err := database.Exec("UPDATE links SET expires_at = ? WHERE code = 'legacy'", now.Add(-time.Minute).In(msk)); err != nil {
+		t.Fatal(err)
+	}
+	if err = db.Migrate(database); err != nil {
+		t.Fatalf("Failed to migrate schema: %v", err)
+	}
+
+	codes := func(limit int) []string {
+		t.Helper()
+		links, err := db.ExpiredLinks(ctx, database, now, limit)
+		if err != nil {
+			t.Fatalf("ExpiredLinks failed: %v", err)
+		}
+		var codes []string
+		for _, link := range links {
+			codes = append(codes, link.Code)
+		}
+		return codes
+	}
+
+	if got, want := codes(10), []string{"expired-day", "expired-hour", "legacy"}; !slices.Equal(got, want) {
+		t.Errorf("ExpiredLinks = %v, want %v", got, want)
+	}
+	if got, want := codes(2), []string{"expired-day", "expired-hour"}; !slices.Equal(got, want) {
+		t.Errorf("ExpiredLinks with limit = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,296 @@
+package tests
+
+import (
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"tinyurl/internal/db"
+	"tinyurl/internal/models"
+)
+
+func createDomain(t *testing.T, domain map[string]interface{}) {
+	t.Helper()
+
+	rr := doJSON(t, testServer.DomainsHandler, http.MethodPost, "/domains", domain)
+	if rr.Code != http.StatusCreated {
+		t.Fatalf("create domain returned %v: %s", rr.Code, rr.Body.String())
+	}
+}
+
+func redirectVia(host, path string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, path, nil)
+	req.Host = host
+	rr := httptest.NewRecorder()
+	testServer.RedirectHandler(rr, req)
+	return rr
+}
+
+func TestDomainsCRUD(t *testing.T) {
+	createDomain(t, map[string]interface{}{"host": "Crud.Example.COM", "redirect_type": 301})
+
+	rr := doJSON(t, testServer.DomainsHandler, http.MethodPost, "/domains", map[string]interface{}{"host": "crud.example.com"})
+	if rr.Code != http.StatusConflict {
+		t.Errorf("duplicate create returned %v, want %v", rr.Code, http.StatusConflict)
+	}
+
+	rr = doJSON(t, testServer.DomainHandler, http.MethodGet, "/domains/crud.example.com", nil)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("get returned %v", rr.Code)
+	}
+	var got models.Domain
+	json.NewDecoder(rr.Body).Decode(&got)
+	if got.Host != "crud.example.com" || got.RedirectType != 301 {
+		t.Errorf("got %+v", got)
+	}
+
+	rr = doJSON(t, testServer.DomainHandler, http.MethodPut, "/domains/crud.example.com", map[string]interface{}{"code_length": 8})
+	if rr.Code != http.StatusOK {
+		t.Fatalf("update returned %v: %s", rr.Code, rr.Body.String())
+	}
+	got = models.Domain{}
+	json.NewDecoder(rr.Body).Decode(&got)
+	if got.CodeLength != 8 || got.RedirectType != 0 {
+		t.Errorf("after update got %+v", got)
+	}
+
+	rr = doJSON(t, testServer.ShortenHandler, http.MethodPost, "/shorten", map[string]interface{}{
+		"url": "https://example.com", "alias": "crud-link", "domain": "crud.example.com",
+	})
+	if rr.Code != http.StatusOK {
+		t.Fatalf("shorten returned %v: %s", rr.Code, rr.Body.String())
+	}
+
+	rr = doJSON(t, testServer.DomainHandler, http.MethodDelete, "/domains/crud.example.com", nil)
+	if rr.Code != http.StatusConflict {
+		t.Errorf("delete domain with links returned %v, want %v", rr.Code, http.StatusConflict)
+	}
+
+	createDomain(t, map[string]interface{}{"host": "empty.example.com"})
+	rr = doJSON(t, testServer.DomainHandler, http.MethodDelete, "/domains/empty.example.com", nil)
+	if rr.Code != http.StatusNoContent {
+		t.Errorf("delete returned %v, want %v", rr.Code, http.StatusNoContent)
+	}
+}
+
+func TestDomainValidation(t *testing.T) {
+	tests := []struct {
+		name   string
+		domain map[string]interface{}
+	}{
+		{"bad host", map[string]interface{}{"host": "not a host"}},
+		{"empty host", map[string]interface{}{"host": ""}},
+		{"bad redirect type", map[string]interface{}{"host": "v1.example.com", "redirect_type": 303}},
+		{"short code length", map[string]interface{}{"host": "v2.example.com", "code_length": 2}},
+		{"long code length", map[string]interface{}{"host": "v3.example.com", "code_length": 100}},
+		{"relative not found url", map[string]interface{}{"host": "v4.example.com", "not_found_url": "/missing"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rr := doJSON(t, testServer.DomainsHandler, http.MethodPost, "/domains", tt.domain)
+			if rr.Code != http.StatusBadRequest {
+				t.Errorf("returned %v, want %v", rr.Code, http.StatusBadRequest)
+			}
+		})
+	}
+}
+
+func TestSameAliasOnDifferentDomains(t *testing.T) {
+	createDomain(t, map[string]interface{}{"host": "a.example.com"})
+	createDomain(t, map[string]interface{}{"host": "b.example.com"})
+
+	for host, url := range map[string]string{
+		"a.example.com": "https://example.com/a",
+		"b.example.com": "https://example.com/b",
+		"":              "https://example.com/default",
+	} {
+		body := map[string]interface{}{"url": url, "alias": "shared-alias"}
+		if host != "" {
+			body["domain"] = host
+		}
+		rr := doJSON(t, testServer.ShortenHandler, http.MethodPost, "/shorten", body)
+		if rr.Code != http.StatusOK {
+			t.Fatalf("shorten on %q returned %v: %s", host, rr.Code, rr.Body.String())
+		}
+		if host != "" {
+			var resp models.ShortenResponse
+			json.NewDecoder(rr.Body).Decode(&resp)
+			if want := "http://" + host + "/r/shared-alias"; resp.ShortURL != want {
+				t.Errorf("short_url = %q, want %q", resp.ShortURL, want)
+			}
+		}
+	}
+
+	rr := doJSON(t, testServer.ShortenHandler, http.MethodPost, "/shorten", map[string]interface{}{
+		"url": "https://example.com", "alias": "shared-alias", "domain": "a.example.com",
+	})
+	if rr.Code != http.StatusConflict {
+		t.Errorf("duplicate alias in domain returned %v, want %v", rr.Code, http.StatusConflict)
+	}
+
+	tests := []struct {
+		host string
+		want string
+	}{
+		{"a.example.com", "https://example.com/a"},
+		{"A.EXAMPLE.COM:8080", "https://example.com/a"},
+		{"b.example.com", "https://example.com/b"},
+		{"localhost:8080", "https://example.com/default"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.host, func(t *testing.T) {
+			rr := redirectVia(tt.host, "/r/shared-alias")
+			if rr.Code != http.StatusFound {
+				t.Fatalf("returned %v, want %v", rr.Code, http.StatusFound)
+			}
+			if got := rr.Header().Get("Location"); got != tt.want {
+				t.Errorf("Location = %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	rr = doJSON(t, testServer.StatsHandler, http.MethodGet, "/stats/shared-alias?domain=b.example.com", nil)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("stats returned %v", rr.Code)
+	}
+	var stats models.StatsResponse
+	json.NewDecoder(rr.Body).Decode(&stats)
+	if stats.URL != "https://example.com/b" || stats.Domain != "b.example.com" {
+		t.Errorf("stats = %+v", stats)
+	}
+
+	rr = doJSON(t, testServer.StatsHandler, http.MethodGet, "/stats/shared-alias?domain=missing.example.com", nil)
+	if rr.Code != http.StatusNotFound {
+		t.Errorf("stats for unknown domain returned %v, want %v", rr.Code, http.StatusNotFound)
+	}
+}
+
+func TestDomainDefaults(t *testing.T) {
+	createDomain(t, map[string]interface{}{
+		"host":          "go.example.com",
+		"redirect_type": 308,
+		"code_length":   10,
+		"not_found_url": "https://example.com/404",
+	})
+
+	rr := doJSON(t, testServer.ShortenHandler, http.MethodPost, "/shorten", map[string]interface{}{
+		"url": "https://example.com/page", "domain": "go.example.com",
+	})
+	if rr.Code != http.StatusOK {
+		t.Fatalf("shorten returned %v: %s", rr.Code, rr.Body.String())
+	}
+	var resp models.ShortenResponse
+	json.NewDecoder(rr.Body).Decode(&resp)
+	if len(resp.Code) != 10 {
+		t.Errorf("code %q has length %d, want 10", resp.Code, len(resp.Code))
+	}
+
+	rr = redirectVia("go.example.com", "/r/"+resp.Code)
+	if rr.Code != http.StatusPermanentRedirect {
+		t.Errorf("redirect returned %v, want %v", rr.Code, http.StatusPermanentRedirect)
+	}
+
+	rr = redirectVia("go.example.com", "/r/no-such-code")
+	if rr.Code != http.StatusFound || rr.Header().Get("Location") != "https://example.com/404" {
+		t.Errorf("missing code returned %v to %q", rr.Code, rr.Header().Get("Location"))
+	}
+
+	rr = doJSON(t, testServer.ShortenHandler, http.MethodPost, "/shorten", map[string]interface{}{
+		"url": "https://example.com", "domain": "unknown.example.com",
+	})
+	if rr.Code != http.StatusBadRequest {
+		t.Errorf("unknown domain returned %v, want %v", rr.Code, http.StatusBadRequest)
+	}
+}
+
+func TestShortURLFollowsRequestHost(t *testing.T) {
+	createDomain(t, map[string]interface{}{"host": "host.example.com"})
+
+	tests := []struct {
+		host string
+		want string
+	}{
+		{"host.example.com", "https://example.com/custom-host"},
+		{"localhost:8080", "https://example.com/default-host"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.host, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/shorten", strings.NewReader(`{"url": "`+tt.want+`"}`))
+			req.Host = tt.host
+			rr := httptest.NewRecorder()
+			testServer.ShortenHandler(rr, req)
+			if rr.Code != http.StatusOK {
+				t.Fatalf("shorten returned %v: %s", rr.Code, rr.Body.String())
+			}
+			var resp models.ShortenResponse
+			json.NewDecoder(rr.Body).Decode(&resp)
+
+			short, err := url.Parse(resp.ShortURL)
+			if err != nil || short.Host != tt.host {
+				t.Fatalf("short_url = %q", resp.ShortURL)
+			}
+			rr = redirectVia(short.Host, short.Path)
+			if rr.Code != http.StatusFound || rr.Header().Get("Location") != tt.want {
+				t.Errorf("following %s returned %v to %q", resp.ShortURL, rr.Code, rr.Header().Get("Location"))
+			}
+		})
+	}
+}
+
+func TestMigrateScopesCodesToDomains(t *testing.T) {
+	database, err := sql.Open("sqlite", "file:migrate_domains.db?mode=memory&cache=shared")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer database.Close()
+
+	_, err = database.Exec(`
+	CREATE TABLE links (
+	  id          INTEGER PRIMARY KEY AUTOINCREMENT,
+	  code        TEXT    NOT NULL UNIQUE,
+	  url         TEXT    NOT NULL,
+	  created_at  TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	  expires_at  TIMESTAMP NULL,
+	  hit_count   INTEGER NOT NULL DEFAULT 0
+	);
+	INSERT INTO links (code, url, hit_count) VALUES ('legacy', 'https://example.com/legacy', 7);
+	`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := db.Migrate(database); err != nil {
+		t.Fatalf("Migrate: %v", err)
+	}
+	if err := db.Migrate(database); err != nil {
+		t.Fatalf("second Migrate: %v", err)
+	}
+
+	var tableSQL string
+	database.QueryRow("SELECT sql FROM sqlite_master WHERE name = 'links'").Scan(&tableSQL)
+	if strings.Contains(strings.ToUpper(tableSQL), "NOT NULL UNIQUE") {
+		t.Errorf("links still has a global unique code: %s", tableSQL)
+	}
+
+	link, err := db.GetLink(database, "legacy")
+	if err != nil || link == nil {
+		t.Fatalf("legacy link lost: %v", err)
+	}
+	if link.HitCount != 7 || link.DomainID != db.DefaultDomainID {
+		t.Errorf("legacy link = %+v", link)
+	}
+
+	if _, err := database.Exec("INSERT INTO links (domain_id, code, url) VALUES (5, 'legacy', 'https://example.com')"); err != nil {
+		t.Errorf("same code in another domain rejected: %v", err)
+	}
+	if _, err := database.Exec("INSERT INTO links (code, url) VALUES ('legacy', 'https://example.com')"); !db.IsUniqueError(err) {
+		t.Errorf("duplicate code in default domain: err = %v", err)
+	}
+}
@@ -0,0 +1,155 @@
+package tests
+
+import (
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"tinyurl/internal/apierror"
+	"tinyurl/internal/logging"
+)
+
+func TestProblemResponses(t *testing.T) {
+	rr := doJSON(t, testServer.ShortenHandler, http.MethodPost, "/shorten", map[string]interface{}{
+		"url": "https://example.com/problem", "alias": "problem-taken",
+	})
+	if rr.Code != http.StatusOK {
+		t.Fatalf("shorten returned %v: %s", rr.Code, rr.Body.String())
+	}
+
+	tests := []struct {
+		name       string
+		handler    http.HandlerFunc
+		method     string
+		target     string
+		body       interface{}
+		wantStatus int
+		wantCode   apierror.Code
+		wantField  string
+		wantAllow  string
+	}{
+		{
+			name:    "Alias taken",
+			handler: testServer.ShortenHandler, method: http.MethodPost, target: "/shorten",
+			body:       map[string]interface{}{"url": "https://example.com/other", "alias": "problem-taken"},
+			wantStatus: http.StatusConflict, wantCode: apierror.AliasTaken, wantField: "alias",
+		},
+		{
+			name:    "Missing URL",
+			handler: testServer.ShortenHandler, method: http.MethodPost, target: "/shorten",
+			body:       map[string]interface{}{},
+			wantStatus: http.StatusBadRequest, wantCode: apierror.URLRequired, wantField: "url",
+		},
+		{
+			name:    "Non-HTTP URL",
+			handler: testServer.ShortenHandler, method: http.MethodPost, target: "/shorten",
+			body:       map[string]interface{}{"url": "ftp://example.com/file"},
+			wantStatus: http.StatusBadRequest, wantCode: apierror.InvalidURL, wantField: "url",
+		},
+		{
+			name:    "Relative rule URL",
+			handler: testServer.ShortenHandler, method: http.MethodPost, target: "/shorten",
+			body: map[string]interface{}{
+				"url":   "https://example.com",
+				"rules": []map[string]string{{"name": "ios", "os": "ios", "url": "/ios"}},
+			},
+			wantStatus: http.StatusBadRequest, wantCode: apierror.InvalidURL, wantField: "rules[0].url",
+		},
+		{
+			name:    "Script variant URL",
+			handler: testServer.ShortenHandler, method: http.MethodPost, target: "/shorten",
+			body: map[string]interface{}{
+				"url":      "https://example.com",
+				"variants": []map[string]interface{}{{"name": "a", "url": "https://example.com/a", "weight": 1}, {"name": "b", "url": "javascript:alert(1)", "weight": 1}},
+			},
+			wantStatus: http.StatusBadRequest, wantCode: apierror.InvalidURL, wantField: "variants[1].url",
+		},
+		{
+			name:    "Non-HTTP geo URL",
+			handler: testServer.ShortenHandler, method: http.MethodPost, target: "/shorten",
+			body:       map[string]interface{}{"url": "https://example.com", "geo": map[string]string{"DE": "ftp://example.de"}},
+			wantStatus: http.StatusBadRequest, wantCode: apierror.InvalidURL, wantField: "geo.DE",
+		},
+		{
+			name:    "Invalid rule",
+			handler: testServer.ShortenHandler, method: http.MethodPost, target: "/shorten",
+			body: map[string]interface{}{
+				"url":   "https://example.com",
+				"rules": []map[string]string{{"name": "ios", "os": "ios", "url": "https://example.com/ios"}, {"name": "bad", "os": "amiga", "url": "https://example.com/a"}},
+			},
+			wantStatus: http.StatusBadRequest, wantCode: apierror.InvalidRules, wantField: "rules[1].os",
+		},
+		{
+			name:    "Method not allowed",
+			handler: testServer.ShortenHandler, method: http.MethodGet, target: "/shorten",
+			wantStatus: http.StatusMethodNotAllowed, wantCode: apierror.MethodNotAllowed, wantAllow: "POST",
+		},
+		{
+			name:    "Link not found",
+			handler: testServer.StatsHandler, method: http.MethodGet, target: "/stats/no-such-link",
+			wantStatus: http.StatusNotFound, wantCode: apierror.LinkNotFound,
+		},
+		{
+			name:    "Incomplete UTM template",
+			handler: testServer.UTMTemplatesHandler, method: http.MethodPost, target: "/utm-templates",
+			body:       map[string]string{"name": "incomplete", "utm_source": "news"},
+			wantStatus: http.StatusBadRequest, wantCode: apierror.InvalidUTMTemplate, wantField: "utm_medium",
+		},
+		{
+			name:    "Unknown route",
+			handler: testServer.NotFoundHandler, method: http.MethodGet, target: "/nowhere",
+			wantStatus: http.StatusNotFound, wantCode: apierror.NotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rr := doJSON(t, tt.handler, tt.method, tt.target, tt.body)
+			if rr.Code != tt.wantStatus {
+				t.Fatalf("status = %v, want %v: %s", rr.Code, tt.wantStatus, rr.Body.String())
+			}
+			if ct := rr.Header().Get("Content-Type"); ct != apierror.ContentType {
+				t.Errorf("Content-Type = %q", ct)
+			}
+			if tt.wantAllow != "" && rr.Header().Get("Allow") != tt.wantAllow {
+				t.Errorf("Allow = %q, want %q", rr.Header().Get("Allow"), tt.wantAllow)
+			}
+
+			var problem apierror.Problem
+			if err := json.NewDecoder(rr.Body).Decode(&problem); err != nil {
+				t.Fatal(err)
+			}
+			if problem.Code != tt.wantCode || problem.Field != tt.wantField || problem.Status != tt.wantStatus {
+				t.Errorf("problem = %+v", problem)
+			}
+			if problem.Type != apierror.TypeURI(tt.wantCode) || problem.Message == "" || problem.Title == "" {
+				t.Errorf("problem = %+v", problem)
+			}
+		})
+	}
+}
+
+func TestProblemIncludesRequestID(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", testServer.NotFoundHandler)
+	handler := logging.Middleware(slog.New(slog.NewTextHandler(io.Discard, nil)), mux, mux)
+
+	req := httptest.NewRequest(http.MethodGet, "/missing", nil)
+	req.Header.Set(logging.RequestIDHeader, "req-problem-1")
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+
+	var problem apierror.Problem
+	if err := json.NewDecoder(rr.Body).Decode(&problem); err != nil {
+		t.Fatal(err)
+	}
+	if problem.RequestID != "req-problem-1" {
+		t.Errorf("request_id = %q", problem.RequestID)
+	}
+	if problem.Instance != "/missing" {
+		t.Errorf("instance = %q", problem.Instance)
+	}
+}
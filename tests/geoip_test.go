@@ -0,0 +1,129 @@
+package tests
+
+import (
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"tinyurl/internal/geoip"
+	"tinyurl/internal/handlers"
+	"tinyurl/internal/models"
+)
+
+func TestGeoIPResolver(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "country.mmdb")
+	if err := writeCountryMMDB(path, map[string]string{
+		"81.2.69.0/24":  "GB",
+		"89.160.0.0/16": "se",
+	}); err != nil {
+		t.Fatalf("Failed to write fixture: %v", err)
+	}
+
+	resolver, err := geoip.Open(path)
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	defer resolver.Close()
+
+	testCases := []struct {
+		ip       string
+		expected string
+	}{
+		{"81.2.69.142", "GB"},
+		{"89.160.20.112", "SE"},
+		{"8.8.8.8", geoip.UnknownCountry},
+	}
+	for _, tc := range testCases {
+		if got := resolver.Country(net.ParseIP(tc.ip)); got != tc.expected {
+			t.Errorf("Country(%s) returned %s, expected %s", tc.ip, got, tc.expected)
+		}
+	}
+
+	if err := writeCountryMMDB(path, map[string]string{"8.8.8.0/24": "US"}); err != nil {
+		t.Fatalf("Failed to rewrite fixture: %v", err)
+	}
+	if err := resolver.Reload(); err != nil {
+		t.Fatalf("Reload failed: %v", err)
+	}
+	if got := resolver.Country(net.ParseIP("8.8.8.8")); got != "US" {
+		t.Errorf("Country after reload returned %s, expected US", got)
+	}
+	if got := resolver.Country(net.ParseIP("81.2.69.142")); got != geoip.UnknownCountry {
+		t.Errorf("Country after reload returned %s, expected %s", got, geoip.UnknownCountry)
+	}
+}
+
+func TestRedirectHandlerGeoTargets(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "country.mmdb")
+	if err := writeCountryMMDB(path, map[string]string{
+		"81.2.69.0/24":  "GB",
+		"89.160.0.0/16": "SE",
+	}); err != nil {
+		t.Fatalf("Failed to write fixture: %v", err)
+	}
+	resolver, err := geoip.Open(path)
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	defer resolver.Close()
+
+	server := handlers.NewServer(testServer.DB)
+	server.GeoIP = resolver
+
+	rr := doJSON(t, server.ShortenHandler, http.MethodPost, "/shorten", map[string]interface{}{
+		"url":   "https://example.com",
+		"alias": "geo_link",
+		"geo":   map[string]string{"gb": "https://example.co.uk"},
+	})
+	if rr.Code != http.StatusOK {
+		t.Fatalf("shorten returned %v: %s", rr.Code, rr.Body.String())
+	}
+
+	testCases := []struct {
+		name        string
+		remoteAddr  string
+		expectedURL string
+	}{
+		{"United Kingdom", "81.2.69.142:5000", "https://example.co.uk"},
+		{"Sweden falls back to default", "89.160.20.112:5000", "https://example.com"},
+		{"Unknown country", "10.0.0.1:5000", "https://example.com"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/r/geo_link", nil)
+			req.RemoteAddr = tc.remoteAddr
+			rr := httptest.NewRecorder()
+			http.HandlerFunc(server.RedirectHandler).ServeHTTP(rr, req)
+
+			if location := rr.Header().Get("Location"); location != tc.expectedURL {
+				t.Errorf("handler returned wrong location: got %v want %v", location, tc.expectedURL)
+			}
+		})
+	}
+
+	var stats models.StatsResponse
+	waitFor(t, func() bool {
+		rr := doJSON(t, server.StatsHandler, http.MethodGet, "/stats/geo_link", nil)
+		if err := json.Unmarshal(rr.Body.Bytes(), &stats); err != nil {
+			return false
+		}
+		countries := stats.Breakdown["country"]
+		return countries["GB"] == 1 && countries["SE"] == 1 && countries[geoip.UnknownCountry] == 1
+	})
+
+	if stats.Geo["GB"] != "https://example.co.uk" {
+		t.Errorf("unexpected geo targets in stats: %v", stats.Geo)
+	}
+
+	rr = doJSON(t, server.ShortenHandler, http.MethodPost, "/shorten", map[string]interface{}{
+		"url": "https://example.com",
+		"geo": map[string]string{"GBR": "https://example.co.uk"},
+	})
+	if rr.Code != http.StatusBadRequest {
+		t.Errorf("invalid country code returned %v, want %v", rr.Code, http.StatusBadRequest)
+	}
+}
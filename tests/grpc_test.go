@@ -0,0 +1,331 @@
+package tests
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"testing"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/proto"
+
+	pb "tinyurl/api/tinyurl/v1"
+	"tinyurl/internal/apierror"
+	"tinyurl/internal/grpcserver"
+	"tinyurl/internal/handlers"
+	"tinyurl/internal/i18n"
+)
+
+// grpcBaseURL - BaseURL сервера в тестах gRPC: без него Shorten для домена по
+// умолчанию отвечает ошибкой.
+const grpcBaseURL = "https://grpc.test"
+
+func newGRPCClient(t *testing.T, baseURL string) pb.TinyURLClient {
+	t.Helper()
+
+	handler := handlers.NewServer(testServer.DB)
+	handler.BaseURL = baseURL
+
+	listener := bufconn.Listen(1 << 20)
+	server := grpcserver.New(handler, slog.New(slog.NewTextHandler(io.Discard, nil)), i18n.Russian)
+	go server.Serve(listener)
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return listener.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	return pb.NewTinyURLClient(conn)
+}
+
+// grpcErrorInfo возвращает код статуса и деталь ErrorInfo ошибки вызова.
+func grpcErrorInfo(t *testing.T, err error) (codes.Code, *errdetails.ErrorInfo, string) {
+	t.Helper()
+
+	st, ok := status.FromError(err)
+	if !ok {
+		t.Fatalf("not a status error: %v", err)
+	}
+	for _, detail := range st.Details() {
+		if info, ok := detail.(*errdetails.ErrorInfo); ok {
+			return st.Code(), info, st.Message()
+		}
+	}
+	t.Fatalf("no ErrorInfo in %v", st)
+	return 0, nil, ""
+}
+
+func TestGRPCLinkLifecycle(t *testing.T) {
+	client := newGRPCClient(t, grpcBaseURL)
+	ctx := context.Background()
+
+	created, err := client.Shorten(ctx, &pb.ShortenRequest{
+		Url: "https://example.com/grpc", Alias: "grpc-link", RedirectType: 301,
+		Variants: []*pb.Variant{{Name: "a", Url: "https://example.com/a", Weight: 1}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if created.GetCode() != "grpc-link" || created.GetShortUrl() != grpcBaseURL+"/r/grpc-link" {
+		t.Errorf("shorten = %v", created)
+	}
+
+	resolved, err := client.Resolve(ctx, &pb.ResolveRequest{Code: "grpc-link"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resolved.GetUrl() != "https://example.com/grpc" || resolved.GetRedirectType() != 301 {
+		t.Errorf("resolve = %v", resolved)
+	}
+
+	stats, err := client.GetStats(ctx, &pb.GetStatsRequest{Code: "grpc-link"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stats.GetLink().GetHitCount() != 0 {
+		t.Errorf("resolve counted a hit: %v", stats.GetLink())
+	}
+	if len(stats.GetLink().GetVariants()) != 1 || stats.GetLink().GetCreatedAt() == nil || stats.GetLink().GetExpiresAt() != nil {
+		t.Errorf("stats link = %v", stats.GetLink())
+	}
+
+	updated, err := client.Update(ctx, &pb.UpdateRequest{
+		Code: "grpc-link", Url: proto.String("https://example.com/updated"), TtlDays: proto.Int32(7), RedirectType: proto.Int32(0),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if updated.GetUrl() != "https://example.com/updated" || updated.GetExpiresAt() == nil || updated.GetRedirectType() != http.StatusFound {
+		t.Errorf("update = %v", updated)
+	}
+
+	resolved, err = client.Resolve(ctx, &pb.ResolveRequest{Code: "grpc-link"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resolved.GetUrl() != "https://example.com/updated" || resolved.GetRedirectType() != http.StatusFound {
+		t.Errorf("resolve after update = %v", resolved)
+	}
+
+	if _, err := client.Delete(ctx, &pb.DeleteRequest{Code: "grpc-link"}); err != nil {
+		t.Fatal(err)
+	}
+	_, err = client.GetStats(ctx, &pb.GetStatsRequest{Code: "grpc-link"})
+	if code, info, _ := grpcErrorInfo(t, err); code != codes.NotFound || info.GetReason() != string(apierror.LinkNotFound) {
+		t.Errorf("stats after delete: %v %v", code, info)
+	}
+}
+
+func TestGRPCShortenWithoutBaseURL(t *testing.T) {
+	client := newGRPCClient(t, "")
+	ctx := context.Background()
+
+	_, err := client.Shorten(ctx, &pb.ShortenRequest{Url: "https://example.com", Alias: "grpc-no-base"})
+	if code, info, _ := grpcErrorInfo(t, err); code != codes.Internal || info.GetReason() != string(apierror.BaseURLRequired) {
+		t.Errorf("shorten without base url: %v %v", code, info)
+	}
+	_, err = client.Resolve(ctx, &pb.ResolveRequest{Code: "grpc-no-base"})
+	if code, _, _ := grpcErrorInfo(t, err); code != codes.NotFound {
+		t.Errorf("link was created: %v", code)
+	}
+
+	// Адрес собственного домена строится по его хосту и без BaseURL.
+	rr := doJSON(t, testServer.DomainsHandler, http.MethodPost, "/domains", map[string]interface{}{"host": "grpc-no-base.example"})
+	if rr.Code != http.StatusCreated {
+		t.Fatalf("create domain returned %v", rr.Code)
+	}
+	created, err := client.Shorten(ctx, &pb.ShortenRequest{Url: "https://example.com", Alias: "grpc-no-base", Domain: "grpc-no-base.example"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if created.GetShortUrl() != "https://grpc-no-base.example/r/grpc-no-base" {
+		t.Errorf("short url = %q", created.GetShortUrl())
+	}
+}
+
+func TestGRPCList(t *testing.T) {
+	client := newGRPCClient(t, grpcBaseURL)
+	ctx := context.Background()
+
+	if _, err := client.Shorten(ctx, &pb.ShortenRequest{Url: "https://example.com", Alias: "grpc-list-domain"}); err != nil {
+		t.Fatal(err)
+	}
+	rr := doJSON(t, testServer.DomainsHandler, http.MethodPost, "/domains", map[string]interface{}{"host": "grpc.example", "redirect_type": 301})
+	if rr.Code != http.StatusCreated {
+		t.Fatalf("create domain returned %v", rr.Code)
+	}
+	redirectTypes := map[string]int32{"g1": 301, "g2": 308, "g3": 301}
+	for _, alias := range []string{"g1", "g2", "g3"} {
+		var own int32
+		if alias == "g2" {
+			own = 308
+		}
+		if _, err := client.Shorten(ctx, &pb.ShortenRequest{Url: "https://example.com/" + alias, Alias: alias, Domain: "grpc.example", RedirectType: own}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var listed []string
+	token := ""
+	for pages := 0; ; pages++ {
+		if pages > 3 {
+			t.Fatal("too many pages")
+		}
+		resp, err := client.List(ctx, &pb.ListRequest{Domain: "grpc.example", PageSize: 2, PageToken: token})
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, link := range resp.GetLinks() {
+			if link.GetDomain() != "grpc.example" {
+				t.Errorf("link %s has domain %q", link.GetCode(), link.GetDomain())
+			}
+			if got, want := link.GetRedirectType(), redirectTypes[link.GetCode()]; got != want {
+				t.Errorf("link %s has redirect_type %d, want %d", link.GetCode(), got, want)
+			}
+			listed = append(listed, link.GetCode())
+		}
+		if token = resp.GetNextPageToken(); token == "" {
+			break
+		}
+	}
+	if len(listed) != 3 || listed[0] != "g1" || listed[2] != "g3" {
+		t.Errorf("listed %v, want [g1 g2 g3]", listed)
+	}
+
+	resp, err := client.List(ctx, &pb.ListRequest{Domain: "grpc.example", Search: "g2"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(resp.GetLinks()) != 1 || resp.GetLinks()[0].GetCode() != "g2" {
+		t.Errorf("search returned %v", resp.GetLinks())
+	}
+}
+
+func TestGRPCErrors(t *testing.T) {
+	client := newGRPCClient(t, grpcBaseURL)
+
+	if _, err := client.Shorten(context.Background(), &pb.ShortenRequest{Url: "https://example.com", Alias: "grpc-taken"}); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name      string
+		call      func(ctx context.Context) error
+		wantCode  codes.Code
+		wantError apierror.Code
+		wantField string
+	}{
+		{
+			name: "Missing URL",
+			call: func(ctx context.Context) error {
+				_, err := client.Shorten(ctx, &pb.ShortenRequest{})
+				return err
+			},
+			wantCode: codes.InvalidArgument, wantError: apierror.URLRequired, wantField: "url",
+		},
+		{
+			name: "Alias taken",
+			call: func(ctx context.Context) error {
+				_, err := client.Shorten(ctx, &pb.ShortenRequest{Url: "https://example.com/other", Alias: "grpc-taken"})
+				return err
+			},
+			wantCode: codes.AlreadyExists, wantError: apierror.AliasTaken, wantField: "alias",
+		},
+		{
+			name: "Invalid rule",
+			call: func(ctx context.Context) error {
+				_, err := client.Shorten(ctx, &pb.ShortenRequest{Url: "https://example.com", Rules: []*pb.TargetRule{{Name: "x", Os: "amiga", Url: "https://example.com/x"}}})
+				return err
+			},
+			wantCode: codes.InvalidArgument, wantError: apierror.InvalidRules, wantField: "rules[0].os",
+		},
+		{
+			name: "Unknown domain",
+			call: func(ctx context.Context) error {
+				_, err := client.List(ctx, &pb.ListRequest{Domain: "missing.example"})
+				return err
+			},
+			wantCode: codes.NotFound, wantError: apierror.DomainNotFound,
+		},
+		{
+			name: "Unknown link",
+			call: func(ctx context.Context) error {
+				_, err := client.Resolve(ctx, &pb.ResolveRequest{Code: "grpc-missing"})
+				return err
+			},
+			wantCode: codes.NotFound, wantError: apierror.LinkNotFound,
+		},
+		{
+			name: "Negative TTL",
+			call: func(ctx context.Context) error {
+				_, err := client.Update(ctx, &pb.UpdateRequest{Code: "grpc-taken", TtlDays: proto.Int32(-1)})
+				return err
+			},
+			wantCode: codes.InvalidArgument, wantError: apierror.InvalidTTL, wantField: "ttl_days",
+		},
+		{
+			name: "Empty URL on update",
+			call: func(ctx context.Context) error {
+				_, err := client.Update(ctx, &pb.UpdateRequest{Code: "grpc-taken", Url: proto.String("")})
+				return err
+			},
+			wantCode: codes.InvalidArgument, wantError: apierror.URLRequired, wantField: "url",
+		},
+		{
+			name: "Negative page size",
+			call: func(ctx context.Context) error {
+				_, err := client.List(ctx, &pb.ListRequest{PageSize: -1})
+				return err
+			},
+			wantCode: codes.InvalidArgument, wantError: apierror.InvalidPageSize, wantField: "page_size",
+		},
+		{
+			name: "Invalid page token",
+			call: func(ctx context.Context) error {
+				_, err := client.List(ctx, &pb.ListRequest{PageToken: "abc"})
+				return err
+			},
+			wantCode: codes.InvalidArgument, wantError: apierror.InvalidPageToken, wantField: "page_token",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			code, info, _ := grpcErrorInfo(t, tt.call(context.Background()))
+			if code != tt.wantCode {
+				t.Errorf("code = %v, want %v", code, tt.wantCode)
+			}
+			if info.GetReason() != string(tt.wantError) || info.GetDomain() != grpcserver.ErrorDomain {
+				t.Errorf("error info = %v, want reason %s", info, tt.wantError)
+			}
+			if info.GetMetadata()["field"] != tt.wantField {
+				t.Errorf("field = %q, want %q", info.GetMetadata()["field"], tt.wantField)
+			}
+		})
+	}
+
+	t.Run("Localized message", func(t *testing.T) {
+		ctx := metadata.AppendToOutgoingContext(context.Background(), "accept-language", "en")
+		_, err := client.Resolve(ctx, &pb.ResolveRequest{Code: "grpc-missing"})
+		if _, _, message := grpcErrorInfo(t, err); message != i18n.T(i18n.English, i18n.ErrLinkNotFound) {
+			t.Errorf("message = %q", message)
+		}
+
+		_, err = client.Resolve(context.Background(), &pb.ResolveRequest{Code: "grpc-missing"})
+		if _, _, message := grpcErrorInfo(t, err); message != i18n.T(i18n.Russian, i18n.ErrLinkNotFound) {
+			t.Errorf("default message = %q", message)
+		}
+	})
+}
@@ -2,12 +2,14 @@ package tests
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
 	"os"
 	"testing"
 
+	"tinyurl/internal/apierror"
 	"tinyurl/internal/db"
 	"tinyurl/internal/handlers"
 	"tinyurl/internal/models"
@@ -28,6 +30,7 @@ func TestMain(m *testing.M) {
 	exitCode := m.Run()
 
 	database.Close()
+	removeCLI()
 
 	os.Exit(exitCode)
 }
@@ -233,3 +236,149 @@ func TestStatsHandler(t *testing.T) {
 		})
 	}
 }
+
+func TestRedirectHandlerRedirectType(t *testing.T) {
+	links := []struct {
+		code         string
+		redirectType int
+	}{
+		{"rt_default", 0},
+		{"rt_301", http.StatusMovedPermanently},
+		{"rt_307", http.StatusTemporaryRedirect},
+		{"rt_308", http.StatusPermanentRedirect},
+	}
+	for _, l := range links {
+		opts := models.LinkOptions{RedirectType: l.redirectType}
+		if err := db.InsertLinkWithOptions(context.Background(), testServer.DB, l.code, "https://example.com", 0, opts); err != nil {
+			t.Fatalf("Failed to create test link: %v", err)
+		}
+	}
+
+	testCases := []struct {
+		name           string
+		code           string
+		expectedStatus int
+		expectedCache  string
+	}{
+		{"Server default", "rt_default", http.StatusFound, "private, no-store"},
+		{"Moved permanently", "rt_301", http.StatusMovedPermanently, "private, max-age=0, must-revalidate"},
+		{"Temporary redirect", "rt_307", http.StatusTemporaryRedirect, "private, no-store"},
+		{"Permanent redirect", "rt_308", http.StatusPermanentRedirect, "private, max-age=0, must-revalidate"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			req, err := http.NewRequest(http.MethodGet, "/r/"+tc.code, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			rr := httptest.NewRecorder()
+			handler := http.HandlerFunc(testServer.RedirectHandler)
+			handler.ServeHTTP(rr, req)
+
+			if status := rr.Code; status != tc.expectedStatus {
+				t.Errorf("handler returned wrong status code: got %v want %v",
+					status, tc.expectedStatus)
+			}
+			if cache := rr.Header().Get("Cache-Control"); cache != tc.expectedCache {
+				t.Errorf("handler returned wrong Cache-Control: got %v want %v",
+					cache, tc.expectedCache)
+			}
+		})
+	}
+}
+
+func TestShortenHandlerInvalidRedirectType(t *testing.T) {
+	jsonBody, _ := json.Marshal(map[string]interface{}{"url": "https://example.com", "redirect_type": 303})
+	req, err := http.NewRequest(http.MethodPost, "/shorten", bytes.NewReader(jsonBody))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rr := httptest.NewRecorder()
+	http.HandlerFunc(testServer.ShortenHandler).ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusBadRequest {
+		t.Errorf("handler returned wrong status code: got %v want %v", rr.Code, http.StatusBadRequest)
+	}
+}
+
+// Алиас целиком становится кодом в /r/{code}, поэтому символы, которые разбили бы
+// путь, отклоняются при создании.
+func TestShortenHandlerInvalidAlias(t *testing.T) {
+	testCases := []struct {
+		name  string
+		alias string
+	}{
+		{"Slash", "alias/tail"},
+		{"Query", "alias?x=1"},
+		{"Fragment", "alias#top"},
+		{"Percent", "alias%2F"},
+		{"Backslash", `alias\tail`},
+		{"Space", "my alias"},
+		{"Dot", "."},
+		{"Dot dot", ".."},
+		{"Preview suffix", "alias+"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			rr := doJSON(t, testServer.ShortenHandler, http.MethodPost, "/shorten",
+				map[string]interface{}{"url": "https://example.com", "alias": tc.alias})
+			if rr.Code != http.StatusBadRequest || problemCode(t, rr) != apierror.InvalidAlias {
+				t.Errorf("shorten %q returned %v: %s", tc.alias, rr.Code, rr.Body.String())
+			}
+		})
+	}
+
+	rr := doJSON(t, testServer.ShortenHandler, http.MethodPost, "/shorten",
+		map[string]interface{}{"url": "https://example.com/dotted", "alias": "v1+2.beta_x-~y"})
+	if rr.Code != http.StatusOK {
+		t.Fatalf("shorten returned %v: %s", rr.Code, rr.Body.String())
+	}
+	rr = doJSON(t, testServer.RedirectHandler, http.MethodGet, "/r/v1+2.beta_x-~y", nil)
+	if location := rr.Header().Get("Location"); location != "https://example.com/dotted" {
+		t.Errorf("redirect returned %v to %q", rr.Code, location)
+	}
+}
+
+func TestRedirectHandlerPassthrough(t *testing.T) {
+	if err := db.InsertLinkWithOptions(context.Background(), testServer.DB, "pt_on", "https://example.com/landing?ref=short", 0,
+		models.LinkOptions{Passthrough: true}); err != nil {
+		t.Fatalf("Failed to create test link: %v", err)
+	}
+	if err := db.InsertLink(testServer.DB, "pt_off", "https://example.com/landing", 0); err != nil {
+		t.Fatalf("Failed to create test link: %v", err)
+	}
+
+	testCases := []struct {
+		name           string
+		target         string
+		expectedStatus int
+		expectedURL    string
+	}{
+		{"Query appended", "/r/pt_on?utm_source=x", http.StatusFound, "https://example.com/landing?ref=short&utm_source=x"},
+		{"Duplicate param ignored", "/r/pt_on?ref=other", http.StatusFound, "https://example.com/landing?ref=short"},
+		{"Path appended", "/r/pt_on/extra/path", http.StatusFound, "https://example.com/landing/extra/path?ref=short"},
+		{"Disabled drops query", "/r/pt_off?utm_source=x", http.StatusFound, "https://example.com/landing"},
+		{"Disabled rejects path", "/r/pt_off/extra", http.StatusNotFound, ""},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, tc.target, nil)
+			rr := httptest.NewRecorder()
+			http.HandlerFunc(testServer.RedirectHandler).ServeHTTP(rr, req)
+
+			if rr.Code != tc.expectedStatus {
+				t.Errorf("handler returned wrong status code: got %v want %v", rr.Code, tc.expectedStatus)
+			}
+			if tc.expectedURL != "" {
+				if location := rr.Header().Get("Location"); location != tc.expectedURL {
+					t.Errorf("handler returned wrong location: got %v want %v", location, tc.expectedURL)
+				}
+			}
+		})
+	}
+}
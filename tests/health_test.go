@@ -0,0 +1,191 @@
+package tests
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"tinyurl/internal/db"
+	"tinyurl/internal/handlers"
+	"tinyurl/internal/i18n"
+	"tinyurl/internal/version"
+)
+
+func TestHealthz(t *testing.T) {
+	rr := doJSON(t, testServer.HealthHandler, http.MethodGet, "/healthz", nil)
+	if rr.Code != http.StatusOK {
+		t.Errorf("healthz returned %v", rr.Code)
+	}
+}
+
+func TestReadyz(t *testing.T) {
+	unmigrated, err := sql.Open("sqlite", "file:unmigrated.db?mode=memory&cache=shared")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer unmigrated.Close()
+	if _, err := unmigrated.Exec(`CREATE TABLE links (
+		id INTEGER PRIMARY KEY AUTOINCREMENT, code TEXT NOT NULL UNIQUE, url TEXT NOT NULL,
+		created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP, expires_at TIMESTAMP NULL, hit_count INTEGER NOT NULL DEFAULT 0)`); err != nil {
+		t.Fatal(err)
+	}
+
+	closed, err := sql.Open("sqlite", "file:closed-ready.db?mode=memory")
+	if err != nil {
+		t.Fatal(err)
+	}
+	closed.Close()
+
+	stopping := handlers.NewServer(testServer.DB)
+	stopping.BeginShutdown()
+
+	pendingServer := handlers.NewServer(unmigrated)
+
+	tests := []struct {
+		name       string
+		server     *handlers.Server
+		wantStatus int
+		failed     string
+		// wantResult - итог проваленной проверки; причина сбоя пробам не отдается.
+		wantResult string
+	}{
+		{"Ready", testServer, http.StatusOK, "", ""},
+		{"Shutting down", stopping, http.StatusServiceUnavailable, "shutdown", ""},
+		{"Database closed", handlers.NewServer(closed), http.StatusServiceUnavailable, "database", i18n.T(i18n.Default, i18n.ReadyCheckFailed)},
+		{"Migrations pending", pendingServer, http.StatusServiceUnavailable, "migrations", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rr := doJSON(t, tt.server.ReadyHandler, http.MethodGet, "/readyz", nil)
+			if rr.Code != tt.wantStatus {
+				t.Fatalf("readyz returned %v, want %v: %s", rr.Code, tt.wantStatus, rr.Body.String())
+			}
+
+			var resp struct {
+				Status string            `json:"status"`
+				Checks map[string]string `json:"checks"`
+			}
+			if err := json.NewDecoder(rr.Body).Decode(&resp); err != nil {
+				t.Fatal(err)
+			}
+			for check, result := range resp.Checks {
+				if check == tt.failed {
+					if result == "ok" {
+						t.Errorf("check %s = ok, want failure", check)
+					}
+					if tt.wantResult != "" && result != tt.wantResult {
+						t.Errorf("check %s = %q, want %q", check, result, tt.wantResult)
+					}
+				} else if tt.failed == "" && result != "ok" {
+					t.Errorf("check %s = %q", check, result)
+				}
+			}
+		})
+	}
+
+	// Непримененные миграции не запоминаются: после Migrate проба снова проверяет схему.
+	if err := db.Migrate(unmigrated); err != nil {
+		t.Fatal(err)
+	}
+	if rr := doJSON(t, pendingServer.ReadyHandler, http.MethodGet, "/readyz", nil); rr.Code != http.StatusOK {
+		t.Errorf("readyz after Migrate returned %v: %s", rr.Code, rr.Body.String())
+	}
+}
+
+func TestPendingMigrations(t *testing.T) {
+	pending, err := db.PendingMigrations(context.Background(), testServer.DB)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pending) != 0 {
+		t.Errorf("migrated database has pending migrations: %v", pending)
+	}
+
+	bare, err := sql.Open("sqlite", "file:bare.db?mode=memory&cache=shared")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer bare.Close()
+	if _, err := bare.Exec(`CREATE TABLE links (id INTEGER PRIMARY KEY, code TEXT NOT NULL UNIQUE, url TEXT NOT NULL)`); err != nil {
+		t.Fatal(err)
+	}
+
+	pending, err = db.PendingMigrations(context.Background(), bare)
+	if err != nil {
+		t.Fatal(err)
+	}
+	joined := strings.Join(pending, ",")
+	for _, want := range []string{"domains", "link_stats", "links.domain_id", "idx_links_domain_code"} {
+		if !strings.Contains(joined, want) {
+			t.Errorf("pending migrations %v do not include %s", pending, want)
+		}
+	}
+}
+
+func TestVersion(t *testing.T) {
+	rr := doJSON(t, testServer.VersionHandler, http.MethodGet, "/version", nil)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("version returned %v", rr.Code)
+	}
+
+	var info version.Info
+	if err := json.NewDecoder(rr.Body).Decode(&info); err != nil {
+		t.Fatal(err)
+	}
+	if info.GoVersion != runtime.Version() || info.Version == "" {
+		t.Errorf("version = %+v", info)
+	}
+}
+
+// После WaitHits все переходы уже записаны: при остановке база закрывается
+// только после него.
+func TestWaitHits(t *testing.T) {
+	if err := db.InsertLink(testServer.DB, "wait_hits", "https://example.com/wait", 0); err != nil {
+		t.Fatal(err)
+	}
+	for range 5 {
+		rr := doJSON(t, testServer.RedirectHandler, http.MethodGet, "/r/wait_hits", nil)
+		if rr.Code != http.StatusFound {
+			t.Fatalf("redirect returned %v", rr.Code)
+		}
+	}
+	testServer.WaitHits()
+
+	link, err := db.GetLink(testServer.DB, "wait_hits")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if link.HitCount != 5 {
+		t.Errorf("hit_count = %d after WaitHits, want 5", link.HitCount)
+	}
+}
+
+func TestDrain(t *testing.T) {
+	server := handlers.NewServer(testServer.DB)
+	done := make(chan struct{})
+	go func() {
+		server.Drain(200 * time.Millisecond)
+		close(done)
+	}()
+
+	waitFor(t, func() bool {
+		rr := doJSON(t, server.ReadyHandler, http.MethodGet, "/readyz", nil)
+		return rr.Code == http.StatusServiceUnavailable
+	})
+	select {
+	case <-done:
+		t.Fatal("Drain returned before /readyz reported 503")
+	default:
+	}
+
+	<-done
+	if rr := doJSON(t, server.ReadyHandler, http.MethodGet, "/readyz", nil); rr.Code != http.StatusServiceUnavailable {
+		t.Errorf("readyz after drain returned %v", rr.Code)
+	}
+}
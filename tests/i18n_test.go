@@ -0,0 +1,209 @@
+package tests
+
+import (
+	"encoding/json"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+
+	"tinyurl/internal/apierror"
+	"tinyurl/internal/i18n"
+)
+
+// declaredKeys читает константы Key из исходника каталога, чтобы новый ключ без
+// перевода не прошел незамеченным.
+func declaredKeys(t *testing.T) []i18n.Key {
+	t.Helper()
+
+	file, err := parser.ParseFile(token.NewFileSet(), "../internal/i18n/keys.go", nil, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var keys []i18n.Key
+	for _, decl := range file.Decls {
+		gen, ok := decl.(*ast.GenDecl)
+		if !ok || gen.Tok != token.CONST {
+			continue
+		}
+		for _, spec := range gen.Specs {
+			for _, value := range spec.(*ast.ValueSpec).Values {
+				lit, ok := value.(*ast.BasicLit)
+				if !ok || lit.Kind != token.STRING {
+					continue
+				}
+				key, err := strconv.Unquote(lit.Value)
+				if err != nil {
+					t.Fatal(err)
+				}
+				keys = append(keys, i18n.Key(key))
+			}
+		}
+	}
+	return keys
+}
+
+var verbPattern = regexp.MustCompile(`%[-+# 0]*[0-9]*[a-zA-Z]`)
+
+func TestCatalogComplete(t *testing.T) {
+	keys := declaredKeys(t)
+	if len(keys) == 0 {
+		t.Fatal("no keys declared")
+	}
+
+	declared := make(map[i18n.Key]bool, len(keys))
+	for _, key := range keys {
+		declared[key] = true
+	}
+
+	reference := i18n.Catalog(i18n.Default)
+	for _, lang := range i18n.Languages() {
+		catalog := i18n.Catalog(lang)
+		for _, key := range keys {
+			msg, ok := catalog[key]
+			if !ok || msg == "" {
+				t.Errorf("%s: missing translation for %s", lang, key)
+				continue
+			}
+			want := strings.Join(verbPattern.FindAllString(reference[key], -1), " ")
+			if got := strings.Join(verbPattern.FindAllString(msg, -1), " "); got != want {
+				t.Errorf("%s: %s has format verbs %q, want %q", lang, key, got, want)
+			}
+		}
+		for key := range catalog {
+			if !declared[key] {
+				t.Errorf("%s: %s is not declared in keys.go", lang, key)
+			}
+		}
+	}
+}
+
+func TestNegotiate(t *testing.T) {
+	tests := []struct {
+		header string
+		want   i18n.Lang
+	}{
+		{"", i18n.Russian},
+		{"en", i18n.English},
+		{"en-US,en;q=0.9", i18n.English},
+		{"de-DE,de;q=0.9,en;q=0.8", i18n.English},
+		{"en;q=0.5,ru;q=0.8", i18n.Russian},
+		{"ru;q=0,en", i18n.English},
+		{"fr, *;q=0.1", i18n.Russian},
+		{"de", i18n.Russian},
+		{"en;q=abc", i18n.Russian},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.header, func(t *testing.T) {
+			if got := i18n.Negotiate(tt.header, i18n.Russian); got != tt.want {
+				t.Errorf("Negotiate(%q) = %s, want %s", tt.header, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseLang(t *testing.T) {
+	tests := []struct {
+		input  string
+		want   i18n.Lang
+		wantOK bool
+	}{
+		{"en", i18n.English, true},
+		{"EN-gb", i18n.English, true},
+		{"ru_RU.UTF-8", i18n.Russian, true},
+		{"en_US@euro", i18n.English, true},
+		{"C", "", false},
+		{"de_DE", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, ok := i18n.Parse(tt.input)
+			if ok != tt.wantOK || (ok && got != tt.want) {
+				t.Errorf("Parse(%q) = %s, %v", tt.input, got, ok)
+			}
+		})
+	}
+}
+
+func TestFromEnv(t *testing.T) {
+	t.Setenv("LC_ALL", "")
+	t.Setenv("LC_MESSAGES", "")
+	t.Setenv("LANG", "en_US.UTF-8")
+	if got := i18n.FromEnv(i18n.Russian); got != i18n.English {
+		t.Errorf("LANG=en_US.UTF-8: got %s", got)
+	}
+
+	t.Setenv("LC_ALL", "C")
+	if got := i18n.FromEnv(i18n.Russian); got != i18n.Russian {
+		t.Errorf("LC_ALL=C: got %s", got)
+	}
+}
+
+func TestLocalizedResponses(t *testing.T) {
+	rr := doJSON(t, testServer.ShortenHandler, http.MethodPost, "/shorten", map[string]interface{}{
+		"url": "https://example.com/localized", "alias": "localized-link",
+	})
+	if rr.Code != http.StatusOK {
+		t.Fatalf("shorten returned %v: %s", rr.Code, rr.Body.String())
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/shorten", testServer.ShortenHandler)
+	mux.HandleFunc("/r/", testServer.RedirectHandler)
+	handler := i18n.Middleware(i18n.Russian, mux)
+
+	tests := []struct {
+		name           string
+		method         string
+		target         string
+		acceptLanguage string
+		wantLang       i18n.Lang
+		wantText       string
+	}{
+		{"Error in English", http.MethodGet, "/shorten", "en-US,en;q=0.9", i18n.English, "Allowed methods: POST"},
+		{"Error in Russian", http.MethodGet, "/shorten", "ru", i18n.Russian, "Разрешены только методы POST"},
+		{"Error default", http.MethodGet, "/shorten", "", i18n.Russian, "Разрешены только методы POST"},
+		{"Preview in English", http.MethodGet, "/r/localized-link+", "en", i18n.English, "Continue"},
+		{"Preview in Russian", http.MethodGet, "/r/localized-link+", "ru", i18n.Russian, "Перейти"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.target, nil)
+			if tt.acceptLanguage != "" {
+				req.Header.Set("Accept-Language", tt.acceptLanguage)
+			}
+			rr := httptest.NewRecorder()
+			handler.ServeHTTP(rr, req)
+
+			if got := rr.Header().Get("Content-Language"); got != string(tt.wantLang) {
+				t.Errorf("Content-Language = %q, want %q", got, tt.wantLang)
+			}
+			if !strings.Contains(rr.Header().Get("Vary"), "Accept-Language") {
+				t.Errorf("Vary = %q", rr.Header().Get("Vary"))
+			}
+
+			body := rr.Body.String()
+			if rr.Header().Get("Content-Type") == apierror.ContentType {
+				var problem apierror.Problem
+				if err := json.Unmarshal(rr.Body.Bytes(), &problem); err != nil {
+					t.Fatal(err)
+				}
+				body = problem.Message
+			} else if !strings.Contains(body, `lang="`+string(tt.wantLang)+`"`) {
+				t.Errorf("page is not marked as %s", tt.wantLang)
+			}
+			if !strings.Contains(body, tt.wantText) {
+				t.Errorf("response does not contain %q: %s", tt.wantText, body)
+			}
+		})
+	}
+}
@@ -0,0 +1,342 @@
+package tests
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"tinyurl/internal/apierror"
+	"tinyurl/internal/db"
+	"tinyurl/internal/handlers"
+	"tinyurl/internal/models"
+	"tinyurl/pkg/client"
+)
+
+// postWithKey отправляет POST с заголовком Idempotency-Key; пустой key - без
+// заголовка.
+func postWithKey(t *testing.T, handler http.HandlerFunc, target, key string, body interface{}) *httptest.ResponseRecorder {
+	t.Helper()
+
+	data, _ := json.Marshal(body)
+	req := httptest.NewRequest(http.MethodPost, target, bytes.NewReader(data))
+	req.Header.Set("Content-Type", "application/json")
+	if key != "" {
+		req.Header.Set(handlers.IdempotencyKeyHeader, key)
+	}
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+	return rr
+}
+
+func problemCode(t *testing.T, rr *httptest.ResponseRecorder) apierror.Code {
+	t.Helper()
+
+	var problem apierror.Problem
+	if err := json.Unmarshal(rr.Body.Bytes(), &problem); err != nil {
+		t.Fatalf("decode problem %q: %v", rr.Body.String(), err)
+	}
+	return problem.Code
+}
+
+func TestIdempotentCreate(t *testing.T) {
+	mux := newAPIMux()
+	legacy := testServer.Idempotent(testServer.ShortenHandler)
+	link := map[string]interface{}{"url": "https://example.com/idempotent"}
+
+	tests := []struct {
+		name       string
+		handler    http.HandlerFunc
+		target     string
+		firstKey   string
+		secondKey  string
+		first      interface{}
+		second     interface{}
+		wantStatus int
+		wantCode   apierror.Code
+		wantSame   bool
+	}{
+		{
+			name: "Replay returns the original link", handler: mux.ServeHTTP, target: handlers.APIPrefix + "/links",
+			firstKey: "idem-replay", secondKey: "idem-replay", first: link, second: link,
+			wantStatus: http.StatusOK, wantSame: true,
+		},
+		{
+			name: "Legacy /shorten replays too", handler: legacy, target: "/shorten",
+			firstKey: "idem-legacy", secondKey: "idem-legacy", first: link, second: link,
+			wantStatus: http.StatusOK, wantSame: true,
+		},
+		{
+			name: "Without key each request creates a link", handler: mux.ServeHTTP, target: handlers.APIPrefix + "/links",
+			first: link, second: link, wantStatus: http.StatusOK,
+		},
+		{
+			name: "Different keys create different links", handler: mux.ServeHTTP, target: handlers.APIPrefix + "/links",
+			firstKey: "idem-first", secondKey: "idem-second", first: link, second: link, wantStatus: http.StatusOK,
+		},
+		{
+			name: "Key reuse with another body", handler: mux.ServeHTTP, target: handlers.APIPrefix + "/links",
+			firstKey: "idem-reused", secondKey: "idem-reused",
+			first: link, second: map[string]interface{}{"url": "https://example.com/other"},
+			wantStatus: http.StatusUnprocessableEntity, wantCode: apierror.IdempotencyKeyReused,
+		},
+		{
+			name: "Client errors are replayed", handler: mux.ServeHTTP, target: handlers.APIPrefix + "/links",
+			firstKey: "idem-invalid", secondKey: "idem-invalid",
+			first: map[string]interface{}{"url": ""}, second: map[string]interface{}{"url": ""},
+			wantStatus: http.StatusBadRequest, wantCode: apierror.URLRequired,
+		},
+		{
+			name: "Domain creation replays", handler: mux.ServeHTTP, target: handlers.APIPrefix + "/domains",
+			firstKey: "idem-domain", secondKey: "idem-domain",
+			first: map[string]interface{}{"host": "idem.example"}, second: map[string]interface{}{"host": "idem.example"},
+			wantStatus: http.StatusCreated, wantSame: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			first := postWithKey(t, tt.handler, tt.target, tt.firstKey, tt.first)
+			second := postWithKey(t, tt.handler, tt.target, tt.secondKey, tt.second)
+
+			if second.Code != tt.wantStatus {
+				t.Fatalf("second status = %v, want %v: %s", second.Code, tt.wantStatus, second.Body.String())
+			}
+			if tt.wantCode != "" {
+				if code := problemCode(t, second); code != tt.wantCode {
+					t.Errorf("code = %q, want %q", code, tt.wantCode)
+				}
+				return
+			}
+
+			same := bytes.Equal(first.Body.Bytes(), second.Body.Bytes())
+			if same != tt.wantSame {
+				t.Errorf("same response = %v, want %v:\n%s\n%s", same, tt.wantSame, first.Body.String(), second.Body.String())
+			}
+			replayed := second.Header().Get(handlers.IdempotentReplayedHeader) == "true"
+			if replayed != tt.wantSame {
+				t.Errorf("%s = %v, want %v", handlers.IdempotentReplayedHeader, replayed, tt.wantSame)
+			}
+			if tt.wantSame && second.Header().Get("Content-Type") != first.Header().Get("Content-Type") {
+				t.Errorf("Content-Type = %q, want %q", second.Header().Get("Content-Type"), first.Header().Get("Content-Type"))
+			}
+		})
+	}
+}
+
+func TestIdempotencyKeyValidation(t *testing.T) {
+	mux := newAPIMux()
+	link := map[string]interface{}{"url": "https://example.com/key"}
+
+	tests := []struct {
+		name string
+		key  string
+		want int
+	}{
+		{name: "Printable ASCII", key: "job-42:attempt", want: http.StatusOK},
+		{name: "Maximum length", key: strings.Repeat("k", 255), want: http.StatusOK},
+		{name: "Too long", key: strings.Repeat("k", 256), want: http.StatusBadRequest},
+		{name: "Non-ASCII", key: "ключ", want: http.StatusBadRequest},
+		{name: "Control character", key: "key\x01", want: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rr := postWithKey(t, mux.ServeHTTP, handlers.APIPrefix+"/links", tt.key, link)
+			if rr.Code != tt.want {
+				t.Fatalf("status = %v, want %v: %s", rr.Code, tt.want, rr.Body.String())
+			}
+			if tt.want == http.StatusBadRequest {
+				if code := problemCode(t, rr); code != apierror.InvalidIdempotencyKey {
+					t.Errorf("code = %q", code)
+				}
+			}
+		})
+	}
+
+	req := httptest.NewRequest(http.MethodPost, handlers.APIPrefix+"/links", strings.NewReader(`{"url":"https://example.com"}`))
+	req.Header.Set(handlers.IdempotencyKeyHeader, "")
+	rr := httptest.NewRecorder()
+	mux.ServeHTTP(rr, req)
+	if rr.Code != http.StatusBadRequest {
+		t.Errorf("empty key status = %v", rr.Code)
+	}
+}
+
+func TestIdempotencyServerErrors(t *testing.T) {
+	var calls int
+	handler := testServer.Idempotent(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if calls == 1 {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.WriteHeader(http.StatusCreated)
+	})
+
+	body := map[string]string{"name": "retry"}
+	if rr := postWithKey(t, handler, "/idempotency-5xx", "idem-5xx", body); rr.Code != http.StatusInternalServerError {
+		t.Fatalf("first status = %v", rr.Code)
+	}
+	rr := postWithKey(t, handler, "/idempotency-5xx", "idem-5xx", body)
+	if rr.Code != http.StatusCreated || calls != 2 {
+		t.Errorf("retry after 5xx: status = %v, calls = %d", rr.Code, calls)
+	}
+}
+
+func TestIdempotencyInProgress(t *testing.T) {
+	started, release := make(chan struct{}), make(chan struct{})
+	handler := testServer.Idempotent(func(w http.ResponseWriter, r *http.Request) {
+		close(started)
+		<-release
+		w.WriteHeader(http.StatusCreated)
+	})
+
+	body := map[string]string{"name": "slow"}
+	done := make(chan *httptest.ResponseRecorder)
+	go func() {
+		done <- postWithKey(t, handler, "/idempotency-slow", "idem-slow", body)
+	}()
+	<-started
+
+	rr := postWithKey(t, handler, "/idempotency-slow", "idem-slow", body)
+	close(release)
+	if rr.Code != http.StatusConflict || problemCode(t, rr) != apierror.IdempotencyInProgress {
+		t.Errorf("concurrent status = %v: %s", rr.Code, rr.Body.String())
+	}
+	if first := <-done; first.Code != http.StatusCreated {
+		t.Errorf("first status = %v", first.Code)
+	}
+
+	rr = postWithKey(t, handler, "/idempotency-slow", "idem-slow", body)
+	if rr.Code != http.StatusCreated || rr.Header().Get(handlers.IdempotentReplayedHeader) != "true" {
+		t.Errorf("replay status = %v, headers = %v", rr.Code, rr.Header())
+	}
+}
+
+func TestIdempotencyKeyExpiry(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+	lease := now.Add(time.Minute)
+	req := models.IdempotentRequest{
+		Scope: http.MethodPost + " /idempotency-expiry", Key: "idem-expiry", RequestHash: "hash", ExpiresAt: lease,
+	}
+
+	if existing, err := db.ReserveIdempotencyKey(ctx, testServer.DB, req, now); err != nil || existing != nil {
+		t.Fatalf("reserve = %+v, %v", existing, err)
+	}
+	existing, err := db.ReserveIdempotencyKey(ctx, testServer.DB, req, now)
+	if err != nil || existing == nil || existing.RequestHash != "hash" || existing.Status != 0 {
+		t.Fatalf("second reserve = %+v, %v", existing, err)
+	}
+
+	// Сохраненный ответ хранится весь срок, а не только время резерва.
+	req.Status, req.ExpiresAt = http.StatusCreated, now.Add(time.Hour)
+	if err := db.SaveIdempotentResponse(ctx, testServer.DB, req, lease); err != nil {
+		t.Fatal(err)
+	}
+	existing, err = db.ReserveIdempotencyKey(ctx, testServer.DB, req, now.Add(2*time.Minute))
+	if err != nil || existing == nil || existing.Status != http.StatusCreated {
+		t.Fatalf("reserve after lease = %+v, %v", existing, err)
+	}
+
+	// После истечения срока хранения ключ можно занять заново.
+	later := now.Add(2 * time.Hour)
+	req.ExpiresAt = later.Add(time.Minute)
+	if existing, err := db.ReserveIdempotencyKey(ctx, testServer.DB, req, later); err != nil || existing != nil {
+		t.Errorf("reserve after expiry = %+v, %v", existing, err)
+	}
+}
+
+func TestIdempotencyStaleReservation(t *testing.T) {
+	handler := testServer.Idempotent(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusCreated)
+	})
+	body := map[string]string{"name": "stale"}
+
+	// Резерв запроса, который так и не получил ответ: сервер упал во время него.
+	_, err := testServer.DB.Exec(`INSERT INTO idempotency_keys (scope, key, request_hash, status, expires_at)
+		VALUES (?, ?, 'crashed', 0, ?)`, http.MethodPost+" /idempotency-stale", "idem-stale", time.Now().Add(-time.Second).UnixMilli())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rr := postWithKey(t, handler, "/idempotency-stale", "idem-stale", body)
+	if rr.Code != http.StatusCreated || rr.Header().Get(handlers.IdempotentReplayedHeader) != "" {
+		t.Fatalf("retry after stale reservation: status = %v, headers = %v", rr.Code, rr.Header())
+	}
+	rr = postWithKey(t, handler, "/idempotency-stale", "idem-stale", body)
+	if rr.Code != http.StatusCreated || rr.Header().Get(handlers.IdempotentReplayedHeader) != "true" {
+		t.Errorf("replay status = %v, headers = %v", rr.Code, rr.Header())
+	}
+
+	var expiresAt int64
+	testServer.DB.QueryRow("SELECT expires_at FROM idempotency_keys WHERE key = ?", "idem-stale").Scan(&expiresAt)
+	if until := time.Until(time.UnixMilli(expiresAt)); until < time.Hour {
+		t.Errorf("saved response expires in %v, want the full TTL", until)
+	}
+}
+
+// Запрос, чей резерв занял повтор, не подменяет сохраненный ответ повтора.
+func TestIdempotencyTakeover(t *testing.T) {
+	started, release := make(chan struct{}), make(chan struct{})
+	var calls int
+	handler := testServer.Idempotent(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if calls == 1 {
+			close(started)
+			<-release
+		}
+		w.WriteHeader(http.StatusCreated)
+		fmt.Fprintf(w, "call %d", calls)
+	})
+	body := map[string]string{"name": "takeover"}
+
+	done := make(chan *httptest.ResponseRecorder)
+	go func() {
+		done <- postWithKey(t, handler, "/idempotency-takeover", "idem-takeover", body)
+	}()
+	<-started
+	if _, err := testServer.DB.Exec("UPDATE idempotency_keys SET expires_at = ? WHERE key = ?", time.Now().Add(-time.Second).UnixMilli(), "idem-takeover"); err != nil {
+		t.Fatal(err)
+	}
+
+	second := postWithKey(t, handler, "/idempotency-takeover", "idem-takeover", body)
+	close(release)
+	if second.Code != http.StatusCreated || second.Body.String() != "call 2" {
+		t.Fatalf("takeover = %v %q", second.Code, second.Body.String())
+	}
+	<-done
+
+	replay := postWithKey(t, handler, "/idempotency-takeover", "idem-takeover", body)
+	if replay.Body.String() != "call 2" || replay.Header().Get(handlers.IdempotentReplayedHeader) != "true" {
+		t.Errorf("replay = %q, headers = %v", replay.Body.String(), replay.Header())
+	}
+}
+
+func TestClientIdempotencyKey(t *testing.T) {
+	c := newTestClient(t, nil)
+	ctx := client.WithIdempotencyKey(context.Background(), "sdk-job-1")
+
+	first, err := c.CreateLink(ctx, client.ShortenRequest{URL: "https://example.com/sdk-idem"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := c.CreateLink(ctx, client.ShortenRequest{URL: "https://example.com/sdk-idem"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if first.Code != second.Code {
+		t.Errorf("codes = %q and %q, want the same link", first.Code, second.Code)
+	}
+
+	_, err = c.CreateLink(ctx, client.ShortenRequest{URL: "https://example.com/sdk-other"})
+	if client.StatusCode(err) != http.StatusUnprocessableEntity {
+		t.Errorf("reused key error = %v", err)
+	}
+}
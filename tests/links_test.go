@@ -0,0 +1,250 @@
+package tests
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"tinyurl/internal/apierror"
+	"tinyurl/internal/models"
+)
+
+func TestListLinksAPI(t *testing.T) {
+	mux := newAPIMux()
+
+	rr := doJSON(t, testServer.DomainsHandler, http.MethodPost, "/domains", map[string]interface{}{"host": "list.example"})
+	if rr.Code != http.StatusCreated {
+		t.Fatalf("create domain returned %v", rr.Code)
+	}
+	for _, alias := range []string{"list-one", "list-two", "list_3", "other"} {
+		rr := serveAPI(t, mux, http.MethodPost, "/links", map[string]interface{}{
+			"url": "https://example.com/" + alias, "alias": alias, "domain": "list.example",
+		})
+		if rr.Code != http.StatusOK {
+			t.Fatalf("shorten %s returned %v: %s", alias, rr.Code, rr.Body.String())
+		}
+	}
+
+	tests := []struct {
+		name  string
+		query string
+		want  [][]string
+	}{
+		{"All", "", [][]string{{"list-one", "list-two", "list_3", "other"}}},
+		{"Paged", "&page_size=3", [][]string{{"list-one", "list-two", "list_3"}, {"other"}}},
+		{"Search", "&search=list", [][]string{{"list-one", "list-two", "list_3"}}},
+		{"Search escapes wildcards", "&search=t_3", [][]string{{"list_3"}}},
+		{"Search by URL", "&search=example.com/oth", [][]string{{"other"}}},
+		{"No matches", "&search=missing", [][]string{{}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			token := ""
+			for i, want := range tt.want {
+				target := "/links?domain=list.example" + tt.query
+				if token != "" {
+					target += "&page_token=" + token
+				}
+				rr := serveAPI(t, mux, http.MethodGet, target, nil)
+				if rr.Code != http.StatusOK {
+					t.Fatalf("list returned %v: %s", rr.Code, rr.Body.String())
+				}
+				var page models.LinkList
+				if err := json.NewDecoder(rr.Body).Decode(&page); err != nil {
+					t.Fatal(err)
+				}
+
+				var codes []string
+				for _, link := range page.Links {
+					codes = append(codes, link.Code)
+					if link.Domain != "list.example" || link.ShortURL != "http://list.example/r/"+link.Code {
+						t.Errorf("link = %+v", link)
+					}
+				}
+				if len(codes) != len(want) {
+					t.Fatalf("page %d = %v, want %v", i, codes, want)
+				}
+				for j := range want {
+					if codes[j] != want[j] {
+						t.Errorf("page %d = %v, want %v", i, codes, want)
+					}
+				}
+
+				token = page.NextPageToken
+				if last := i == len(tt.want)-1; last != (token == "") {
+					t.Errorf("page %d next_page_token = %q", i, token)
+				}
+			}
+		})
+	}
+}
+
+func TestManageLinkAPI(t *testing.T) {
+	mux := newAPIMux()
+
+	rr := serveAPI(t, mux, http.MethodPost, "/links", map[string]interface{}{"url": "https://example.com/manage", "alias": "manage-me"})
+	if rr.Code != http.StatusOK {
+		t.Fatalf("shorten returned %v: %s", rr.Code, rr.Body.String())
+	}
+
+	rr = serveAPI(t, mux, http.MethodPatch, "/links/manage-me", map[string]interface{}{"url": "https://example.com/managed", "ttl_days": 2})
+	if rr.Code != http.StatusOK {
+		t.Fatalf("update returned %v: %s", rr.Code, rr.Body.String())
+	}
+	var stats models.StatsResponse
+	if err := json.NewDecoder(rr.Body).Decode(&stats); err != nil {
+		t.Fatal(err)
+	}
+	if stats.URL != "https://example.com/managed" || stats.ExpiresAt == nil {
+		t.Errorf("updated = %+v", stats)
+	}
+
+	rr = serveAPI(t, mux, http.MethodPatch, "/links/manage-me", map[string]interface{}{"ttl_days": 0})
+	var cleared models.StatsResponse
+	json.NewDecoder(rr.Body).Decode(&cleared)
+	if rr.Code != http.StatusOK || cleared.ExpiresAt != nil || cleared.URL != "https://example.com/managed" {
+		t.Errorf("clear ttl returned %v: %+v", rr.Code, cleared)
+	}
+
+	rr = serveAPI(t, mux, http.MethodGet, "/links/manage-me/resolve", nil)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("resolve returned %v: %s", rr.Code, rr.Body.String())
+	}
+	var resolved models.ResolveResponse
+	if err := json.NewDecoder(rr.Body).Decode(&resolved); err != nil {
+		t.Fatal(err)
+	}
+	if resolved.URL != "https://example.com/managed" || resolved.ShortURL != "http://example.com/r/manage-me" || resolved.RedirectType != http.StatusFound {
+		t.Errorf("resolved = %+v", resolved)
+	}
+
+	rr = serveAPI(t, mux, http.MethodGet, "/links/manage-me", nil)
+	json.NewDecoder(rr.Body).Decode(&stats)
+	if stats.HitCount != 0 {
+		t.Errorf("resolve counted a hit: %d", stats.HitCount)
+	}
+
+	rr = serveAPI(t, mux, http.MethodDelete, "/links/manage-me", nil)
+	if rr.Code != http.StatusNoContent {
+		t.Fatalf("delete returned %v: %s", rr.Code, rr.Body.String())
+	}
+	for _, method := range []string{http.MethodGet, http.MethodDelete} {
+		if rr := serveAPI(t, mux, method, "/links/manage-me", nil); rr.Code != http.StatusNotFound {
+			t.Errorf("%s after delete returned %v", method, rr.Code)
+		}
+	}
+}
+
+// Постоянное перенаправление изменяемой ссылки не должно кэшироваться: после
+// PATCH следующий переход ведет на новый адрес.
+func TestPermanentRedirectAfterUpdate(t *testing.T) {
+	mux := newAPIMux()
+
+	rr := serveAPI(t, mux, http.MethodPost, "/links", map[string]interface{}{
+		"url": "https://example.com/before", "alias": "cache-update", "redirect_type": http.StatusMovedPermanently,
+	})
+	if rr.Code != http.StatusOK {
+		t.Fatalf("shorten returned %v: %s", rr.Code, rr.Body.String())
+	}
+
+	check := func(want string) {
+		t.Helper()
+		rr := httptest.NewRecorder()
+		testServer.RedirectHandler(rr, httptest.NewRequest(http.MethodGet, "/r/cache-update", nil))
+		if rr.Code != http.StatusMovedPermanently || rr.Header().Get("Location") != want {
+			t.Errorf("redirect = %v %s, want %s", rr.Code, rr.Header().Get("Location"), want)
+		}
+		if cache := rr.Header().Get("Cache-Control"); cache != "private, max-age=0, must-revalidate" {
+			t.Errorf("Cache-Control = %q", cache)
+		}
+	}
+
+	check("https://example.com/before")
+	rr = serveAPI(t, mux, http.MethodPatch, "/links/cache-update", map[string]interface{}{"url": "https://example.com/after"})
+	if rr.Code != http.StatusOK {
+		t.Fatalf("update returned %v: %s", rr.Code, rr.Body.String())
+	}
+	check("https://example.com/after")
+}
+
+func TestManageLinkAPIErrors(t *testing.T) {
+	mux := newAPIMux()
+
+	rr := serveAPI(t, mux, http.MethodPost, "/links", map[string]interface{}{"url": "https://example.com/errors", "alias": "manage-errors"})
+	if rr.Code != http.StatusOK {
+		t.Fatalf("shorten returned %v: %s", rr.Code, rr.Body.String())
+	}
+
+	tests := []struct {
+		name       string
+		method     string
+		target     string
+		body       interface{}
+		wantStatus int
+		wantCode   apierror.Code
+		wantField  string
+	}{
+		{
+			name: "Invalid page size", method: http.MethodGet, target: "/links?page_size=many",
+			wantStatus: http.StatusBadRequest, wantCode: apierror.InvalidPageSize, wantField: "page_size",
+		},
+		{
+			name: "Negative page size", method: http.MethodGet, target: "/links?page_size=-1",
+			wantStatus: http.StatusBadRequest, wantCode: apierror.InvalidPageSize, wantField: "page_size",
+		},
+		{
+			name: "Invalid page token", method: http.MethodGet, target: "/links?page_token=x",
+			wantStatus: http.StatusBadRequest, wantCode: apierror.InvalidPageToken, wantField: "page_token",
+		},
+		{
+			name: "Unknown domain", method: http.MethodGet, target: "/links?domain=missing.example",
+			wantStatus: http.StatusNotFound, wantCode: apierror.DomainNotFound,
+		},
+		{
+			name: "Negative TTL", method: http.MethodPatch, target: "/links/manage-errors",
+			body:       map[string]interface{}{"ttl_days": -3},
+			wantStatus: http.StatusBadRequest, wantCode: apierror.InvalidTTL, wantField: "ttl_days",
+		},
+		{
+			name: "Invalid redirect type", method: http.MethodPatch, target: "/links/manage-errors",
+			body:       map[string]interface{}{"redirect_type": 303},
+			wantStatus: http.StatusBadRequest, wantCode: apierror.InvalidRedirectType, wantField: "redirect_type",
+		},
+		{
+			name: "Update unknown link", method: http.MethodPatch, target: "/links/manage-missing",
+			body:       map[string]interface{}{"url": "https://example.com"},
+			wantStatus: http.StatusNotFound, wantCode: apierror.LinkNotFound,
+		},
+		{
+			name: "Resolve unknown link", method: http.MethodGet, target: "/links/manage-missing/resolve",
+			wantStatus: http.StatusNotFound, wantCode: apierror.LinkNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rr := serveAPI(t, mux, tt.method, tt.target, tt.body)
+			if rr.Code != tt.wantStatus {
+				t.Fatalf("status = %v, want %v: %s", rr.Code, tt.wantStatus, rr.Body.String())
+			}
+			var problem apierror.Problem
+			if err := json.NewDecoder(rr.Body).Decode(&problem); err != nil {
+				t.Fatal(err)
+			}
+			if problem.Code != tt.wantCode || problem.Field != tt.wantField {
+				t.Errorf("problem = %+v", problem)
+			}
+		})
+	}
+
+	t.Run("Malformed body", func(t *testing.T) {
+		rr := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPatch, "/api/v1/links/manage-errors", nil)
+		mux.ServeHTTP(rr, req)
+		if rr.Code != http.StatusBadRequest {
+			t.Errorf("empty body returned %v", rr.Code)
+		}
+	})
+}
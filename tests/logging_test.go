@@ -0,0 +1,151 @@
+package tests
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"tinyurl/internal/handlers"
+	"tinyurl/internal/logging"
+)
+
+func TestNewLogger(t *testing.T) {
+	tests := []struct {
+		name    string
+		format  string
+		level   string
+		wantErr bool
+	}{
+		{"Text info", "text", "info", false},
+		{"JSON debug", "json", "debug", false},
+		{"Upper case", "JSON", "WARN", false},
+		{"Unknown format", "xml", "info", true},
+		{"Unknown level", "text", "verbose", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := logging.New(&bytes.Buffer{}, tt.format, tt.level)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("New() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func serveLogged(t *testing.T, server *handlers.Server, req *http.Request) (*httptest.ResponseRecorder, []map[string]interface{}) {
+	t.Helper()
+
+	var buf bytes.Buffer
+	logger, err := logging.New(&buf, "json", "debug")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/shorten", server.ShortenHandler)
+	mux.HandleFunc("/r/", server.RedirectHandler)
+	mux.HandleFunc("/stats/", server.StatsHandler)
+
+	rr := httptest.NewRecorder()
+	logging.Middleware(logger, mux, mux).ServeHTTP(rr, req)
+
+	var entries []map[string]interface{}
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		if line == "" {
+			continue
+		}
+		var entry map[string]interface{}
+		if err := json.Unmarshal([]byte(line), &entry); err != nil {
+			t.Fatalf("log line is not JSON: %s", line)
+		}
+		entries = append(entries, entry)
+	}
+	return rr, entries
+}
+
+func TestRequestIDMiddleware(t *testing.T) {
+	tests := []struct {
+		name     string
+		incoming string
+		keep     bool
+	}{
+		{"Generated", "", false},
+		{"Propagated", "abc-123.def", true},
+		{"Header injection rejected", "abc\r\nX-Evil: 1", false},
+		{"Too long rejected", strings.Repeat("a", 200), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/r/no-such-code-logged", nil)
+			if tt.incoming != "" {
+				req.Header.Set(logging.RequestIDHeader, tt.incoming)
+			}
+			rr, entries := serveLogged(t, testServer, req)
+
+			id := rr.Header().Get(logging.RequestIDHeader)
+			if id == "" {
+				t.Fatal("response has no X-Request-ID")
+			}
+			if tt.keep && id != tt.incoming {
+				t.Errorf("X-Request-ID = %q, want %q", id, tt.incoming)
+			}
+			if !tt.keep && id == tt.incoming {
+				t.Errorf("invalid X-Request-ID %q was propagated", id)
+			}
+
+			if len(entries) != 1 {
+				t.Fatalf("got %d log entries, want 1", len(entries))
+			}
+			entry := entries[0]
+			if entry["request_id"] != id {
+				t.Errorf("request_id = %v, want %q", entry["request_id"], id)
+			}
+			if entry["route"] != "/r/" || entry["status"] != float64(http.StatusNotFound) || entry["code"] != "no-such-code-logged" {
+				t.Errorf("access log = %v", entry)
+			}
+			if _, ok := entry["latency"]; !ok {
+				t.Errorf("access log has no latency: %v", entry)
+			}
+		})
+	}
+}
+
+func TestServerErrorIsLogged(t *testing.T) {
+	closed, err := sql.Open("sqlite", "file:closed.db?mode=memory")
+	if err != nil {
+		t.Fatal(err)
+	}
+	closed.Close()
+
+	req := httptest.NewRequest(http.MethodGet, "/stats/anything", nil)
+	req.Header.Set(logging.RequestIDHeader, "failing-request")
+	rr, entries := serveLogged(t, handlers.NewServer(closed), req)
+
+	if rr.Code != http.StatusInternalServerError {
+		t.Fatalf("returned %v, want %v", rr.Code, http.StatusInternalServerError)
+	}
+
+	var sawError, sawAccess bool
+	for _, entry := range entries {
+		if entry["request_id"] != "failing-request" {
+			t.Errorf("entry without request id: %v", entry)
+		}
+		if entry["level"] != "ERROR" {
+			continue
+		}
+		if entry["msg"] == "http request" {
+			sawAccess = true
+		} else if entry["error"] != nil {
+			sawError = true
+		}
+	}
+	if !sawError || !sawAccess {
+		t.Errorf("expected error and access log entries, got %v", entries)
+	}
+}
@@ -0,0 +1,82 @@
+package tests
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"tinyurl/internal/metrics"
+)
+
+func TestMetricsEndpoint(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/shorten", testServer.ShortenHandler)
+	mux.HandleFunc("/r/", testServer.RedirectHandler)
+	mux.Handle("/metrics", metrics.Handler())
+	srv := httptest.NewServer(metrics.Middleware(mux))
+	defer srv.Close()
+
+	resp, err := http.Post(srv.URL+"/shorten", "application/json",
+		strings.NewReader(`{"url":"https://example.com","alias":"metrics-link"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+
+	client := &http.Client{CheckRedirect: func(*http.Request, []*http.Request) error {
+		return http.ErrUseLastResponse
+	}}
+	for _, path := range []string{"/r/metrics-link", "/r/metrics-missing", "/nowhere"} {
+		resp, err := client.Get(srv.URL + path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+	}
+
+	waitFor(t, func() bool {
+		return strings.Contains(scrape(t, srv.URL), "tinyurl_hits_pending 0")
+	})
+	body := scrape(t, srv.URL)
+
+	for _, want := range []string{
+		`tinyurl_http_request_duration_seconds_count{method="POST",route="/shorten",status="200"}`,
+		`tinyurl_http_request_duration_seconds_count{method="GET",route="/r/",status="302"}`,
+		`tinyurl_http_request_duration_seconds_count{method="GET",route="/r/",status="404"}`,
+		`tinyurl_http_request_duration_seconds_count{method="GET",route="unmatched",status="404"}`,
+		`tinyurl_shorten_total{result="created"}`,
+		`tinyurl_redirect_total{result="redirect"}`,
+		`tinyurl_redirect_total{result="not_found"}`,
+		`tinyurl_db_query_duration_seconds_count{operation="get_link"}`,
+		`tinyurl_db_query_duration_seconds_count{operation="get_link_targeting"}`,
+		`tinyurl_db_query_duration_seconds_count{operation="increment_hit_count"}`,
+		`tinyurl_code_collisions_total`,
+		`go_goroutines`,
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("metrics output is missing %s", want)
+		}
+	}
+
+	if strings.Contains(body, "metrics-link") {
+		t.Error("link codes must not be used as metric labels")
+	}
+}
+
+func scrape(t *testing.T, baseURL string) string {
+	t.Helper()
+
+	resp, err := http.Get(baseURL + "/metrics")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(body)
+}
@@ -0,0 +1,141 @@
+package tests
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"net"
+	"os"
+	"sort"
+)
+
+// writeCountryMMDB пишет минимальную IPv4-базу в формате MaxMind DB с записями
+// вида {"country": {"iso_code": ...}} для каждой сети из networks.
+func writeCountryMMDB(path string, networks map[string]string) error {
+	type node struct{ records [2]int }
+	const (
+		empty = -1
+		leaf  = -2
+	)
+
+	nodes := []node{{records: [2]int{empty, empty}}}
+	leaves := map[[2]int]int{}
+
+	cidrs := make([]string, 0, len(networks))
+	for cidr := range networks {
+		cidrs = append(cidrs, cidr)
+	}
+	sort.Strings(cidrs)
+
+	var data bytes.Buffer
+	for _, cidr := range cidrs {
+		_, network, err := net.ParseCIDR(cidr)
+		if err != nil {
+			return err
+		}
+		ip := network.IP.To4()
+		if ip == nil {
+			return fmt.Errorf("only IPv4 networks are supported: %s", cidr)
+		}
+		prefix, _ := network.Mask.Size()
+
+		offset := data.Len()
+		encodeMap(&data, []interface{}{"country", []interface{}{"iso_code", networks[cidr]}})
+
+		current := 0
+		for bit := 0; bit < prefix; bit++ {
+			side := int(ip[bit/8]>>(7-uint(bit%8))) & 1
+			if bit == prefix-1 {
+				nodes[current].records[side] = leaf
+				leaves[[2]int{current, side}] = offset
+				break
+			}
+			next := nodes[current].records[side]
+			if next < 0 {
+				nodes = append(nodes, node{records: [2]int{empty, empty}})
+				next = len(nodes) - 1
+				nodes[current].records[side] = next
+			}
+			current = next
+		}
+	}
+
+	nodeCount := len(nodes)
+	var out bytes.Buffer
+	for i, n := range nodes {
+		for side, record := range n.records {
+			value := record
+			switch record {
+			case empty:
+				value = nodeCount
+			case leaf:
+				value = nodeCount + 16 + leaves[[2]int{i, side}]
+			}
+			out.Write([]byte{byte(value >> 16), byte(value >> 8), byte(value)})
+		}
+	}
+	out.Write(make([]byte, 16))
+	out.Write(data.Bytes())
+
+	out.WriteString("\xab\xcd\xefMaxMind.com")
+	encodeMap(&out, []interface{}{
+		"binary_format_major_version", uint16(2),
+		"binary_format_minor_version", uint16(0),
+		"build_epoch", uint64(1700000000),
+		"database_type", "Test-Country",
+		"description", []interface{}{"en", "tinyurl test fixture"},
+		"ip_version", uint16(4),
+		"languages", []string{"en"},
+		"node_count", uint32(nodeCount),
+		"record_size", uint16(24),
+	})
+
+	return os.WriteFile(path, out.Bytes(), 0o644)
+}
+
+// encodeMap кодирует пары ключ-значение; вложенная карта задается срезом []interface{}.
+func encodeMap(buf *bytes.Buffer, pairs []interface{}) {
+	writeControl(buf, 7, len(pairs)/2)
+	for i := 0; i < len(pairs); i += 2 {
+		encodeValue(buf, pairs[i])
+		encodeValue(buf, pairs[i+1])
+	}
+}
+
+func encodeValue(buf *bytes.Buffer, v interface{}) {
+	switch value := v.(type) {
+	case string:
+		writeControl(buf, 2, len(value))
+		buf.WriteString(value)
+	case uint16:
+		writeControl(buf, 5, 2)
+		binary.Write(buf, binary.BigEndian, value)
+	case uint32:
+		writeControl(buf, 6, 4)
+		binary.Write(buf, binary.BigEndian, value)
+	case uint64:
+		writeControl(buf, 9, 8)
+		binary.Write(buf, binary.BigEndian, value)
+	case []string:
+		writeControl(buf, 11, len(value))
+		for _, s := range value {
+			encodeValue(buf, s)
+		}
+	case []interface{}:
+		encodeMap(buf, value)
+	default:
+		panic(fmt.Sprintf("unsupported mmdb value %T", v))
+	}
+}
+
+func writeControl(buf *bytes.Buffer, typeNum, size int) {
+	if size >= 29 {
+		panic("mmdb fixture values must be shorter than 29 bytes")
+	}
+	if typeNum <= 7 {
+		buf.WriteByte(byte(typeNum<<5 | size))
+		return
+	}
+	buf.WriteByte(byte(size))
+	buf.WriteByte(byte(typeNum - 7))
+}
@@ -0,0 +1,286 @@
+package tests
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+
+	"tinyurl/internal/apierror"
+	"tinyurl/internal/handlers"
+)
+
+type specSchema struct {
+	Ref                  string                 `json:"$ref"`
+	Type                 string                 `json:"type"`
+	Nullable             bool                   `json:"nullable"`
+	Properties           map[string]*specSchema `json:"properties"`
+	Required             []string               `json:"required"`
+	Items                *specSchema            `json:"items"`
+	AdditionalProperties *specSchema            `json:"additionalProperties"`
+}
+
+type specOperation struct {
+	Parameters []struct {
+		Name string `json:"name"`
+		In   string `json:"in"`
+	} `json:"parameters"`
+	Responses map[string]struct {
+		Content map[string]struct {
+			Schema *specSchema `json:"schema"`
+		} `json:"content"`
+	} `json:"responses"`
+}
+
+type spec struct {
+	OpenAPI string                              `json:"openapi"`
+	Servers []struct{ URL string }              `json:"servers"`
+	Paths   map[string]map[string]specOperation `json:"paths"`
+	Schemas map[string]*specSchema
+}
+
+func newAPIMux() *http.ServeMux {
+	mux := http.NewServeMux()
+	testServer.RegisterAPI(mux)
+	mux.HandleFunc("/", testServer.NotFoundHandler)
+	return mux
+}
+
+func loadSpec(t *testing.T, mux *http.ServeMux) *spec {
+	t.Helper()
+
+	rr := httptest.NewRecorder()
+	mux.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, handlers.APIPrefix+"/openapi.json", nil))
+	if rr.Code != http.StatusOK {
+		t.Fatalf("openapi.json returned %v", rr.Code)
+	}
+
+	var doc struct {
+		spec
+		Components struct {
+			Schemas map[string]*specSchema `json:"schemas"`
+		} `json:"components"`
+	}
+	if err := json.NewDecoder(rr.Body).Decode(&doc); err != nil {
+		t.Fatal(err)
+	}
+	doc.spec.Schemas = doc.Components.Schemas
+	return &doc.spec
+}
+
+// checkSchema проверяет, что значение соответствует схеме: совпадают типы, есть все
+// обязательные поля и нет полей, которых нет в спецификации.
+func (s *spec) checkSchema(t *testing.T, path string, value any, schema *specSchema) {
+	t.Helper()
+
+	if schema.Ref != "" {
+		name := strings.TrimPrefix(schema.Ref, "#/components/schemas/")
+		resolved, ok := s.Schemas[name]
+		if !ok {
+			t.Errorf("%s: unknown schema %s", path, schema.Ref)
+			return
+		}
+		schema = resolved
+	}
+	if value == nil {
+		if !schema.Nullable {
+			t.Errorf("%s: null is not allowed", path)
+		}
+		return
+	}
+
+	switch schema.Type {
+	case "object":
+		obj, ok := value.(map[string]any)
+		if !ok {
+			t.Errorf("%s: got %T, want object", path, value)
+			return
+		}
+		for _, name := range schema.Required {
+			if _, ok := obj[name]; !ok {
+				t.Errorf("%s: required field %q is missing", path, name)
+			}
+		}
+		for name, v := range obj {
+			switch prop, ok := schema.Properties[name]; {
+			case ok:
+				s.checkSchema(t, path+"."+name, v, prop)
+			case schema.AdditionalProperties != nil:
+				s.checkSchema(t, path+"."+name, v, schema.AdditionalProperties)
+			case schema.Properties != nil:
+				t.Errorf("%s: field %q is not in the spec", path, name)
+			}
+		}
+	case "array":
+		items, ok := value.([]any)
+		if !ok {
+			t.Errorf("%s: got %T, want array", path, value)
+			return
+		}
+		for i, item := range items {
+			s.checkSchema(t, path+"["+strconv.Itoa(i)+"]", item, schema.Items)
+		}
+	case "string":
+		if _, ok := value.(string); !ok {
+			t.Errorf("%s: got %T, want string", path, value)
+		}
+	case "integer", "number":
+		if _, ok := value.(float64); !ok {
+			t.Errorf("%s: got %T, want %s", path, value, schema.Type)
+		}
+	case "boolean":
+		if _, ok := value.(bool); !ok {
+			t.Errorf("%s: got %T, want boolean", path, value)
+		}
+	}
+}
+
+// checkResponse проверяет, что код и тело ответа описаны в спецификации операции.
+func (s *spec) checkResponse(t *testing.T, method, path string, rr *httptest.ResponseRecorder) {
+	t.Helper()
+
+	op, ok := s.Paths[path][strings.ToLower(method)]
+	if !ok {
+		t.Fatalf("%s %s is not in the spec", method, path)
+	}
+	resp, ok := op.Responses[strconv.Itoa(rr.Code)]
+	if !ok {
+		t.Fatalf("%s %s: status %d is not documented: %s", method, path, rr.Code, rr.Body.String())
+	}
+	if rr.Body.Len() == 0 {
+		if len(resp.Content) != 0 {
+			t.Errorf("%s %s: empty body, want %v", method, path, resp.Content)
+		}
+		return
+	}
+
+	contentType := strings.TrimSpace(strings.Split(rr.Header().Get("Content-Type"), ";")[0])
+	media, ok := resp.Content[contentType]
+	if !ok {
+		t.Fatalf("%s %s: content type %q is not documented for %d", method, path, contentType, rr.Code)
+	}
+	if contentType != "application/json" && contentType != apierror.ContentType {
+		return
+	}
+
+	var body any
+	if err := json.Unmarshal(rr.Body.Bytes(), &body); err != nil {
+		t.Fatal(err)
+	}
+	s.checkSchema(t, method+" "+path, body, media.Schema)
+}
+
+func TestOpenAPIRoutesMatchHandlers(t *testing.T) {
+	mux := newAPIMux()
+	doc := loadSpec(t, mux)
+
+	if !strings.HasPrefix(doc.OpenAPI, "3.") {
+		t.Errorf("openapi = %q", doc.OpenAPI)
+	}
+	if len(doc.Servers) != 1 || doc.Servers[0].URL != handlers.APIPrefix {
+		t.Errorf("servers = %+v", doc.Servers)
+	}
+
+	allMethods := []string{http.MethodGet, http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete}
+	for path, item := range doc.Paths {
+		target := handlers.APIPrefix + strings.NewReplacer("{code}", "no-such-code", "{host}", "no-such.host", "{name}", "no-such-template", "{id}", "999999", "{delivery}", "999999").Replace(path)
+
+		var documented []string
+		for _, method := range allMethods {
+			if _, ok := item[strings.ToLower(method)]; ok {
+				documented = append(documented, method)
+			}
+		}
+		sort.Strings(documented)
+
+		for _, method := range allMethods {
+			rr := httptest.NewRecorder()
+			mux.ServeHTTP(rr, httptest.NewRequest(method, target, strings.NewReader("{}")))
+
+			if _, ok := item[strings.ToLower(method)]; ok {
+				if rr.Code == http.StatusMethodNotAllowed {
+					t.Errorf("%s %s is documented but not routed", method, path)
+				}
+				doc.checkResponse(t, method, path, rr)
+				continue
+			}
+
+			if rr.Code != http.StatusMethodNotAllowed {
+				t.Errorf("%s %s is routed (%d) but not documented", method, path, rr.Code)
+				continue
+			}
+			allow := strings.Split(rr.Header().Get("Allow"), ", ")
+			sort.Strings(allow)
+			if strings.Join(allow, ",") != strings.Join(documented, ",") {
+				t.Errorf("%s: Allow = %v, spec has %v", path, allow, documented)
+			}
+		}
+
+		for _, op := range item {
+			for _, p := range op.Parameters {
+				if p.In == "path" && !strings.Contains(path, "{"+p.Name+"}") {
+					t.Errorf("%s: path parameter %q is not in the path", path, p.Name)
+				}
+			}
+		}
+	}
+}
+
+func TestOpenAPIResponsesMatchModels(t *testing.T) {
+	mux := newAPIMux()
+	doc := loadSpec(t, mux)
+
+	steps := []struct {
+		method string
+		target string
+		path   string
+		body   any
+		status int
+	}{
+		{http.MethodPost, "/utm-templates", "/utm-templates", map[string]string{
+			"name": "spec-tmpl", "utm_source": "newsletter", "utm_medium": "email", "utm_campaign": "spec",
+		}, http.StatusCreated},
+		{http.MethodPost, "/links", "/links", map[string]any{
+			"url": "https://example.com/spec", "alias": "spec-link", "utm_template": "spec-tmpl",
+			"rules":    []map[string]any{{"name": "bots", "bot": true, "url": "https://example.com/bot"}},
+			"variants": []map[string]any{{"name": "a", "url": "https://example.com/a", "weight": 1}},
+			"geo":      map[string]string{"DE": "https://example.com/de"},
+		}, http.StatusOK},
+		{http.MethodPost, "/links", "/links", map[string]any{"url": "https://example.com/spec", "alias": "spec-link"}, http.StatusConflict},
+		{http.MethodGet, "/links/spec-link", "/links/{code}", nil, http.StatusOK},
+		{http.MethodGet, "/links/spec-link/qr?format=svg", "/links/{code}/qr", nil, http.StatusOK},
+		{http.MethodGet, "/links/spec-link/qr?size=abc", "/links/{code}/qr", nil, http.StatusBadRequest},
+		{http.MethodGet, "/campaigns", "/campaigns", nil, http.StatusOK},
+		{http.MethodPost, "/domains", "/domains", map[string]any{"host": "spec.example", "redirect_type": 301}, http.StatusCreated},
+		{http.MethodGet, "/domains", "/domains", nil, http.StatusOK},
+		{http.MethodPut, "/domains/spec.example", "/domains/{host}", map[string]any{"code_length": 8}, http.StatusOK},
+		{http.MethodGet, "/domains/spec.example", "/domains/{host}", nil, http.StatusOK},
+		{http.MethodDelete, "/domains/spec.example", "/domains/{host}", nil, http.StatusNoContent},
+		{http.MethodGet, "/utm-templates", "/utm-templates", nil, http.StatusOK},
+		{http.MethodPut, "/utm-templates/spec-tmpl", "/utm-templates/{name}", map[string]string{
+			"utm_source": "newsletter", "utm_medium": "email", "utm_campaign": "spec-2",
+		}, http.StatusOK},
+		{http.MethodGet, "/utm-templates/spec-tmpl", "/utm-templates/{name}", nil, http.StatusOK},
+		{http.MethodDelete, "/utm-templates/spec-tmpl", "/utm-templates/{name}", nil, http.StatusConflict},
+	}
+
+	for _, step := range steps {
+		t.Run(step.method+" "+step.target, func(t *testing.T) {
+			var body bytes.Buffer
+			if step.body != nil {
+				json.NewEncoder(&body).Encode(step.body)
+			}
+			rr := httptest.NewRecorder()
+			mux.ServeHTTP(rr, httptest.NewRequest(step.method, handlers.APIPrefix+step.target, &body))
+
+			if rr.Code != step.status {
+				t.Fatalf("status = %v, want %v: %s", rr.Code, step.status, rr.Body.String())
+			}
+			doc.checkResponse(t, step.method, step.path, rr)
+		})
+	}
+}
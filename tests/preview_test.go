@@ -0,0 +1,91 @@
+package tests
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"tinyurl/internal/db"
+	"tinyurl/internal/models"
+)
+
+func TestRedirectHandlerPreview(t *testing.T) {
+	destination := "https://example.com/page?a=1&b=<script>"
+	if err := db.InsertLink(testServer.DB, "preview_me", destination, 0); err != nil {
+		t.Fatalf("Failed to create test link: %v", err)
+	}
+
+	testCases := []struct {
+		name   string
+		target string
+	}{
+		{"Plus suffix", "/r/preview_me+"},
+		{"Query parameter", "/r/preview_me?preview"},
+		{"Query parameter with value", "/r/preview_me?preview=1"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, tc.target, nil)
+			rr := httptest.NewRecorder()
+			http.HandlerFunc(testServer.RedirectHandler).ServeHTTP(rr, req)
+
+			if rr.Code != http.StatusOK {
+				t.Fatalf("handler returned wrong status code: got %v want %v", rr.Code, http.StatusOK)
+			}
+			if ct := rr.Header().Get("Content-Type"); !strings.HasPrefix(ct, "text/html") {
+				t.Errorf("handler returned wrong content type: %v", ct)
+			}
+			body := rr.Body.String()
+			if !strings.Contains(body, "https://example.com/page?a=1&amp;b=&lt;script&gt;") {
+				t.Errorf("preview page does not contain escaped destination: %s", body)
+			}
+			if strings.Contains(body, "<script>") {
+				t.Error("preview page contains unescaped destination")
+			}
+		})
+	}
+
+	time.Sleep(50 * time.Millisecond)
+	link, err := db.GetLink(testServer.DB, "preview_me")
+	if err != nil {
+		t.Fatalf("GetLink failed: %v", err)
+	}
+	if link.HitCount != 0 {
+		t.Errorf("preview should not count hits, got %d", link.HitCount)
+	}
+}
+
+func TestRedirectHandlerInterstitial(t *testing.T) {
+	rr := doJSON(t, testServer.ShortenHandler, http.MethodPost, "/shorten", map[string]interface{}{
+		"url":          "https://example.com/careful",
+		"alias":        "always_preview",
+		"interstitial": true,
+	})
+	if rr.Code != http.StatusOK {
+		t.Fatalf("shorten returned %v: %s", rr.Code, rr.Body.String())
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/r/always_preview", nil)
+	rr = httptest.NewRecorder()
+	http.HandlerFunc(testServer.RedirectHandler).ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("handler returned wrong status code: got %v want %v", rr.Code, http.StatusOK)
+	}
+	if !strings.Contains(rr.Body.String(), `href="https://example.com/careful"`) {
+		t.Errorf("interstitial page does not link to destination: %s", rr.Body.String())
+	}
+
+	waitFor(t, func() bool {
+		rr := doJSON(t, testServer.StatsHandler, http.MethodGet, "/stats/always_preview", nil)
+		var stats models.StatsResponse
+		if err := json.Unmarshal(rr.Body.Bytes(), &stats); err != nil {
+			return false
+		}
+		return stats.HitCount == 1 && stats.Interstitial
+	})
+}
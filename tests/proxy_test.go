@@ -0,0 +1,314 @@
+package tests
+
+import (
+	"crypto/tls"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"tinyurl/internal/handlers"
+	"tinyurl/internal/models"
+	"tinyurl/internal/utils"
+)
+
+func TestParseTrustedProxies(t *testing.T) {
+	proxies, err := utils.ParseTrustedProxies("10.0.0.0/8, 192.168.1.5, ::1")
+	if err != nil {
+		t.Fatalf("ParseTrustedProxies: %v", err)
+	}
+
+	tests := []struct {
+		ip   string
+		want bool
+	}{
+		{"10.1.2.3", true},
+		{"192.168.1.5", true},
+		{"192.168.1.6", false},
+		{"::1", true},
+		{"203.0.113.7", false},
+	}
+	for _, tt := range tests {
+		if got := proxies.Contains(net.ParseIP(tt.ip)); got != tt.want {
+			t.Errorf("Contains(%s) = %v, want %v", tt.ip, got, tt.want)
+		}
+	}
+
+	for _, bad := range []string{"not-an-ip", "10.0.0.0/33"} {
+		if _, err := utils.ParseTrustedProxies(bad); err == nil {
+			t.Errorf("ParseTrustedProxies(%q) succeeded, want error", bad)
+		}
+	}
+}
+
+func TestPublicOrigin(t *testing.T) {
+	trusted, _ := utils.ParseTrustedProxies("10.0.0.0/8")
+
+	tests := []struct {
+		name       string
+		remoteAddr string
+		tls        bool
+		headers    map[string]string
+		expected   string
+	}{
+		{
+			name:       "No proxy headers",
+			remoteAddr: "10.0.0.1:1234",
+			expected:   "http://internal:8080",
+		},
+		{
+			name:       "TLS without proxy",
+			remoteAddr: "203.0.113.7:1234",
+			tls:        true,
+			expected:   "https://internal:8080",
+		},
+		{
+			name:       "X-Forwarded from trusted proxy",
+			remoteAddr: "10.0.0.1:1234",
+			headers:    map[string]string{"X-Forwarded-Proto": "https", "X-Forwarded-Host": "sho.rt"},
+			expected:   "https://sho.rt",
+		},
+		{
+			name:       "X-Forwarded spoofed by untrusted client",
+			remoteAddr: "203.0.113.7:1234",
+			headers:    map[string]string{"X-Forwarded-Proto": "https", "X-Forwarded-Host": "evil.example"},
+			expected:   "http://internal:8080",
+		},
+		{
+			name:       "Forwarded spoofed by untrusted client",
+			remoteAddr: "203.0.113.7:1234",
+			headers:    map[string]string{"Forwarded": "proto=https;host=evil.example"},
+			expected:   "http://internal:8080",
+		},
+		{
+			name:       "Forwarded from trusted proxy",
+			remoteAddr: "10.0.0.1:1234",
+			headers:    map[string]string{"Forwarded": `for=203.0.113.7;proto=https;host="sho.rt"`},
+			expected:   "https://sho.rt",
+		},
+		{
+			name:       "Forwarded wins over X-Forwarded",
+			remoteAddr: "10.0.0.1:1234",
+			headers: map[string]string{
+				"Forwarded":         "for=203.0.113.7;proto=https;host=sho.rt",
+				"X-Forwarded-Host":  "other.example",
+				"X-Forwarded-Proto": "http",
+			},
+			expected: "https://sho.rt",
+		},
+		{
+			name:       "Client-supplied Forwarded element is skipped",
+			remoteAddr: "10.0.0.1:1234",
+			headers:    map[string]string{"Forwarded": "for=1.2.3.4;host=evil.example, for=203.0.113.7;proto=https;host=sho.rt"},
+			expected:   "https://sho.rt",
+		},
+		{
+			name:       "Chain of trusted proxies",
+			remoteAddr: "10.0.0.1:1234",
+			headers:    map[string]string{"Forwarded": "for=203.0.113.7;proto=https;host=sho.rt, for=10.0.0.2;proto=http;host=lb.internal"},
+			expected:   "https://sho.rt",
+		},
+		{
+			name:       "Client-supplied X-Forwarded-Host value is skipped",
+			remoteAddr: "10.0.0.1:1234",
+			headers:    map[string]string{"X-Forwarded-Host": "evil.example, sho.rt"},
+			expected:   "http://sho.rt",
+		},
+		{
+			name:       "Invalid forwarded values are ignored",
+			remoteAddr: "10.0.0.1:1234",
+			headers:    map[string]string{"X-Forwarded-Proto": "javascript", "X-Forwarded-Host": "evil.example/path"},
+			expected:   "http://internal:8080",
+		},
+		{
+			name:       "Host with userinfo is ignored",
+			remoteAddr: "10.0.0.1:1234",
+			headers:    map[string]string{"Forwarded": "proto=https;host=user@evil.example"},
+			expected:   "https://internal:8080",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/shorten", nil)
+			req.Host = "internal:8080"
+			req.RemoteAddr = tt.remoteAddr
+			if tt.tls {
+				req.TLS = &tls.ConnectionState{}
+			}
+			for k, v := range tt.headers {
+				req.Header.Set(k, v)
+			}
+
+			if got := utils.PublicBaseURL(req, trusted); got != tt.expected {
+				t.Errorf("PublicBaseURL() = %q, want %q", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestShortURLBehindProxy(t *testing.T) {
+	trusted, _ := utils.ParseTrustedProxies("10.0.0.0/8")
+
+	tests := []struct {
+		name       string
+		baseURL    string
+		remoteAddr string
+		expected   string
+	}{
+		{"Canonical base URL", "https://sho.rt/go", "203.0.113.7:1234", "https://sho.rt/go/r/"},
+		{"Trusted proxy headers", "", "10.0.0.1:1234", "https://public.example/r/"},
+		{"Untrusted proxy headers", "", "203.0.113.7:1234", "http://internal:8080/r/"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := handlers.NewServer(testServer.DB)
+			server.BaseURL = tt.baseURL
+			server.TrustedProxies = trusted
+
+			req := httptest.NewRequest(http.MethodPost, "/shorten", strings.NewReader(`{"url":"https://example.com"}`))
+			req.Host = "internal:8080"
+			req.RemoteAddr = tt.remoteAddr
+			req.Header.Set("X-Forwarded-Proto", "https")
+			req.Header.Set("X-Forwarded-Host", "public.example")
+			rr := httptest.NewRecorder()
+			server.ShortenHandler(rr, req)
+			if rr.Code != http.StatusOK {
+				t.Fatalf("shorten returned %v: %s", rr.Code, rr.Body.String())
+			}
+
+			var resp models.ShortenResponse
+			json.NewDecoder(rr.Body).Decode(&resp)
+			if !strings.HasPrefix(resp.ShortURL, tt.expected) {
+				t.Errorf("short_url = %q, want prefix %q", resp.ShortURL, tt.expected)
+			}
+		})
+	}
+}
+
+func TestClientIP(t *testing.T) {
+	trusted, _ := utils.ParseTrustedProxies("10.0.0.0/8, 2001:db8::/32")
+
+	tests := []struct {
+		name       string
+		remoteAddr string
+		headers    map[string][]string
+		expected   string
+	}{
+		{
+			name: "Direct client", remoteAddr: "203.0.113.7:1234", expected: "203.0.113.7",
+		},
+		{
+			name: "Untrusted peer headers are ignored", remoteAddr: "203.0.113.7:1234",
+			headers:  map[string][]string{"X-Forwarded-For": {"198.51.100.1"}},
+			expected: "203.0.113.7",
+		},
+		{
+			name: "Trusted proxy without headers", remoteAddr: "10.0.0.1:1234", expected: "10.0.0.1",
+		},
+		{
+			name: "X-Forwarded-For from trusted proxy", remoteAddr: "10.0.0.1:1234",
+			headers:  map[string][]string{"X-Forwarded-For": {"198.51.100.1"}},
+			expected: "198.51.100.1",
+		},
+		{
+			name: "Spoofed left hops are skipped", remoteAddr: "10.0.0.1:1234",
+			headers:  map[string][]string{"X-Forwarded-For": {"1.2.3.4, 198.51.100.1, 10.0.0.2"}},
+			expected: "198.51.100.1",
+		},
+		{
+			name: "Repeated X-Forwarded-For headers", remoteAddr: "10.0.0.1:1234",
+			headers:  map[string][]string{"X-Forwarded-For": {"1.2.3.4", "198.51.100.1, 10.0.0.2"}},
+			expected: "198.51.100.1",
+		},
+		{
+			name: "All hops trusted", remoteAddr: "10.0.0.1:1234",
+			headers:  map[string][]string{"X-Forwarded-For": {"10.0.0.3, 10.0.0.2"}},
+			expected: "10.0.0.3",
+		},
+		{
+			name: "Forwarded wins over X-Forwarded-For", remoteAddr: "10.0.0.1:1234",
+			headers: map[string][]string{
+				"Forwarded":       {`for=1.2.3.4, for="[2001:db8::5]:443";proto=https, for=198.51.100.9`},
+				"X-Forwarded-For": {"198.51.100.1"},
+			},
+			expected: "198.51.100.9",
+		},
+		{
+			name: "Forwarded IPv6 behind trusted hop", remoteAddr: "10.0.0.1:1234",
+			headers:  map[string][]string{"Forwarded": {`for="[2001:db9::1]:443", for=10.0.0.2`}},
+			expected: "2001:db9::1",
+		},
+		{
+			name: "Obfuscated client", remoteAddr: "10.0.0.1:1234",
+			headers:  map[string][]string{"Forwarded": {"for=unknown"}},
+			expected: "<nil>",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.RemoteAddr = tt.remoteAddr
+			for k, values := range tt.headers {
+				for _, v := range values {
+					req.Header.Add(k, v)
+				}
+			}
+			if got := utils.ClientIP(req, trusted).String(); got != tt.expected {
+				t.Errorf("ClientIP() = %s, want %s", got, tt.expected)
+			}
+		})
+	}
+}
+
+// countryByIP - GeoIP для тестов: страна по точному адресу.
+type countryByIP map[string]string
+
+func (c countryByIP) Country(ip net.IP) string {
+	return c[ip.String()]
+}
+
+func TestGeoBehindProxy(t *testing.T) {
+	trusted, _ := utils.ParseTrustedProxies("10.0.0.0/8")
+	server := handlers.NewServer(testServer.DB)
+	server.TrustedProxies = trusted
+	server.GeoIP = countryByIP{"81.2.69.142": "GB", "10.0.0.1": "SE", "1.2.3.4": "GB"}
+
+	rr := doJSON(t, server.ShortenHandler, http.MethodPost, "/shorten", map[string]interface{}{
+		"url": "https://example.com", "alias": "geo_proxy",
+		"geo": map[string]string{"GB": "https://example.co.uk", "SE": "https://example.se"},
+	})
+	if rr.Code != http.StatusOK {
+		t.Fatalf("shorten returned %v: %s", rr.Code, rr.Body.String())
+	}
+
+	tests := []struct {
+		name         string
+		remoteAddr   string
+		forwardedFor string
+		expectedURL  string
+	}{
+		{"Client behind trusted proxy", "10.0.0.1:5000", "81.2.69.142", "https://example.co.uk"},
+		{"Proxy address is not the client", "10.0.0.1:5000", "198.51.100.1", "https://example.com"},
+		{"Spoofed hop before the proxy", "10.0.0.1:5000", "1.2.3.4, 198.51.100.1", "https://example.com"},
+		{"Untrusted peer cannot spoof", "198.51.100.1:5000", "81.2.69.142", "https://example.com"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/r/geo_proxy", nil)
+			req.RemoteAddr = tt.remoteAddr
+			req.Header.Set("X-Forwarded-For", tt.forwardedFor)
+			rr := httptest.NewRecorder()
+			server.RedirectHandler(rr, req)
+
+			if location := rr.Header().Get("Location"); location != tt.expectedURL {
+				t.Errorf("Location = %q, want %q", location, tt.expectedURL)
+			}
+		})
+	}
+}
@@ -0,0 +1,155 @@
+package tests
+
+import (
+	"bytes"
+	"errors"
+	"image/color"
+	"image/png"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"tinyurl/internal/db"
+	"tinyurl/internal/qr"
+)
+
+func TestQRRender(t *testing.T) {
+	opts := qr.DefaultOptions()
+	opts.Size = 300
+	opts.Margin = 2
+	opts.Foreground = color.NRGBA{R: 0x12, G: 0x34, B: 0x56, A: 0xff}
+
+	data, err := qr.Render("http://localhost:8080/r/abc", opts)
+	if err != nil {
+		t.Fatalf("Render failed: %v", err)
+	}
+
+	img, err := png.Decode(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("Failed to decode PNG: %v", err)
+	}
+
+	bounds := img.Bounds()
+	if bounds.Dx() != bounds.Dy() || bounds.Dx() > opts.Size || bounds.Dx() < opts.Size/2 {
+		t.Errorf("unexpected image size %dx%d for requested %d", bounds.Dx(), bounds.Dy(), opts.Size)
+	}
+
+	// Левый верхний угол - фон отступа, сразу за отступом начинается поисковый узор.
+	scale := bounds.Dx() / (25 + 2*opts.Margin)
+	if got := color.NRGBAModel.Convert(img.At(0, 0)); got != opts.Background {
+		t.Errorf("margin pixel is %v, expected background %v", got, opts.Background)
+	}
+	if got := color.NRGBAModel.Convert(img.At(opts.Margin*scale, opts.Margin*scale)); got != opts.Foreground {
+		t.Errorf("finder pattern pixel is %v, expected foreground %v", got, opts.Foreground)
+	}
+}
+
+func TestQRRenderTooSmall(t *testing.T) {
+	opts := qr.DefaultOptions()
+	opts.Size = qr.MinSize
+	opts.Margin = qr.MaxMargin
+	content := "http://localhost:8080/r/" + strings.Repeat("a", 200)
+
+	_, err := qr.Render(content, opts)
+	var sizeErr *qr.SizeError
+	if !errors.As(err, &sizeErr) || sizeErr.Min <= opts.Size {
+		t.Fatalf("Render returned %v, want *qr.SizeError", err)
+	}
+
+	opts.Size = sizeErr.Min
+	data, err := qr.Render(content, opts)
+	if err != nil {
+		t.Fatalf("Render at the minimal size failed: %v", err)
+	}
+	img, err := png.Decode(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("Failed to decode PNG: %v", err)
+	}
+	if side := img.Bounds().Dx(); side != sizeErr.Min {
+		t.Errorf("image side %d, want %d", side, sizeErr.Min)
+	}
+}
+
+func TestQRRenderSVG(t *testing.T) {
+	opts := qr.DefaultOptions()
+	opts.Format = qr.FormatSVG
+	opts.Background = color.NRGBA{R: 0xff, G: 0xee, B: 0xdd, A: 0x80}
+
+	data, err := qr.Render("http://localhost:8080/r/abc", opts)
+	if err != nil {
+		t.Fatalf("Render failed: %v", err)
+	}
+
+	svg := string(data)
+	for _, want := range []string{"<svg", `fill="#ffeedd"`, `fill-opacity="0.502"`, `fill="#000000"`, "</svg>"} {
+		if !strings.Contains(svg, want) {
+			t.Errorf("SVG does not contain %q", want)
+		}
+	}
+}
+
+func TestQRParseColor(t *testing.T) {
+	testCases := []struct {
+		input    string
+		expected color.NRGBA
+		valid    bool
+	}{
+		{"000000", color.NRGBA{A: 0xff}, true},
+		{"#ff8000", color.NRGBA{R: 0xff, G: 0x80, A: 0xff}, true},
+		{"ff800080", color.NRGBA{R: 0xff, G: 0x80, A: 0x80}, true},
+		{"fff", color.NRGBA{}, false},
+		{"zzzzzz", color.NRGBA{}, false},
+	}
+
+	for _, tc := range testCases {
+		got, err := qr.ParseColor(tc.input)
+		if tc.valid && (err != nil || got != tc.expected) {
+			t.Errorf("ParseColor(%q) returned %v, %v; expected %v", tc.input, got, err, tc.expected)
+		}
+		if !tc.valid && err == nil {
+			t.Errorf("ParseColor(%q) expected error", tc.input)
+		}
+	}
+}
+
+func TestQRHandler(t *testing.T) {
+	if err := db.InsertLink(testServer.DB, "qr_code", "https://example.com", 0); err != nil {
+		t.Fatalf("Failed to create test link: %v", err)
+	}
+
+	testCases := []struct {
+		name           string
+		target         string
+		expectedStatus int
+		expectedType   string
+	}{
+		{"Default PNG", "/qr/qr_code", http.StatusOK, "image/png"},
+		{"SVG with options", "/qr/qr_code?format=svg&size=512&level=H&fg=ff0000&bg=00000000&margin=0", http.StatusOK, "image/svg+xml"},
+		{"Unknown format", "/qr/qr_code?format=gif", http.StatusBadRequest, ""},
+		{"Size too large", "/qr/qr_code?size=10000", http.StatusBadRequest, ""},
+		{"Invalid level", "/qr/qr_code?level=X", http.StatusBadRequest, ""},
+		{"Invalid color", "/qr/qr_code?fg=red", http.StatusBadRequest, ""},
+		{"Size too small for code", "/qr/qr_code?size=64&margin=16&level=H", http.StatusBadRequest, ""},
+		{"Nonexistent code", "/qr/nonexistent", http.StatusNotFound, ""},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, tc.target, nil)
+			rr := httptest.NewRecorder()
+			http.HandlerFunc(testServer.QRHandler).ServeHTTP(rr, req)
+
+			if rr.Code != tc.expectedStatus {
+				t.Errorf("handler returned wrong status code: got %v want %v", rr.Code, tc.expectedStatus)
+			}
+			if tc.expectedType != "" && rr.Header().Get("Content-Type") != tc.expectedType {
+				t.Errorf("handler returned wrong content type: got %v want %v",
+					rr.Header().Get("Content-Type"), tc.expectedType)
+			}
+			if rr.Code == http.StatusOK && rr.Header().Get("Cache-Control") != "private, max-age=300" {
+				t.Errorf("Cache-Control = %q", rr.Header().Get("Cache-Control"))
+			}
+		})
+	}
+}
@@ -0,0 +1,131 @@
+package tests
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"tinyurl/internal/models"
+	"tinyurl/internal/useragent"
+)
+
+const (
+	uaIPhone  = "Mozilla/5.0 (iPhone; CPU iPhone OS 17_0 like Mac OS X) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/17.0 Mobile/15E148 Safari/604.1"
+	uaIPad    = "Mozilla/5.0 (iPad; CPU OS 17_0 like Mac OS X) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/17.0 Mobile/15E148 Safari/604.1"
+	uaAndroid = "Mozilla/5.0 (Linux; Android 14; Pixel 8) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0 Mobile Safari/537.36"
+	uaTablet  = "Mozilla/5.0 (Linux; Android 13; SM-X700) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0 Safari/537.36"
+	uaWindows = "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0 Safari/537.36"
+	uaMac     = "Mozilla/5.0 (Macintosh; Intel Mac OS X 14_0) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/17.0 Safari/605.1.15"
+	uaGoogle  = "Mozilla/5.0 (compatible; Googlebot/2.1; +http://www.google.com/bot.html)"
+)
+
+func TestParseUserAgent(t *testing.T) {
+	testCases := []struct {
+		name     string
+		ua       string
+		expected useragent.Info
+	}{
+		{"iPhone", uaIPhone, useragent.Info{OS: useragent.OSIOS, Device: useragent.DeviceMobile}},
+		{"iPad", uaIPad, useragent.Info{OS: useragent.OSIOS, Device: useragent.DeviceTablet}},
+		{"Android phone", uaAndroid, useragent.Info{OS: useragent.OSAndroid, Device: useragent.DeviceMobile}},
+		{"Android tablet", uaTablet, useragent.Info{OS: useragent.OSAndroid, Device: useragent.DeviceTablet}},
+		{"Windows", uaWindows, useragent.Info{OS: useragent.OSWindows, Device: useragent.DeviceDesktop}},
+		{"macOS", uaMac, useragent.Info{OS: useragent.OSMacOS, Device: useragent.DeviceDesktop}},
+		{"Googlebot", uaGoogle, useragent.Info{OS: useragent.OSOther, Device: useragent.DeviceDesktop, Bot: true}},
+		{"Empty", "", useragent.Info{OS: useragent.OSOther, Device: useragent.DeviceDesktop, Bot: true}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := useragent.Parse(tc.ua); got != tc.expected {
+				t.Errorf("Parse() returned %+v, expected %+v", got, tc.expected)
+			}
+		})
+	}
+}
+
+func TestRedirectHandlerTargetRules(t *testing.T) {
+	notBot := false
+	rr := doJSON(t, testServer.ShortenHandler, http.MethodPost, "/shorten", map[string]interface{}{
+		"url":   "https://example.com",
+		"alias": "app_link",
+		"rules": []map[string]interface{}{
+			{"name": "ios", "os": "ios", "url": "https://apps.apple.com/app/id1"},
+			{"name": "android", "os": "android", "bot": notBot, "url": "https://play.google.com/store/apps/details?id=app"},
+			{"os": "windows", "device": "mobile", "url": "https://example.com/never"},
+		},
+	})
+	if rr.Code != http.StatusOK {
+		t.Fatalf("shorten returned %v: %s", rr.Code, rr.Body.String())
+	}
+
+	testCases := []struct {
+		name        string
+		ua          string
+		expectedURL string
+	}{
+		{"iPhone", uaIPhone, "https://apps.apple.com/app/id1"},
+		{"iPad", uaIPad, "https://apps.apple.com/app/id1"},
+		{"Android", uaAndroid, "https://play.google.com/store/apps/details?id=app"},
+		{"Desktop", uaWindows, "https://example.com"},
+		{"Bot", uaGoogle, "https://example.com"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/r/app_link", nil)
+			req.Header.Set("User-Agent", tc.ua)
+			rr := httptest.NewRecorder()
+			http.HandlerFunc(testServer.RedirectHandler).ServeHTTP(rr, req)
+
+			if location := rr.Header().Get("Location"); location != tc.expectedURL {
+				t.Errorf("handler returned wrong location: got %v want %v", location, tc.expectedURL)
+			}
+		})
+	}
+
+	var stats models.StatsResponse
+	waitFor(t, func() bool {
+		rr := doJSON(t, testServer.StatsHandler, http.MethodGet, "/stats/app_link", nil)
+		if err := json.Unmarshal(rr.Body.Bytes(), &stats); err != nil {
+			return false
+		}
+		return stats.Breakdown["rule"]["default"] == 2
+	})
+
+	if stats.Breakdown["rule"]["ios"] != 2 || stats.Breakdown["rule"]["android"] != 1 {
+		t.Errorf("unexpected rule breakdown: %v", stats.Breakdown["rule"])
+	}
+	if len(stats.Rules) != 3 || stats.Rules[2].Name != "rule_3" {
+		t.Errorf("unexpected rules in stats: %+v", stats.Rules)
+	}
+}
+
+func TestShortenHandlerInvalidRules(t *testing.T) {
+	testCases := []struct {
+		name  string
+		rules []map[string]interface{}
+	}{
+		{"Missing URL", []map[string]interface{}{{"os": "ios"}}},
+		{"Unknown OS", []map[string]interface{}{{"os": "symbian", "url": "https://example.com"}}},
+		{"Unknown device", []map[string]interface{}{{"device": "watch", "url": "https://example.com"}}},
+		{"Duplicate name", []map[string]interface{}{
+			{"name": "a", "url": "https://example.com"},
+			{"name": "a", "url": "https://example.org"},
+		}},
+		{"Reserved name", []map[string]interface{}{{"name": "default", "url": "https://example.com"}}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			rr := doJSON(t, testServer.ShortenHandler, http.MethodPost, "/shorten", map[string]interface{}{
+				"url":   "https://example.com",
+				"rules": tc.rules,
+			})
+			if rr.Code != http.StatusBadRequest {
+				t.Errorf("handler returned wrong status code: got %v want %v", rr.Code, http.StatusBadRequest)
+			}
+		})
+	}
+}
@@ -0,0 +1,142 @@
+package tests
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+
+	"tinyurl/internal/tracing"
+)
+
+func setupTestTracing(t *testing.T) *tracetest.InMemoryExporter {
+	t.Helper()
+
+	exporter := tracetest.NewInMemoryExporter()
+	provider := tracing.NewProvider(sdktrace.WithSyncer(exporter))
+
+	prevProvider, prevPropagator := otel.GetTracerProvider(), otel.GetTextMapPropagator()
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	t.Cleanup(func() {
+		provider.Shutdown(t.Context())
+		otel.SetTracerProvider(prevProvider)
+		otel.SetTextMapPropagator(prevPropagator)
+	})
+
+	return exporter
+}
+
+func spanAttr(span tracetest.SpanStub, key attribute.Key) attribute.Value {
+	for _, kv := range span.Attributes {
+		if kv.Key == key {
+			return kv.Value
+		}
+	}
+	return attribute.Value{}
+}
+
+func findSpan(spans tracetest.SpanStubs, name string) (tracetest.SpanStub, bool) {
+	for _, span := range spans {
+		if span.Name == name {
+			return span, true
+		}
+	}
+	return tracetest.SpanStub{}, false
+}
+
+func TestRedirectTracing(t *testing.T) {
+	exporter := setupTestTracing(t)
+
+	rr := doJSON(t, testServer.ShortenHandler, http.MethodPost, "/shorten", map[string]interface{}{
+		"url": "https://example.com/traced", "alias": "traced-link",
+	})
+	if rr.Code != http.StatusOK {
+		t.Fatalf("shorten returned %v: %s", rr.Code, rr.Body.String())
+	}
+	exporter.Reset()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/r/", testServer.RedirectHandler)
+	handler := tracing.Middleware(mux, mux)
+
+	const (
+		traceID      = "4bf92f3577b34da6a3ce929d0e0e4736"
+		parentSpanID = "00f067aa0ba902b7"
+	)
+	req := httptest.NewRequest(http.MethodGet, "/r/traced-link", nil)
+	req.Header.Set("traceparent", "00-"+traceID+"-"+parentSpanID+"-01")
+	rr = httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+	if rr.Code != http.StatusFound {
+		t.Fatalf("redirect returned %v", rr.Code)
+	}
+
+	waitFor(t, func() bool {
+		_, ok := findSpan(exporter.GetSpans(), "db increment_hit_count")
+		return ok
+	})
+	spans := exporter.GetSpans()
+
+	server, ok := findSpan(spans, "GET /r/")
+	if !ok {
+		t.Fatalf("no server span in %d spans", len(spans))
+	}
+	if got := server.SpanContext.TraceID().String(); got != traceID {
+		t.Errorf("trace id = %s, want %s", got, traceID)
+	}
+	if got := server.Parent.SpanID().String(); got != parentSpanID {
+		t.Errorf("parent span id = %s, want %s", got, parentSpanID)
+	}
+	if got := spanAttr(server, tracing.AttrCode).AsString(); got != "traced-link" {
+		t.Errorf("tinyurl.code = %q", got)
+	}
+	if got := spanAttr(server, tracing.AttrOutcome).AsString(); got != "redirect" {
+		t.Errorf("tinyurl.outcome = %q", got)
+	}
+	if got := spanAttr(server, "http.response.status_code").AsInt64(); got != http.StatusFound {
+		t.Errorf("http.response.status_code = %d", got)
+	}
+
+	for _, name := range []string{"db get_domain", "db get_link", "db increment_hit_count"} {
+		span, ok := findSpan(spans, name)
+		if !ok {
+			t.Errorf("no %q span", name)
+			continue
+		}
+		if span.Parent.SpanID() != server.SpanContext.SpanID() {
+			t.Errorf("%q is not a child of the server span", name)
+		}
+		if got := spanAttr(span, "db.system.name").AsString(); got != "sqlite" {
+			t.Errorf("%q db.system.name = %q", name, got)
+		}
+	}
+}
+
+func TestTracingMarksServerErrors(t *testing.T) {
+	exporter := setupTestTracing(t)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/fail", func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "boom", http.StatusInternalServerError)
+	})
+	tracing.Middleware(mux, mux).ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/fail", nil))
+
+	spans := exporter.GetSpans()
+	if len(spans) != 1 {
+		t.Fatalf("got %d spans, want 1", len(spans))
+	}
+	if spans[0].Status.Code != codes.Error {
+		t.Errorf("status = %v, want Error", spans[0].Status.Code)
+	}
+	if !strings.HasPrefix(spans[0].Name, "GET /fail") {
+		t.Errorf("span name = %q", spans[0].Name)
+	}
+}
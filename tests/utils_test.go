@@ -71,3 +71,45 @@ func TestGetHost(t *testing.T) {
 		})
 	}
 }
+
+func TestAppendPassthrough(t *testing.T) {
+	testCases := []struct {
+		name        string
+		destination string
+		path        string
+		query       string
+		expected    string
+	}{
+		{"Nothing to append", "https://example.com/landing", "", "", "https://example.com/landing"},
+		{"Query only", "https://example.com/landing", "", "utm_source=x", "https://example.com/landing?utm_source=x"},
+		{"Query merged", "https://example.com/?a=1", "", "b=2", "https://example.com/?a=1&b=2"},
+		{"Destination wins", "https://example.com/?utm_source=site", "", "utm_source=x&ref=y", "https://example.com/?utm_source=site&ref=y"},
+		{"Repeated params kept", "https://example.com/", "", "tag=a&tag=b", "https://example.com/?tag=a&tag=b"},
+		{"Path appended", "https://example.com/docs", "/extra/path", "", "https://example.com/docs/extra/path"},
+		{"Path with trailing slash on destination", "https://example.com/docs/", "/page", "", "https://example.com/docs/page"},
+		{"Path keeps trailing slash", "https://example.com", "/dir/", "", "https://example.com/dir/"},
+		{"Encoded path segment", "https://example.com/files", "/a%2Fb/c%20d", "", "https://example.com/files/a%2Fb/c%20d"},
+		{"Unicode query value", "https://example.com/", "", "q=%D0%BF%D1%80%D0%B8%D0%B2%D0%B5%D1%82", "https://example.com/?q=%D0%BF%D1%80%D0%B8%D0%B2%D0%B5%D1%82"},
+		{"Plus and ampersand encoding", "https://example.com/", "", "q=a+b%26c", "https://example.com/?q=a+b%26c"},
+		{"Path and query", "https://example.com/base?x=1", "/sub", "y=2", "https://example.com/base/sub?x=1&y=2"},
+		{"Fragment preserved", "https://example.com/page#top", "", "a=1", "https://example.com/page?a=1#top"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := utils.AppendPassthrough(tc.destination, tc.path, tc.query)
+			if err != nil {
+				t.Fatalf("AppendPassthrough returned error: %v", err)
+			}
+			if got != tc.expected {
+				t.Errorf("AppendPassthrough() returned %s, expected %s", got, tc.expected)
+			}
+		})
+	}
+}
+
+func TestAppendPassthroughInvalidQuery(t *testing.T) {
+	if _, err := utils.AppendPassthrough("https://example.com/", "", "a=%zz"); err == nil {
+		t.Error("Expected error for malformed query string")
+	}
+}
@@ -0,0 +1,207 @@
+package tests
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"tinyurl/internal/db"
+	"tinyurl/internal/models"
+)
+
+func doJSON(t *testing.T, handler http.HandlerFunc, method, target string, body interface{}) *httptest.ResponseRecorder {
+	t.Helper()
+
+	var reader *bytes.Reader
+	if body != nil {
+		jsonBody, _ := json.Marshal(body)
+		reader = bytes.NewReader(jsonBody)
+	} else {
+		reader = bytes.NewReader(nil)
+	}
+
+	req := httptest.NewRequest(method, target, reader)
+	req.Header.Set("Content-Type", "application/json")
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+	return rr
+}
+
+func waitFor(t *testing.T, cond func() bool) {
+	t.Helper()
+
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		if cond() {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatal("condition not met before timeout")
+}
+
+func TestUTMTemplatesCRUD(t *testing.T) {
+	template := map[string]interface{}{
+		"name":         "crud",
+		"utm_source":   "newsletter",
+		"utm_medium":   "email",
+		"utm_campaign": "spring",
+	}
+
+	rr := doJSON(t, testServer.UTMTemplatesHandler, http.MethodPost, "/utm-templates", template)
+	if rr.Code != http.StatusCreated {
+		t.Fatalf("create returned %v: %s", rr.Code, rr.Body.String())
+	}
+
+	rr = doJSON(t, testServer.UTMTemplatesHandler, http.MethodPost, "/utm-templates", template)
+	if rr.Code != http.StatusConflict {
+		t.Errorf("duplicate create returned %v, want %v", rr.Code, http.StatusConflict)
+	}
+
+	rr = doJSON(t, testServer.UTMTemplatesHandler, http.MethodPost, "/utm-templates",
+		map[string]interface{}{"name": "incomplete", "utm_source": "x"})
+	if rr.Code != http.StatusBadRequest {
+		t.Errorf("incomplete create returned %v, want %v", rr.Code, http.StatusBadRequest)
+	}
+
+	template["utm_campaign"] = "summer"
+	rr = doJSON(t, testServer.UTMTemplateHandler, http.MethodPut, "/utm-templates/crud", template)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("update returned %v: %s", rr.Code, rr.Body.String())
+	}
+
+	rr = doJSON(t, testServer.UTMTemplateHandler, http.MethodGet, "/utm-templates/crud", nil)
+	var got models.UTMTemplate
+	if err := json.Unmarshal(rr.Body.Bytes(), &got); err != nil {
+		t.Fatalf("Failed to decode response body: %v", err)
+	}
+	if got.Campaign != "summer" {
+		t.Errorf("Expected campaign summer, got %s", got.Campaign)
+	}
+
+	rr = doJSON(t, testServer.UTMTemplatesHandler, http.MethodGet, "/utm-templates", nil)
+	var list []models.UTMTemplate
+	if err := json.Unmarshal(rr.Body.Bytes(), &list); err != nil {
+		t.Fatalf("Failed to decode response body: %v", err)
+	}
+	if len(list) == 0 {
+		t.Error("Expected at least one template in list")
+	}
+
+	rr = doJSON(t, testServer.UTMTemplateHandler, http.MethodDelete, "/utm-templates/crud", nil)
+	if rr.Code != http.StatusNoContent {
+		t.Errorf("delete returned %v, want %v", rr.Code, http.StatusNoContent)
+	}
+
+	rr = doJSON(t, testServer.UTMTemplateHandler, http.MethodGet, "/utm-templates/crud", nil)
+	if rr.Code != http.StatusNotFound {
+		t.Errorf("get after delete returned %v, want %v", rr.Code, http.StatusNotFound)
+	}
+}
+
+func TestShortenWithUTMTemplate(t *testing.T) {
+	rr := doJSON(t, testServer.UTMTemplatesHandler, http.MethodPost, "/utm-templates", map[string]interface{}{
+		"name":         "promo",
+		"utm_source":   "poster",
+		"utm_medium":   "offline",
+		"utm_campaign": "launch",
+	})
+	if rr.Code != http.StatusCreated {
+		t.Fatalf("create template returned %v: %s", rr.Code, rr.Body.String())
+	}
+
+	testCases := []struct {
+		name        string
+		alias       string
+		apply       string
+		expectedURL string
+	}{
+		{"Applied at creation", "utm_create", "", "https://example.com/?utm_campaign=launch&utm_medium=offline&utm_source=poster"},
+		{"Applied at redirect", "utm_redirect", "redirect", "https://example.com/?utm_campaign=launch&utm_medium=offline&utm_source=poster"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			body := map[string]interface{}{
+				"url":          "https://example.com/?utm_source=old",
+				"alias":        tc.alias,
+				"utm_template": "promo",
+			}
+			if tc.apply != "" {
+				body["utm_apply"] = tc.apply
+			}
+			rr := doJSON(t, testServer.ShortenHandler, http.MethodPost, "/shorten", body)
+			if rr.Code != http.StatusOK {
+				t.Fatalf("shorten returned %v: %s", rr.Code, rr.Body.String())
+			}
+
+			rr = doJSON(t, testServer.RedirectHandler, http.MethodGet, "/r/"+tc.alias, nil)
+			if location := rr.Header().Get("Location"); location != tc.expectedURL {
+				t.Errorf("handler returned wrong location: got %v want %v", location, tc.expectedURL)
+			}
+		})
+	}
+
+	waitFor(t, func() bool {
+		rr := doJSON(t, testServer.StatsHandler, http.MethodGet, "/stats/?group_by=campaign", nil)
+		var stats []models.CampaignStats
+		if err := json.Unmarshal(rr.Body.Bytes(), &stats); err != nil {
+			return false
+		}
+		for _, c := range stats {
+			if c.Campaign == "launch" && c.HitCount == 2 && c.Links == 2 {
+				return true
+			}
+		}
+		return false
+	})
+
+	rr = doJSON(t, testServer.StatsHandler, http.MethodGet, "/stats/utm_redirect", nil)
+	var stats models.StatsResponse
+	if err := json.Unmarshal(rr.Body.Bytes(), &stats); err != nil {
+		t.Fatalf("Failed to decode response body: %v", err)
+	}
+	if stats.UTMTemplate != "promo" {
+		t.Errorf("Expected utm_template promo, got %s", stats.UTMTemplate)
+	}
+	if stats.Breakdown["campaign"]["launch"] != 1 {
+		t.Errorf("Expected one click for campaign launch, got %v", stats.Breakdown)
+	}
+
+	rr = doJSON(t, testServer.UTMTemplateHandler, http.MethodDelete, "/utm-templates/promo", nil)
+	if rr.Code != http.StatusConflict {
+		t.Errorf("delete of used template returned %v, want %v", rr.Code, http.StatusConflict)
+	}
+}
+
+func TestShortenWithUnknownUTMTemplate(t *testing.T) {
+	rr := doJSON(t, testServer.ShortenHandler, http.MethodPost, "/shorten", map[string]interface{}{
+		"url":          "https://example.com",
+		"utm_template": "missing",
+	})
+	if rr.Code != http.StatusBadRequest {
+		t.Errorf("handler returned wrong status code: got %v want %v", rr.Code, http.StatusBadRequest)
+	}
+}
+
+func TestPassthroughCampaign(t *testing.T) {
+	if err := db.InsertLinkWithOptions(context.Background(), testServer.DB, "pt_campaign", "https://example.com/landing", 0,
+		models.LinkOptions{Passthrough: true}); err != nil {
+		t.Fatalf("Failed to create test link: %v", err)
+	}
+
+	rr := doJSON(t, testServer.RedirectHandler, http.MethodGet, "/r/pt_campaign?utm_campaign=forwarded", nil)
+	if location := rr.Header().Get("Location"); location != "https://example.com/landing?utm_campaign=forwarded" {
+		t.Fatalf("handler returned wrong location: %v", location)
+	}
+
+	waitFor(t, func() bool {
+		rr := doJSON(t, testServer.StatsHandler, http.MethodGet, "/stats/pt_campaign", nil)
+		var stats models.StatsResponse
+		return json.Unmarshal(rr.Body.Bytes(), &stats) == nil && stats.Breakdown["campaign"]["forwarded"] == 1
+	})
+}
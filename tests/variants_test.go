@@ -0,0 +1,119 @@
+package tests
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"tinyurl/internal/models"
+	"tinyurl/internal/utils"
+)
+
+func TestWeightedIndex(t *testing.T) {
+	weights := []int{1, 3, 6}
+	testCases := []struct {
+		roll     int
+		expected int
+	}{
+		{0, 0},
+		{1, 1},
+		{3, 1},
+		{4, 2},
+		{9, 2},
+	}
+
+	for _, tc := range testCases {
+		if got := utils.WeightedIndex(weights, tc.roll); got != tc.expected {
+			t.Errorf("WeightedIndex(%v, %d) returned %d, expected %d", weights, tc.roll, got, tc.expected)
+		}
+	}
+}
+
+func TestRedirectHandlerVariants(t *testing.T) {
+	rr := doJSON(t, testServer.ShortenHandler, http.MethodPost, "/shorten", map[string]interface{}{
+		"url":    "https://example.com",
+		"alias":  "ab_test",
+		"sticky": true,
+		"variants": []map[string]interface{}{
+			{"name": "a", "url": "https://example.com/a", "weight": 1},
+			{"name": "b", "url": "https://example.com/b", "weight": 1},
+		},
+	})
+	if rr.Code != http.StatusOK {
+		t.Fatalf("shorten returned %v: %s", rr.Code, rr.Body.String())
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/r/ab_test", nil)
+	rr = httptest.NewRecorder()
+	http.HandlerFunc(testServer.RedirectHandler).ServeHTTP(rr, req)
+
+	location := rr.Header().Get("Location")
+	if location != "https://example.com/a" && location != "https://example.com/b" {
+		t.Fatalf("handler returned unexpected location: %v", location)
+	}
+	cookies := rr.Result().Cookies()
+	if len(cookies) != 1 || cookies[0].Name != "tinyurl_v_ab_test" {
+		t.Fatalf("expected sticky cookie, got %v", cookies)
+	}
+
+	for i := 0; i < 5; i++ {
+		req := httptest.NewRequest(http.MethodGet, "/r/ab_test", nil)
+		req.AddCookie(&http.Cookie{Name: "tinyurl_v_ab_test", Value: "b"})
+		rr := httptest.NewRecorder()
+		http.HandlerFunc(testServer.RedirectHandler).ServeHTTP(rr, req)
+
+		if got := rr.Header().Get("Location"); got != "https://example.com/b" {
+			t.Errorf("sticky visitor got %v, want https://example.com/b", got)
+		}
+		if len(rr.Result().Cookies()) != 0 {
+			t.Error("sticky visitor should not get a new cookie")
+		}
+	}
+
+	var stats models.StatsResponse
+	waitFor(t, func() bool {
+		rr := doJSON(t, testServer.StatsHandler, http.MethodGet, "/stats/ab_test", nil)
+		if err := json.Unmarshal(rr.Body.Bytes(), &stats); err != nil {
+			return false
+		}
+		return stats.HitCount == 6
+	})
+
+	variants := stats.Breakdown["variant"]
+	if variants["a"]+variants["b"] != 6 || variants["b"] < 5 {
+		t.Errorf("unexpected variant breakdown: %v", variants)
+	}
+	if !stats.Sticky || len(stats.Variants) != 2 {
+		t.Errorf("unexpected variants in stats: %+v", stats.Variants)
+	}
+}
+
+func TestShortenHandlerInvalidVariants(t *testing.T) {
+	testCases := []struct {
+		name string
+		body map[string]interface{}
+	}{
+		{"Zero weight", map[string]interface{}{
+			"url":      "https://example.com",
+			"variants": []map[string]interface{}{{"url": "https://example.com/a", "weight": 0}},
+		}},
+		{"Missing URL", map[string]interface{}{
+			"url":      "https://example.com",
+			"variants": []map[string]interface{}{{"weight": 1}},
+		}},
+		{"Sticky without variants", map[string]interface{}{
+			"url":    "https://example.com",
+			"sticky": true,
+		}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			rr := doJSON(t, testServer.ShortenHandler, http.MethodPost, "/shorten", tc.body)
+			if rr.Code != http.StatusBadRequest {
+				t.Errorf("handler returned wrong status code: got %v want %v", rr.Code, http.StatusBadRequest)
+			}
+		})
+	}
+}
@@ -0,0 +1,654 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"tinyurl/internal/apierror"
+	"tinyurl/internal/db"
+	"tinyurl/internal/handlers"
+	"tinyurl/internal/models"
+	"tinyurl/internal/webhooks"
+)
+
+const receiverSecret = "whsec_test_receiver_secret"
+
+// receiver - получатель вебхуков, проверяющий подпись каждого запроса.
+type receiver struct {
+	t      *testing.T
+	server *httptest.Server
+
+	mu     sync.Mutex
+	status int
+	events []webhooks.Event
+}
+
+func newReceiver(t *testing.T) *receiver {
+	t.Helper()
+
+	rc := &receiver{t: t, status: http.StatusOK}
+	rc.server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		timestamp, err := strconv.ParseInt(r.Header.Get(webhooks.HeaderTimestamp), 10, 64)
+		if err != nil || !webhooks.Verify(receiverSecret, timestamp, body, r.Header.Get(webhooks.HeaderSignature)) {
+			t.Errorf("bad signature %q for %s", r.Header.Get(webhooks.HeaderSignature), body)
+		}
+
+		var event webhooks.Event
+		if err := json.Unmarshal(body, &event); err != nil {
+			t.Errorf("bad payload %s: %v", body, err)
+		}
+		if r.Header.Get(webhooks.HeaderEvent) != event.Type || r.Header.Get(webhooks.HeaderDelivery) == "" {
+			t.Errorf("headers = %v for event %s", r.Header, event.Type)
+		}
+
+		rc.mu.Lock()
+		defer rc.mu.Unlock()
+		if rc.status == http.StatusOK {
+			rc.events = append(rc.events, event)
+		}
+		w.WriteHeader(rc.status)
+	}))
+	t.Cleanup(rc.server.Close)
+
+	return rc
+}
+
+func (rc *receiver) respond(status int) {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	rc.status = status
+}
+
+func (rc *receiver) received() []webhooks.Event {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	return append([]webhooks.Event(nil), rc.events...)
+}
+
+// newWebhookServer возвращает сервер с диспетчером без пауз между попытками и его
+// маршруты /api/v1. Получатели в тестах слушают 127.0.0.1, поэтому внутренние
+// адреса разрешены.
+func newWebhookServer(t *testing.T) (*handlers.Server, *webhooks.Dispatcher, *http.ServeMux) {
+	t.Helper()
+
+	dispatcher := webhooks.NewDispatcher(testServer.DB)
+	dispatcher.BaseDelay = 0
+	dispatcher.MaxAttempts = 2
+	dispatcher.AllowPrivate = true
+
+	server := handlers.NewServer(testServer.DB)
+	server.Events = dispatcher
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/r/", server.RedirectHandler)
+	server.RegisterAPI(mux)
+
+	return server, dispatcher, mux
+}
+
+func serveAPI(t *testing.T, mux *http.ServeMux, method, target string, body interface{}) *httptest.ResponseRecorder {
+	t.Helper()
+	return doJSON(t, mux.ServeHTTP, method, handlers.APIPrefix+target, body)
+}
+
+// subscribe создает вебхук на события получателя и удаляет его по завершении теста.
+func subscribe(t *testing.T, mux *http.ServeMux, rc *receiver, events ...string) models.Webhook {
+	t.Helper()
+
+	rr := serveAPI(t, mux, http.MethodPost, "/webhooks", map[string]interface{}{
+		"url": rc.server.URL, "events": events, "secret": receiverSecret,
+	})
+	if rr.Code != http.StatusCreated {
+		t.Fatalf("create webhook returned %v: %s", rr.Code, rr.Body.String())
+	}
+	var hook models.Webhook
+	if err := json.NewDecoder(rr.Body).Decode(&hook); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.DeleteWebhook(context.Background(), testServer.DB, hook.ID) })
+
+	return hook
+}
+
+func listDeliveries(t *testing.T, mux *http.ServeMux, hook models.Webhook, status string) []models.WebhookDelivery {
+	t.Helper()
+
+	rr := serveAPI(t, mux, http.MethodGet, fmt.Sprintf("/webhooks/%d/deliveries?status=%s", hook.ID, status), nil)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("deliveries returned %v: %s", rr.Code, rr.Body.String())
+	}
+	var deliveries []models.WebhookDelivery
+	if err := json.NewDecoder(rr.Body).Decode(&deliveries); err != nil {
+		t.Fatal(err)
+	}
+	return deliveries
+}
+
+func deliverAll(t *testing.T, dispatcher *webhooks.Dispatcher) int {
+	t.Helper()
+
+	sent, err := dispatcher.DeliverDue(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return sent
+}
+
+func TestWebhookLinkLifecycle(t *testing.T) {
+	server, dispatcher, mux := newWebhookServer(t)
+	rc := newReceiver(t)
+	hook := subscribe(t, mux, rc, webhooks.LinkCreated, webhooks.LinkUpdated, webhooks.LinkClicked, webhooks.LinkDeleted)
+	ctx := context.Background()
+
+	domain, code, err := server.CreateLink(ctx, models.ShortenRequest{URL: "https://example.com/hook", Alias: "hook-link"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if deliverAll(t, dispatcher) != 1 {
+		t.Fatal("link.created was not delivered")
+	}
+
+	rr := httptest.NewRecorder()
+	mux.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/r/"+code, nil))
+	if rr.Code != http.StatusFound {
+		t.Fatalf("redirect returned %v", rr.Code)
+	}
+	waitFor(t, func() bool { return deliverAll(t, dispatcher) == 1 })
+
+	url := "https://example.com/hook-updated"
+	if _, err := server.UpdateLink(ctx, domain, code, models.LinkUpdate{URL: &url}); err != nil {
+		t.Fatal(err)
+	}
+	if err := server.DeleteLink(ctx, domain, code); err != nil {
+		t.Fatal(err)
+	}
+	deliverAll(t, dispatcher)
+
+	events := rc.received()
+	want := []struct {
+		event    string
+		url      string
+		hitCount int64
+	}{
+		{webhooks.LinkCreated, "https://example.com/hook", 0},
+		{webhooks.LinkClicked, "https://example.com/hook", 1},
+		{webhooks.LinkUpdated, url, 1},
+		{webhooks.LinkDeleted, url, 1},
+	}
+	if len(events) != len(want) {
+		t.Fatalf("received %d events, want %d: %+v", len(events), len(want), events)
+	}
+	for i, w := range want {
+		e := events[i]
+		if e.Type != w.event || e.Data.Code != "hook-link" || e.Data.URL != w.url || e.Data.HitCount != w.hitCount {
+			t.Errorf("event %d = %+v, want %s", i, e, w.event)
+		}
+		if e.ID == "" || e.CreatedAt.IsZero() {
+			t.Errorf("event %d has no id or time: %+v", i, e)
+		}
+	}
+
+	deliveries := listDeliveries(t, mux, hook, models.DeliveryDelivered)
+	if len(deliveries) != len(want) {
+		t.Fatalf("delivered %d, want %d", len(deliveries), len(want))
+	}
+	for _, d := range deliveries {
+		if d.Attempts != 1 || len(d.Log) != 1 || d.Log[0].StatusCode != http.StatusOK || d.NextAttemptAt != nil {
+			t.Errorf("delivery = %+v", d)
+		}
+	}
+}
+
+func TestWebhookRetriesAndDeadLetter(t *testing.T) {
+	server, dispatcher, mux := newWebhookServer(t)
+	rc := newReceiver(t)
+	hook := subscribe(t, mux, rc, webhooks.LinkCreated)
+
+	rc.respond(http.StatusServiceUnavailable)
+	if _, _, err := server.CreateLink(context.Background(), models.ShortenRequest{URL: "https://example.com/dead", Alias: "hook-dead"}); err != nil {
+		t.Fatal(err)
+	}
+
+	deliverAll(t, dispatcher)
+	pending := listDeliveries(t, mux, hook, models.DeliveryPending)
+	if len(pending) != 1 || pending[0].Attempts != 1 || pending[0].NextAttemptAt == nil || pending[0].LastStatusCode != http.StatusServiceUnavailable {
+		t.Fatalf("after first attempt: %+v", pending)
+	}
+
+	deliverAll(t, dispatcher)
+	dead := listDeliveries(t, mux, hook, models.DeliveryDead)
+	if len(dead) != 1 || dead[0].Attempts != 2 || len(dead[0].Log) != 2 || dead[0].NextAttemptAt != nil {
+		t.Fatalf("after last attempt: %+v", dead)
+	}
+	for i, a := range dead[0].Log {
+		if a.Attempt != i+1 || a.StatusCode != http.StatusServiceUnavailable || a.Error == "" {
+			t.Errorf("log[%d] = %+v", i, a)
+		}
+	}
+	if deliverAll(t, dispatcher) != 0 {
+		t.Error("dead delivery was retried automatically")
+	}
+
+	rc.respond(http.StatusOK)
+	rr := serveAPI(t, mux, http.MethodPost, fmt.Sprintf("/webhooks/%d/deliveries/%d/retry", hook.ID, dead[0].ID), nil)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("retry returned %v: %s", rr.Code, rr.Body.String())
+	}
+	var retried models.WebhookDelivery
+	if err := json.NewDecoder(rr.Body).Decode(&retried); err != nil {
+		t.Fatal(err)
+	}
+	if retried.Status != models.DeliveryPending || retried.Attempts != 0 {
+		t.Errorf("retried = %+v", retried)
+	}
+
+	deliverAll(t, dispatcher)
+	delivered := listDeliveries(t, mux, hook, models.DeliveryDelivered)
+	if len(delivered) != 1 || len(delivered[0].Log) != 3 || delivered[0].LastError != "" {
+		t.Fatalf("after retry: %+v", delivered)
+	}
+	if events := rc.received(); len(events) != 1 || events[0].Data.Code != "hook-dead" {
+		t.Errorf("received %+v", events)
+	}
+}
+
+func TestWebhookExpiredLinks(t *testing.T) {
+	server, dispatcher, mux := newWebhookServer(t)
+	rc := newReceiver(t)
+	ctx := context.Background()
+
+	expire := func(code string, at time.Time) {
+		t.Helper()
+		link, err := db.GetLinkInDomain(ctx, testServer.DB, db.DefaultDomainID, code)
+		if err != nil || link == nil {
+			t.Fatalf("link %s: %v", code, err)
+		}
+		link.ExpiresAt = &at
+		if err := db.UpdateLink(ctx, testServer.DB, link); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Ссылка, истекшая до подписки, не должна попасть к новому подписчику.
+	if _, _, err := server.CreateLink(ctx, models.ShortenRequest{URL: "https://example.com/old", Alias: "hook-expired-old"}); err != nil {
+		t.Fatal(err)
+	}
+	expire("hook-expired-old", time.Now().Add(-time.Hour))
+
+	subscribe(t, mux, rc, webhooks.LinkExpired)
+	if _, _, err := server.CreateLink(ctx, models.ShortenRequest{URL: "https://example.com/new", Alias: "hook-expired-new"}); err != nil {
+		t.Fatal(err)
+	}
+	expire("hook-expired-new", time.Now().Add(-time.Millisecond))
+
+	if _, err := dispatcher.SweepExpired(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := dispatcher.SweepExpired(ctx); err != nil {
+		t.Fatal(err)
+	}
+	deliverAll(t, dispatcher)
+
+	var codes []string
+	for _, e := range rc.received() {
+		if e.Type != webhooks.LinkExpired || e.Data.ExpiresAt == nil {
+			t.Errorf("event = %+v", e)
+		}
+		codes = append(codes, e.Data.Code)
+	}
+	if len(codes) != 1 || codes[0] != "hook-expired-new" {
+		t.Errorf("expired events for %v, want [hook-expired-new]", codes)
+	}
+}
+
+func TestWebhookValidation(t *testing.T) {
+	_, _, mux := newWebhookServer(t)
+	rc := newReceiver(t)
+	hook := subscribe(t, mux, rc, webhooks.LinkCreated)
+
+	tests := []struct {
+		name       string
+		method     string
+		target     string
+		body       interface{}
+		wantStatus int
+		wantCode   apierror.Code
+		wantField  string
+	}{
+		{
+			name: "Relative URL", method: http.MethodPost, target: "/webhooks",
+			body:       map[string]interface{}{"url": "/hook", "events": []string{webhooks.LinkCreated}},
+			wantStatus: http.StatusBadRequest, wantCode: apierror.InvalidWebhook, wantField: "url",
+		},
+		{
+			name: "No events", method: http.MethodPost, target: "/webhooks",
+			body:       map[string]interface{}{"url": "https://example.com/hook"},
+			wantStatus: http.StatusBadRequest, wantCode: apierror.InvalidWebhook, wantField: "events",
+		},
+		{
+			name: "Unknown event", method: http.MethodPost, target: "/webhooks",
+			body:       map[string]interface{}{"url": "https://example.com/hook", "events": []string{"link.renamed"}},
+			wantStatus: http.StatusBadRequest, wantCode: apierror.InvalidWebhook, wantField: "events",
+		},
+		{
+			name: "Short secret", method: http.MethodPost, target: "/webhooks",
+			body:       map[string]interface{}{"url": "https://example.com/hook", "events": []string{webhooks.LinkCreated}, "secret": "short"},
+			wantStatus: http.StatusBadRequest, wantCode: apierror.InvalidWebhook, wantField: "secret",
+		},
+		{
+			name: "Unknown webhook", method: http.MethodGet, target: "/webhooks/999999",
+			wantStatus: http.StatusNotFound, wantCode: apierror.WebhookNotFound,
+		},
+		{
+			name: "Invalid delivery status", method: http.MethodGet, target: fmt.Sprintf("/webhooks/%d/deliveries?status=lost", hook.ID),
+			wantStatus: http.StatusBadRequest, wantCode: apierror.InvalidWebhook, wantField: "status",
+		},
+		{
+			name: "Unknown delivery", method: http.MethodPost, target: fmt.Sprintf("/webhooks/%d/deliveries/999999/retry", hook.ID),
+			wantStatus: http.StatusNotFound, wantCode: apierror.DeliveryNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rr := serveAPI(t, mux, tt.method, tt.target, tt.body)
+			if rr.Code != tt.wantStatus {
+				t.Fatalf("status = %v, want %v: %s", rr.Code, tt.wantStatus, rr.Body.String())
+			}
+			var problem apierror.Problem
+			if err := json.NewDecoder(rr.Body).Decode(&problem); err != nil {
+				t.Fatal(err)
+			}
+			if problem.Code != tt.wantCode || problem.Field != tt.wantField {
+				t.Errorf("problem = %+v", problem)
+			}
+		})
+	}
+
+	t.Run("Generated secret", func(t *testing.T) {
+		rr := serveAPI(t, mux, http.MethodPost, "/webhooks", map[string]interface{}{
+			"url": "https://example.com/generated", "events": []string{webhooks.LinkClicked, webhooks.LinkClicked},
+		})
+		if rr.Code != http.StatusCreated {
+			t.Fatalf("create returned %v: %s", rr.Code, rr.Body.String())
+		}
+		var created models.Webhook
+		json.NewDecoder(rr.Body).Decode(&created)
+		t.Cleanup(func() { db.DeleteWebhook(context.Background(), testServer.DB, created.ID) })
+		if len(created.Secret) < 16 || len(created.Events) != 1 {
+			t.Errorf("created = %+v", created)
+		}
+
+		rr = serveAPI(t, mux, http.MethodGet, fmt.Sprintf("/webhooks/%d", created.ID), nil)
+		var fetched models.Webhook
+		json.NewDecoder(rr.Body).Decode(&fetched)
+		if rr.Code != http.StatusOK || fetched.Secret != "" || fetched.URL != created.URL {
+			t.Errorf("get returned %v: %+v", rr.Code, fetched)
+		}
+
+		rr = serveAPI(t, mux, http.MethodDelete, fmt.Sprintf("/webhooks/%d", created.ID), nil)
+		if rr.Code != http.StatusNoContent {
+			t.Errorf("delete returned %v", rr.Code)
+		}
+	})
+}
+
+func TestWebhookBackoff(t *testing.T) {
+	dispatcher := webhooks.NewDispatcher(nil)
+	dispatcher.BaseDelay = time.Minute
+	dispatcher.MaxDelay = 10 * time.Minute
+
+	tests := []struct {
+		attempt int
+		want    time.Duration
+	}{
+		{1, time.Minute},
+		{2, 2 * time.Minute},
+		{3, 4 * time.Minute},
+		{4, 8 * time.Minute},
+		{5, 10 * time.Minute},
+		{50, 10 * time.Minute},
+	}
+	for _, tt := range tests {
+		if got := dispatcher.Backoff(tt.attempt); got != tt.want {
+			t.Errorf("Backoff(%d) = %v, want %v", tt.attempt, got, tt.want)
+		}
+	}
+}
+
+func TestWebhookSignature(t *testing.T) {
+	body := []byte(`{"type":"link.created"}`)
+	signature := webhooks.Sign("secret", 1700000000, body)
+
+	tests := []struct {
+		name      string
+		secret    string
+		timestamp int64
+		body      []byte
+		want      bool
+	}{
+		{"Valid", "secret", 1700000000, body, true},
+		{"Wrong secret", "other", 1700000000, body, false},
+		{"Replayed timestamp", "secret", 1700000001, body, false},
+		{"Tampered body", "secret", 1700000000, []byte(`{"type":"link.deleted"}`), false},
+	}
+	for _, tt := range tests {
+		if got := webhooks.Verify(tt.secret, tt.timestamp, tt.body, signature); got != tt.want {
+			t.Errorf("%s: Verify = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestWebhookSubscriberCache(t *testing.T) {
+	server, dispatcher, mux := newWebhookServer(t)
+	rc := newReceiver(t)
+	ctx := context.Background()
+
+	// Событие без подписчиков кэширует пустой список подписок.
+	if _, _, err := server.CreateLink(ctx, models.ShortenRequest{URL: "https://example.com/cache", Alias: "hook-cache-before"}); err != nil {
+		t.Fatal(err)
+	}
+	hook := subscribe(t, mux, rc, webhooks.LinkCreated)
+	if _, _, err := server.CreateLink(ctx, models.ShortenRequest{URL: "https://example.com/cache", Alias: "hook-cache-after"}); err != nil {
+		t.Fatal(err)
+	}
+	deliverAll(t, dispatcher)
+	if events := rc.received(); len(events) != 1 || events[0].Data.Code != "hook-cache-after" {
+		t.Fatalf("after subscribe received %+v", events)
+	}
+
+	if rr := serveAPI(t, mux, http.MethodDelete, fmt.Sprintf("/webhooks/%d", hook.ID), nil); rr.Code != http.StatusNoContent {
+		t.Fatalf("delete returned %v", rr.Code)
+	}
+	if _, _, err := server.CreateLink(ctx, models.ShortenRequest{URL: "https://example.com/cache", Alias: "hook-cache-deleted"}); err != nil {
+		t.Fatal(err)
+	}
+	var queued int
+	testServer.DB.QueryRow("SELECT COUNT(*) FROM webhook_deliveries WHERE webhook_id = ?", hook.ID).Scan(&queued)
+	if queued != 0 {
+		t.Errorf("%d deliveries queued for a deleted webhook", queued)
+	}
+}
+
+func TestWebhookDeliveryFairness(t *testing.T) {
+	server, dispatcher, mux := newWebhookServer(t)
+	dispatcher.PerWebhook = 2
+	dispatcher.Workers = 2
+	ctx := context.Background()
+
+	// Медленный получатель держит запрос, пока его не отпустят.
+	started, release := make(chan struct{}, 10), make(chan struct{})
+	var releaseOnce sync.Once
+	slow := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		started <- struct{}{}
+		<-release
+	}))
+	t.Cleanup(slow.Close)
+	t.Cleanup(func() { releaseOnce.Do(func() { close(release) }) })
+	rr := serveAPI(t, mux, http.MethodPost, "/webhooks", map[string]interface{}{
+		"url": slow.URL, "events": []string{webhooks.LinkCreated}, "secret": receiverSecret,
+	})
+	var slowHook models.Webhook
+	json.NewDecoder(rr.Body).Decode(&slowHook)
+	t.Cleanup(func() { db.DeleteWebhook(context.Background(), testServer.DB, slowHook.ID) })
+
+	rc := newReceiver(t)
+	hook := subscribe(t, mux, rc, webhooks.LinkCreated)
+
+	for i := range 3 {
+		if _, _, err := server.CreateLink(ctx, models.ShortenRequest{URL: "https://example.com/fair", Alias: fmt.Sprintf("hook-fair-%d", i)}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	done := make(chan int)
+	go func() {
+		sent, err := dispatcher.DeliverDue(ctx)
+		if err != nil {
+			t.Error(err)
+		}
+		done <- sent
+	}()
+	<-started
+	// Пока медленный получатель занят, второй получает свои доставки.
+	waitFor(t, func() bool { return len(rc.received()) == 2 })
+	releaseOnce.Do(func() { close(release) })
+
+	if sent := <-done; sent != 4 {
+		t.Errorf("sent %d, want 2 per webhook", sent)
+	}
+	if pending := listDeliveries(t, mux, hook, models.DeliveryPending); len(pending) != 1 {
+		t.Errorf("pending after first pass = %d, want 1", len(pending))
+	}
+	deliverAll(t, dispatcher)
+	if events := rc.received(); len(events) != 3 {
+		t.Errorf("received %d events, want 3", len(events))
+	}
+}
+
+func TestWebhookPrune(t *testing.T) {
+	server, dispatcher, mux := newWebhookServer(t)
+	rc := newReceiver(t)
+	hook := subscribe(t, mux, rc, webhooks.LinkCreated)
+	ctx := context.Background()
+
+	create := func(alias string) {
+		t.Helper()
+		if _, _, err := server.CreateLink(ctx, models.ShortenRequest{URL: "https://example.com/prune", Alias: alias}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	create("hook-prune-delivered")
+	create("hook-prune-recent")
+	deliverAll(t, dispatcher)
+	rc.respond(http.StatusServiceUnavailable)
+	create("hook-prune-dead")
+	deliverAll(t, dispatcher)
+	deliverAll(t, dispatcher)
+	create("hook-prune-pending")
+
+	// Старые доставки - все, кроме недавно доставленной.
+	_, err := testServer.DB.Exec(`UPDATE webhook_deliveries SET updated_at = '2000-01-01 00:00:00'
+		WHERE webhook_id = ? AND payload NOT LIKE '%hook-prune-recent%'`, hook.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dispatcher.Retention = time.Hour
+	pruned, err := dispatcher.Prune(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pruned != 2 {
+		t.Errorf("pruned %d, want delivered and dead", pruned)
+	}
+
+	remaining := map[string]int{}
+	for _, d := range listDeliveries(t, mux, hook, "") {
+		remaining[d.Status]++
+	}
+	if remaining[models.DeliveryDelivered] != 1 || remaining[models.DeliveryPending] != 1 || remaining[models.DeliveryDead] != 0 {
+		t.Errorf("remaining deliveries = %v", remaining)
+	}
+	var orphans int
+	testServer.DB.QueryRow("SELECT COUNT(*) FROM webhook_attempts WHERE delivery_id NOT IN (SELECT id FROM webhook_deliveries)").Scan(&orphans)
+	if orphans != 0 {
+		t.Errorf("%d attempts left without deliveries", orphans)
+	}
+}
+
+func TestWebhookPrivateDestinations(t *testing.T) {
+	server, dispatcher, mux := newWebhookServer(t)
+	dispatcher.AllowPrivate = false
+	rc := newReceiver(t)
+	hook := subscribe(t, mux, rc, webhooks.LinkCreated)
+
+	if _, _, err := server.CreateLink(context.Background(), models.ShortenRequest{URL: "https://example.com/ssrf", Alias: "hook-ssrf"}); err != nil {
+		t.Fatal(err)
+	}
+	deliverAll(t, dispatcher)
+	pending := listDeliveries(t, mux, hook, models.DeliveryPending)
+	if len(pending) != 1 || !strings.Contains(pending[0].LastError, webhooks.ErrForbiddenDestination.Error()) {
+		t.Fatalf("delivery to loopback = %+v", pending)
+	}
+	if events := rc.received(); len(events) != 0 {
+		t.Errorf("loopback receiver got %+v", events)
+	}
+
+	// Явное разрешение пропускает внутренних получателей.
+	dispatcher.AllowPrivate = true
+	rr := serveAPI(t, mux, http.MethodPost, fmt.Sprintf("/webhooks/%d/deliveries/%d/retry", hook.ID, pending[0].ID), nil)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("retry returned %v", rr.Code)
+	}
+	deliverAll(t, dispatcher)
+	if events := rc.received(); len(events) != 1 {
+		t.Errorf("received %d events after opt-in, want 1", len(events))
+	}
+}
+
+func TestWebhookDestinationCheck(t *testing.T) {
+	dispatcher := webhooks.NewDispatcher(nil)
+
+	tests := []struct {
+		addr    string
+		allowed bool
+	}{
+		{"127.0.0.1:80", false},
+		{"[::1]:443", false},
+		{"10.1.2.3:80", false},
+		{"172.16.0.1:80", false},
+		{"192.168.1.1:80", false},
+		{"169.254.169.254:80", false},
+		{"[fe80::1]:80", false},
+		{"[fd00::1]:80", false},
+		{"[::ffff:127.0.0.1]:80", false},
+		{"100.64.0.1:80", false},
+		{"0.0.0.0:80", false},
+		// Адреса для документации не частные, но и никуда не ведут.
+		{"192.0.2.1:80", true},
+		{"[2001:db8::1]:443", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.addr, func(t *testing.T) {
+			ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+			defer cancel()
+			req, _ := http.NewRequestWithContext(ctx, http.MethodPost, "http://"+tt.addr+"/hook", nil)
+			_, err := dispatcher.Client.Do(req)
+			if forbidden := errors.Is(err, webhooks.ErrForbiddenDestination); forbidden == tt.allowed {
+				t.Errorf("forbidden = %v, err = %v", forbidden, err)
+			}
+		})
+	}
+}